@@ -9,8 +9,21 @@ import (
 type Config struct {
 	Host string `json:"host" envconfig:"host"`
 	Port string `json:"port" envconfig:"port"`
+	// TrustedProxies are the addresses or CIDR ranges of reverse proxies whose
+	// X-Forwarded-For header is believed for the client address. Without any,
+	// the address of the connection is used.
+	TrustedProxies []string `json:"trusted_proxies" envconfig:"trusted_proxies"`
 	PostgresConfig
 	MindsporeModelURL string `json:"mindspore_model_url" envconfig:"mindspore_model_url"`
+	// MindsporeModelVersion is recorded with predictions when the model server does not report its own version.
+	MindsporeModelVersion string `json:"mindspore_model_version" envconfig:"mindspore_model_version"`
+	JWTConfig
+	LocationConfig
+	SOSConfig
+	SMTPConfig
+	WebhookConfig
+	AccountConfig
+	PasswordHashConfig
 }
 
 type PostgresConfig struct {
@@ -20,6 +33,91 @@ type PostgresConfig struct {
 	DbUsername string `json:"db_username" envconfig:"db_username"`
 	DbPassword string `json:"db_password" envconfig:"db_password"`
 	DbSslmode  string `json:"db_sslmode" envconfig:"db_sslmode"`
+	// UsePostGIS searches locations through the PostGIS geography index added by
+	// migration 0011, which is only created when the extension is installed.
+	UsePostGIS bool `json:"use_postgis" envconfig:"use_postgis"`
+}
+
+// JWTConfig holds the token signing keys indexed by key id (kid). New tokens are
+// signed with JWTActiveKeyID, older keys are kept only to verify tokens issued
+// before a rotation.
+type JWTConfig struct {
+	JWTIssuer             string            `json:"jwt_issuer" envconfig:"jwt_issuer"`
+	JWTActiveKeyID        string            `json:"jwt_active_key_id" envconfig:"jwt_active_key_id"`
+	JWTSigningKeys        map[string]string `json:"jwt_signing_keys" envconfig:"jwt_signing_keys"`
+	AccessTokenTTLMinutes int               `json:"access_token_ttl_minutes" envconfig:"access_token_ttl_minutes"`
+	RefreshTokenTTLHours  int               `json:"refresh_token_ttl_hours" envconfig:"refresh_token_ttl_hours"`
+}
+
+// LocationConfig limits what nearby user searches reveal. Users who are not
+// friends see positions snapped to a grid of LocationGridMeters, and locations
+// older than LocationMaxAgeMinutes are not shown at all.
+type LocationConfig struct {
+	LocationGridMeters    int `json:"location_grid_meters" envconfig:"location_grid_meters"`
+	LocationMaxAgeMinutes int `json:"location_max_age_minutes" envconfig:"location_max_age_minutes"`
+}
+
+// SOSConfig limits who is alerted about an SOS: opted-in users whose latest
+// location is within SOSRadiusMeters and not older than SOSMaxAgeMinutes, at
+// most SOSMaxResponders of them, nearest first.
+type SOSConfig struct {
+	SOSRadiusMeters  int `json:"sos_radius_meters" envconfig:"sos_radius_meters"`
+	SOSMaxAgeMinutes int `json:"sos_max_age_minutes" envconfig:"sos_max_age_minutes"`
+	SOSMaxResponders int `json:"sos_max_responders" envconfig:"sos_max_responders"`
+}
+
+// SMTPConfig is the server emails are sent through. While SMTPHost is empty,
+// account emails are written as files to MailDir, or to the log when that is
+// empty too, and email notifications are disabled.
+type SMTPConfig struct {
+	SMTPHost     string `json:"smtp_host" envconfig:"smtp_host"`
+	SMTPPort     string `json:"smtp_port" envconfig:"smtp_port"`
+	SMTPUsername string `json:"smtp_username" envconfig:"smtp_username"`
+	SMTPPassword string `json:"smtp_password" envconfig:"smtp_password"`
+	SMTPFrom     string `json:"smtp_from" envconfig:"smtp_from"`
+	MailDir      string `json:"mail_dir" envconfig:"mail_dir"`
+}
+
+// WebhookConfig configures webhook notifications. With WebhookSecret set,
+// requests carry an HMAC-SHA256 signature of the body so receivers can verify
+// them.
+type WebhookConfig struct {
+	WebhookSecret         string `json:"webhook_secret" envconfig:"webhook_secret"`
+	WebhookTimeoutSeconds int    `json:"webhook_timeout_seconds" envconfig:"webhook_timeout_seconds"`
+}
+
+// AccountConfig configures account emails, passwords, logins and deletion.
+// Account emails link to AppURL when it is set. Passwords listed in
+// BreachedPasswordsFile are rejected. Deleted accounts can be restored by
+// logging in for AccountDeletionGraceDays before they are purged.
+//
+// Failed logins slow down further attempts on the account and from the client
+// address, and lock them for LoginLockoutMinutes after
+// LoginAccountLockoutThreshold or LoginIPLockoutThreshold failures in a row.
+// LoginFailureStore is "postgres" (the default) or "memory" for a single
+// instance deployment. TOTP secrets of two-factor authentication are stored
+// encrypted with MFASecretKey.
+type AccountConfig struct {
+	AppURL                       string `json:"app_url" envconfig:"app_url"`
+	BreachedPasswordsFile        string `json:"breached_passwords_file" envconfig:"breached_passwords_file"`
+	AccountDeletionGraceDays     int    `json:"account_deletion_grace_days" envconfig:"account_deletion_grace_days"`
+	LoginFailureStore            string `json:"login_failure_store" envconfig:"login_failure_store"`
+	LoginAccountLockoutThreshold int    `json:"login_account_lockout_threshold" envconfig:"login_account_lockout_threshold"`
+	LoginIPLockoutThreshold      int    `json:"login_ip_lockout_threshold" envconfig:"login_ip_lockout_threshold"`
+	LoginLockoutMinutes          int    `json:"login_lockout_minutes" envconfig:"login_lockout_minutes"`
+	MFASecretKey                 string `json:"mfa_secret_key" envconfig:"mfa_secret_key"`
+}
+
+// PasswordHashConfig selects how new passwords are hashed: "argon2id" (the
+// default) with the given memory in KiB, iterations and parallelism, or
+// "bcrypt" with BcryptCost. Zero values use the defaults. Stored hashes made
+// with other settings are upgraded when their owner logs in.
+type PasswordHashConfig struct {
+	PasswordHashAlgorithm string `json:"password_hash_algorithm" envconfig:"password_hash_algorithm"`
+	Argon2MemoryKiB       int    `json:"argon2_memory_kib" envconfig:"argon2_memory_kib"`
+	Argon2Iterations      int    `json:"argon2_iterations" envconfig:"argon2_iterations"`
+	Argon2Parallelism     int    `json:"argon2_parallelism" envconfig:"argon2_parallelism"`
+	BcryptCost            int    `json:"bcrypt_cost" envconfig:"bcrypt_cost"`
 }
 
 func getConfigsFromJSON() (*Config, error) {
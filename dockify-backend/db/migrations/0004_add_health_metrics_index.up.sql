@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_health_metrics_user_type_recorded_at ON health_metrics (user_id, metric_type, recorded_at);
@@ -0,0 +1,5 @@
+ALTER TABLE health_metrics ADD COLUMN IF NOT EXISTS source VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE health_metrics ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+
+-- external_id is nullable, so metrics sent without one are never treated as duplicates
+CREATE UNIQUE INDEX IF NOT EXISTS uq_health_metrics_user_source_external_id ON health_metrics (user_id, source, external_id);
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS blood_pressure_readings (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    systolic INT NOT NULL,
+    diastolic INT NOT NULL,
+    pulse INT,
+    source VARCHAR(255) NOT NULL DEFAULT '',
+    recorded_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_blood_pressure_readings_user_recorded_at ON blood_pressure_readings (user_id, recorded_at);
+
+CREATE TABLE IF NOT EXISTS workouts (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    workout_type VARCHAR(50) NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP NOT NULL,
+    calories_burned DOUBLE PRECISION,
+    avg_bpm INT,
+    max_bpm INT,
+    source VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (ended_at > started_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workouts_user_started_at ON workouts (user_id, started_at);
+
+CREATE TABLE IF NOT EXISTS sleep_sessions (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP NOT NULL,
+    stages JSONB NOT NULL DEFAULT '[]',
+    avg_heart_rate INT,
+    movements_per_hour DOUBLE PRECISION,
+    snore_minutes INT,
+    notes TEXT[] NOT NULL DEFAULT '{}',
+    source VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (ended_at > started_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sleep_sessions_user_started_at ON sleep_sessions (user_id, started_at);
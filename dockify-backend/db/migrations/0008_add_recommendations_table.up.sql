@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS recommendations (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    rule VARCHAR(50) NOT NULL,
+    category VARCHAR(30) NOT NULL,
+    priority INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    source_metrics JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP,
+    dismissed_at TIMESTAMP,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- at most one open recommendation per rule; dismissed and resolved ones are kept as history
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recommendations_user_rule_open ON recommendations (user_id, rule)
+    WHERE dismissed_at IS NULL AND resolved_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_recommendations_user_priority ON recommendations (user_id, priority DESC, id DESC);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS hospitals (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    address VARCHAR(500) NOT NULL DEFAULT '',
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    specialties TEXT[] NOT NULL DEFAULT '{}',
+    emergency BOOLEAN NOT NULL DEFAULT FALSE,
+    opening_hours VARCHAR(255) NOT NULL DEFAULT '', -- OpenStreetMap opening_hours syntax
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_hospitals_specialties ON hospitals USING GIN (specialties);
@@ -0,0 +1,7 @@
+ALTER TABLE hospitals ADD COLUMN IF NOT EXISTS amenity VARCHAR(20) NOT NULL DEFAULT 'hospital';
+ALTER TABLE hospitals ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE hospitals ADD COLUMN IF NOT EXISTS external_id VARCHAR(100);
+
+-- lets repeated imports find the rows they created, e.g. ('osm', 'node/123')
+CREATE UNIQUE INDEX IF NOT EXISTS idx_hospitals_source_external_id ON hospitals (source, external_id);
+CREATE INDEX IF NOT EXISTS idx_hospitals_lower_name ON hospitals (LOWER(name));
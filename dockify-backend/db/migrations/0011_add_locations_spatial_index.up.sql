@@ -0,0 +1,17 @@
+-- used by the bounding box pre-filter of nearest user searches
+CREATE INDEX IF NOT EXISTS idx_locations_latitude_longitude ON locations (latitude, longitude);
+
+-- PostGIS mode (use_postgis in the config) searches a geography column with a
+-- GiST index instead. Both are only created where the extension is available,
+-- so this migration also runs on plain PostgreSQL.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'postgis') THEN
+        CREATE EXTENSION IF NOT EXISTS postgis;
+
+        EXECUTE 'ALTER TABLE locations ADD COLUMN IF NOT EXISTS geog geography(Point, 4326)
+            GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography) STORED';
+        EXECUTE 'CREATE INDEX IF NOT EXISTS idx_locations_geog ON locations USING GIST (geog)';
+    END IF;
+END
+$$;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_locations_user_recorded_at ON locations (user_id, recorded_at);
@@ -0,0 +1,37 @@
+-- users opt in to be alerted about emergencies near them
+ALTER TABLE users ADD COLUMN IF NOT EXISTS sos_responder BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS sos_incidents (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    status VARCHAR(10) NOT NULL DEFAULT 'open', -- open or resolved
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sos_incidents_user ON sos_incidents (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS sos_responders (
+    incident_id INT NOT NULL REFERENCES sos_incidents(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    distance DOUBLE PRECISION NOT NULL, -- metres from the incident when it was raised
+    notified_at TIMESTAMP,
+    acknowledged_at TIMESTAMP,
+    PRIMARY KEY (incident_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sos_responders_user ON sos_responders (user_id);
+
+CREATE TABLE IF NOT EXISTS sos_events (
+    id SERIAL PRIMARY KEY,
+    incident_id INT NOT NULL REFERENCES sos_incidents(id) ON DELETE CASCADE,
+    user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    event VARCHAR(20) NOT NULL, -- created, notified, notify_failed, acknowledged or resolved
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sos_events_incident ON sos_events (incident_id, id);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    metric_type VARCHAR(50) NOT NULL,
+    comparator VARCHAR(3) NOT NULL, -- gt, gte, lt or lte
+    threshold DOUBLE PRECISION NOT NULL, -- in the canonical unit of the metric
+    duration_minutes INT NOT NULL DEFAULT 0, -- how long the condition must hold
+    cooldown_minutes INT NOT NULL DEFAULT 60, -- minimum time between two alerts
+    channels JSONB NOT NULL DEFAULT '[]', -- [{"type": "email", "target": "..."}]
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_fired_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_user ON alert_rules (user_id, metric_type);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id SERIAL PRIMARY KEY,
+    rule_id INT REFERENCES alert_rules(id) ON DELETE SET NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rule_name VARCHAR(100) NOT NULL DEFAULT '',
+    metric_type VARCHAR(50) NOT NULL,
+    comparator VARCHAR(3) NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    value DOUBLE PRECISION NOT NULL, -- the reading that fired the rule
+    recorded_at TIMESTAMP NOT NULL,
+    deliveries JSONB NOT NULL DEFAULT '[]', -- outcome per channel
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_user ON alerts (user_id, created_at DESC, id DESC);
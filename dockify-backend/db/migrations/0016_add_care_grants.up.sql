@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS care_invitations (
+    id SERIAL PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL, -- invitee, who may not have an account yet
+    metric_types TEXT[] NOT NULL DEFAULT '{}',
+    location BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending', -- pending, accepted, declined or cancelled
+    grantee_id INT REFERENCES users(id) ON DELETE SET NULL, -- set on acceptance
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_care_invitations_owner ON care_invitations (owner_id);
+CREATE INDEX IF NOT EXISTS idx_care_invitations_email ON care_invitations (LOWER(email));
+
+-- read access of grantee_id to the data of owner_id
+CREATE TABLE IF NOT EXISTS grants (
+    id SERIAL PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    grantee_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    metric_types TEXT[] NOT NULL DEFAULT '{}',
+    location BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP,
+    CHECK (owner_id <> grantee_id)
+);
+
+-- one active grant per pair and direction
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grants_active_pair ON grants (owner_id, grantee_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_grants_grantee ON grants (grantee_id) WHERE revoked_at IS NULL;
+
+-- every read made through a grant
+CREATE TABLE IF NOT EXISTS grant_access_log (
+    id SERIAL PRIMARY KEY,
+    grant_id INT NOT NULL REFERENCES grants(id) ON DELETE CASCADE,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    grantee_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resource VARCHAR(50) NOT NULL, -- metrics, metrics_aggregate or location_history
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_grant_access_log_owner ON grant_access_log (owner_id, created_at DESC, id DESC);
@@ -0,0 +1,9 @@
+-- failed logins per account ("email:<address>") and per client ("ip:<address>")
+CREATE TABLE IF NOT EXISTS login_failures (
+    key VARCHAR(120) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0, -- consecutive failures, reset after a quiet period or a successful login
+    last_failed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_failures_last_failed_at ON login_failures (last_failed_at);
@@ -0,0 +1,9 @@
+-- emails are compared without regard to case. Addresses stored with
+-- capitals are lowered where that does not clash with another account; any
+-- remaining clash makes the index creation fail and has to be resolved by
+-- hand.
+UPDATE users SET email = LOWER(email)
+WHERE email <> LOWER(email)
+  AND NOT EXISTS (SELECT 1 FROM users other WHERE other.id <> users.id AND LOWER(other.email) = LOWER(users.email));
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (LOWER(email));
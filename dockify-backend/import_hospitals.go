@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/hospitalimport"
+	"github.com/askaroe/dockify-backend/internal/services/hospital"
+)
+
+const importHospitalsUsage = "usage: dockify-backend import-hospitals [-format pbf|osm|geojson|csv] [-timezone Asia/Almaty] <file>"
+
+// importHospitals implements the import-hospitals command, which loads
+// hospitals, clinics, doctors and pharmacies from an OpenStreetMap extract, a
+// GeoJSON FeatureCollection or a CSV file into the hospital directory.
+func importHospitals(ctx context.Context, service hospital.Hospital, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("import-hospitals", flag.ContinueOnError)
+	flags.SetOutput(out)
+	format := flags.String("format", "", "input format: pbf, osm, geojson or csv (default: from the file extension)")
+	timezone := flags.String("timezone", "UTC", "IANA timezone of entries that do not name one, used for their opening hours")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(importHospitalsUsage)
+	}
+	if _, err := time.LoadLocation(*timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", *timezone, err)
+	}
+
+	result, err := hospitalimport.ReadFile(ctx, flags.Arg(0), hospitalimport.Options{Format: *format, Timezone: *timezone})
+	if err != nil {
+		return err
+	}
+
+	report, err := service.ImportHospitals(ctx, result.Hospitals)
+	if err != nil {
+		return err
+	}
+
+	// entries the reader dropped were read from the file too
+	for reason, count := range result.Skipped {
+		report.Skipped[reason] += count
+		report.Read += count
+	}
+	skipped := 0
+	for _, count := range report.Skipped {
+		skipped += count
+	}
+
+	fmt.Fprintf(out, "read:      %d\n", report.Read)
+	fmt.Fprintf(out, "inserted:  %d\n", report.Inserted)
+	fmt.Fprintf(out, "updated:   %d\n", report.Updated)
+	fmt.Fprintf(out, "unchanged: %d\n", report.Unchanged)
+	fmt.Fprintf(out, "skipped:   %d\n", skipped)
+	for _, reason := range slices.Sorted(maps.Keys(report.Skipped)) {
+		fmt.Fprintf(out, "  %s: %d\n", reason, report.Skipped[reason])
+	}
+
+	return nil
+}
@@ -1,13 +1,44 @@
 package entity
 
-import "github.com/shopspring/decimal"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/gateway/mindspore"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/shopspring/decimal"
+)
 
 const (
-	RequestParamUserID = "user_id"
+	// ContextKeyUserID is the gin context key holding the authenticated user's id.
+	ContextKeyUserID = "user_id"
+	// ContextKeySessionID is the gin context key holding the session the access token belongs to.
+	ContextKeySessionID = "session_id"
+
+	RequestParamID = "id"
+
+	QueryParamMetricType = "metric_type"
+	QueryParamFrom       = "from"
+	QueryParamTo         = "to"
+	QueryParamOrder      = "order"
+	QueryParamCursor     = "cursor"
+	QueryParamLimit      = "limit"
+	QueryParamBucket     = "bucket"
+	QueryParamFunctions  = "fn"
+	QueryParamTimezone   = "tz"
+	QueryParamKind       = "kind"
+	QueryParamFormat     = "format"
+	QueryParamHeartRate  = "heart_rate"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
 )
 
+// ErrorMessage is the body of every error response. Details lists the invalid
+// fields when a request fails validation.
 type ErrorMessage struct {
-	Message string `json:"message"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
 }
 
 type UserRegisterRequest struct {
@@ -16,6 +47,95 @@ type UserRegisterRequest struct {
 	LastName  string `json:"last_name"`
 	Email     string `json:"email"`
 	Password  string `json:"password"`
+	BirthDate string `json:"birth_date,omitempty" example:"1995-04-21"` // YYYY-MM-DD, used for lifestyle predictions
+}
+
+// UpdateProfileRequest changes only the fields that are present.
+type UpdateProfileRequest struct {
+	UserId    int     `json:"-"`
+	Username  *string `json:"username,omitempty"`
+	FirstName *string `json:"first_name,omitempty"`
+	LastName  *string `json:"last_name,omitempty"`
+	Email     *string `json:"email,omitempty"`                           // a new email has to be verified again
+	BirthDate *string `json:"birth_date,omitempty" example:"1995-04-21"` // YYYY-MM-DD, empty to clear
+
+	CurrentPassword string `json:"current_password,omitempty"` // required to change the email
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"` // logging in before then restores the account
+}
+
+type ForgotPasswordRequest struct {
+	Email     string `json:"email" binding:"required"`
+	IPAddress string `json:"-"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"` // from the password reset email
+	NewPassword string `json:"new_password" binding:"required"`
+	IPAddress   string `json:"-"`
+}
+
+// MFAChallengeResponse is returned by login instead of tokens when the user
+// has two-factor authentication enabled.
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required" example:"true"`
+	MFAToken    string `json:"mfa_token"`                // exchanged for tokens at /login/mfa
+	ExpiresIn   int    `json:"expires_in" example:"300"` // seconds
+}
+
+// MFALoginRequest completes a login with a code from the authenticator app or
+// a recovery code.
+type MFALoginRequest struct {
+	MFAToken   string `json:"mfa_token" binding:"required"`
+	Code       string `json:"code" binding:"required" example:"123456"`
+	DeviceName string `json:"device_name" example:"Pixel 8"`
+	Platform   string `json:"platform" example:"android"`
+	IPAddress  string `json:"-"`
+}
+
+type MFAStatusResponse struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+// MFASetupResponse carries a new TOTP secret. Authenticator apps import it
+// from a QR code of ProvisioningURI.
+type MFASetupResponse struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/Dockify:jane@example.com?algorithm=SHA1&digits=6&issuer=Dockify&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+type MFACodeRequest struct {
+	Code      string `json:"code" binding:"required" example:"123456"`
+	IPAddress string `json:"-"`
+}
+
+type DisableMFARequest struct {
+	Password  string `json:"password" binding:"required"`
+	Code      string `json:"code" binding:"required" example:"123456"` // or a recovery code
+	IPAddress string `json:"-"`
+}
+
+// RecoveryCodesResponse lists single use codes that replace the
+// authenticator app once each. They are shown only this once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3j9d-x2mfq"`
 }
 
 type CreatedUserResponse struct {
@@ -23,19 +143,199 @@ type CreatedUserResponse struct {
 }
 
 type UserLoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	DeviceName string `json:"device_name" example:"Pixel 8"`
+	Platform   string `json:"platform" example:"android"`
+	IPAddress  string `json:"-"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in" example:"900"` // access token lifetime in seconds
+}
+
+type LoginResponse struct {
+	User models.User `json:"user"`
+	TokenResponse
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+	IPAddress    string `json:"-"`
+}
+
+type SessionResponse struct {
+	ID         int        `json:"id"`
+	DeviceName string     `json:"device_name"`
+	Platform   string     `json:"platform"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  *time.Time `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	Current    bool       `json:"current"`
 }
 
 type HealthMetricsRequest struct {
-	UserId   int            `json:"user_id"`
+	UserId   int            `json:"-"`
 	Location Location       `json:"location"`
 	Metrics  []HealthMetric `json:"metrics"`
 }
 
 type HealthMetric struct {
-	MetricType  string `json:"metric_type"`
-	MetricValue string `json:"metric_value"`
+	MetricType  string      `json:"metric_type" example:"heart_rate"`
+	MetricValue json.Number `json:"metric_value" swaggertype:"number" example:"72"`
+	Unit        string      `json:"unit,omitempty" example:"bpm"` // defaults to the catalog unit
+	RecordedAt  *time.Time  `json:"recorded_at,omitempty"`        // defaults to the time of upload
+	Source      string      `json:"source,omitempty" example:"com.google.android.apps.fitness"`
+	ExternalID  string      `json:"external_id,omitempty" example:"3f1c2a9e"` // client id used to deduplicate retries
+}
+
+type MetricTypeInfo struct {
+	Type  string   `json:"type" example:"weight"`
+	Label string   `json:"label" example:"Weight"`
+	Unit  string   `json:"unit" example:"kg"` // canonical unit, values are stored in it
+	Units []string `json:"units"`             // units accepted on ingest
+	Min   float64  `json:"min" example:"1"`
+	Max   float64  `json:"max" example:"500"`
+}
+
+const (
+	MetricStatusInserted  = "inserted"
+	MetricStatusDuplicate = "duplicate"
+	MetricStatusRejected  = "rejected"
+)
+
+type MetricResult struct {
+	Index      int    `json:"index"`
+	MetricType string `json:"metric_type"`
+	Status     string `json:"status" example:"inserted"`
+	ID         int    `json:"id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type MetricsIngestResponse struct {
+	Inserted  int            `json:"inserted"`
+	Duplicate int            `json:"duplicate"`
+	Rejected  int            `json:"rejected"`
+	Results   []MetricResult `json:"results"`
+}
+
+type HealthMetricsQuery struct {
+	UserId      int
+	MetricTypes []string
+	From        *time.Time
+	To          *time.Time
+	Order       string
+	Cursor      string
+	Limit       int
+}
+
+type HealthMetricsPage struct {
+	Metrics    []models.HealthMetrics `json:"metrics"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	HasMore    bool                   `json:"has_more"`
+	Limit      int                    `json:"limit" example:"100"`
+}
+
+type MetricAggregateQuery struct {
+	UserId     int
+	MetricType string
+	Bucket     string
+	Functions  []string
+	From       *time.Time
+	To         *time.Time
+	Timezone   string
+}
+
+type MetricAggregateResponse struct {
+	MetricType string         `json:"metric_type" example:"heart_rate"`
+	Bucket     string         `json:"bucket" example:"1d"`
+	Timezone   string         `json:"timezone" example:"Asia/Almaty"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Functions  []string       `json:"functions" example:"avg,min,max"`
+	Buckets    []MetricBucket `json:"buckets"`
+}
+
+type MetricBucket struct {
+	Start  time.Time           `json:"start"`
+	Values map[string]*float64 `json:"values"`
+}
+
+type TimeRangeQuery struct {
+	UserId int
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+}
+
+type AlertRuleRequest struct {
+	Name            string                `json:"name" example:"High heart rate at rest"`
+	MetricType      string                `json:"metric_type" binding:"required" example:"heart_rate"`
+	Comparator      string                `json:"comparator" binding:"required" example:"gt"` // gt, gte, lt or lte
+	Threshold       float64               `json:"threshold" example:"130"`
+	Unit            string                `json:"unit,omitempty" example:"bpm"`  // unit of threshold, defaults to the metric's unit
+	DurationMinutes int                   `json:"duration_minutes" example:"10"` // how long the condition must hold; 0 fires on a single reading
+	CooldownMinutes *int                  `json:"cooldown_minutes" example:"60"` // minimum time between alerts, default 60
+	Channels        []models.AlertChannel `json:"channels"`                      // where to send alerts besides the app
+	Enabled         *bool                 `json:"enabled" example:"true"`        // default true
+}
+
+type BloodPressureRequest struct {
+	Systolic   int        `json:"systolic" binding:"required" example:"120"`
+	Diastolic  int        `json:"diastolic" binding:"required" example:"80"`
+	Pulse      *int       `json:"pulse,omitempty" example:"68"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"` // defaults to now
+	Source     string     `json:"source,omitempty" example:"com.omron.connect"`
+}
+
+type WorkoutRequest struct {
+	WorkoutType    string    `json:"workout_type" binding:"required" example:"running"`
+	StartedAt      time.Time `json:"started_at" binding:"required"`
+	EndedAt        time.Time `json:"ended_at" binding:"required"`
+	CaloriesBurned *float64  `json:"calories_burned,omitempty" example:"420"`
+	AvgBpm         *int      `json:"avg_bpm,omitempty" example:"142"`
+	MaxBpm         *int      `json:"max_bpm,omitempty" example:"171"`
+	Source         string    `json:"source,omitempty"`
+}
+
+type SleepStage struct {
+	Stage     string    `json:"stage" binding:"required" example:"deep"` // awake, light, deep or rem
+	StartedAt time.Time `json:"started_at" binding:"required"`
+	EndedAt   time.Time `json:"ended_at" binding:"required"`
+}
+
+type SleepSessionRequest struct {
+	StartedAt        time.Time    `json:"started_at" binding:"required"` // went to bed
+	EndedAt          time.Time    `json:"ended_at" binding:"required"`   // got up
+	Stages           []SleepStage `json:"stages,omitempty"`
+	AvgHeartRate     *int         `json:"avg_heart_rate,omitempty" example:"58"`
+	MovementsPerHour *float64     `json:"movements_per_hour,omitempty" example:"12.5"`
+	SnoreMinutes     *int         `json:"snore_minutes,omitempty" example:"15"`
+	Notes            []string     `json:"notes,omitempty" example:"coffee,stress"` // coffee, tea, workout, stress, ate_late
+	Source           string       `json:"source,omitempty"`
+}
+
+type SleepPredictionResponse struct {
+	ID        int                            `json:"id"`
+	Input     mindspore.PredictSleepRequest  `json:"input"`
+	Result    mindspore.PredictSleepResponse `json:"result"`
+	CreatedAt time.Time                      `json:"created_at"`
+}
+
+type LifestylePredictionResponse struct {
+	ID        int                                `json:"id"`
+	Input     mindspore.PredictLifestyleRequest  `json:"input"`
+	Result    mindspore.PredictLifestyleResponse `json:"result"`
+	CreatedAt time.Time                          `json:"created_at"`
+}
+
+type PredictionsQuery struct {
+	UserId int
+	Kind   string
+	Limit  int
 }
 
 type Location struct {
@@ -44,23 +344,183 @@ type Location struct {
 }
 
 type NearestUsersRequest struct {
-	UserId    int     `json:"user_id"`
+	UserId    int     `json:"-"`
 	Longitude float64 `json:"longitude" example:"37.617396"`
 	Latitude  float64 `json:"latitude" example:"55.755825"`
 	Radius    int     `json:"radius" example:"5000"` // in meters
 }
 
 type NearestHospitalsRequest struct {
-	Longitude float64 `json:"longitude" example:"37.617396"`
-	Latitude  float64 `json:"latitude" example:"55.755825"`
-	Radius    int     `json:"radius" example:"5000"` // in meters
+	Longitude     float64 `json:"longitude" example:"37.617396"`
+	Latitude      float64 `json:"latitude" example:"55.755825"`
+	Radius        int     `json:"radius" example:"5000"`                    // in meters
+	Amenity       string  `json:"amenity,omitempty" example:"hospital"`     // hospital, clinic, doctors or pharmacy
+	Specialty     string  `json:"specialty,omitempty" example:"cardiology"` // only hospitals offering it
+	OpenNow       bool    `json:"open_now,omitempty"`
+	EmergencyOnly bool    `json:"emergency_only,omitempty"`
+}
+
+type HospitalResponse struct {
+	ID             int      `json:"id"`
+	Amenity        string   `json:"amenity" example:"hospital"`
+	Name           string   `json:"name" example:"City Clinical Hospital No. 7"`
+	Address        string   `json:"address"`
+	Phone          string   `json:"phone"`
+	Specialties    []string `json:"specialties" example:"cardiology,surgery"`
+	Emergency      bool     `json:"emergency"`
+	OpeningHours   string   `json:"opening_hours" example:"Mo-Fr 08:00-18:00"`
+	OpenNow        *bool    `json:"open_now"` // null when the opening hours are unknown or not understood
+	Latitude       float64  `json:"latitude" example:"43.222015"`
+	Longitude      float64  `json:"longitude" example:"76.851248"`
+	DistanceMeters float64  `json:"distance_meters" example:"1250.5"`
+}
+
+// HospitalImportReport summarizes an import run. Skipped counts records by
+// the reason they were left out.
+type HospitalImportReport struct {
+	Read      int
+	Inserted  int
+	Updated   int
+	Unchanged int
+	Skipped   map[string]int
 }
 
 type NearestUsersResponse struct {
-	UserID   int      `json:"user_id"`
-	Location Location `json:"location"`
+	UserID      int        `json:"user_id"`
+	Location    Location   `json:"location"`
+	Approximate bool       `json:"approximate"` // location snapped to a grid since the user is not a friend
+	RecordedAt  *time.Time `json:"recorded_at"`
+}
+
+type LocationSharingRequest struct {
+	UserId int    `json:"-"`
+	Mode   string `json:"mode" binding:"required" example:"friends"` // off, friends or everyone
+}
+
+type LocationSharingResponse struct {
+	Mode          string `json:"mode" example:"friends"`
+	GridMeters    int    `json:"grid_meters" example:"500"`    // precision non-friends see
+	MaxAgeMinutes int    `json:"max_age_minutes" example:"60"` // older locations are not shown
+}
+
+type FriendResponse struct {
+	UserID     int        `json:"user_id"`
+	Status     string     `json:"status" example:"accepted"` // pending or accepted
+	Incoming   bool       `json:"incoming"`                  // the other user sent the request
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+}
+
+type LocationPoint struct {
+	Latitude   float64   `json:"latitude" example:"43.238949"`
+	Longitude  float64   `json:"longitude" example:"76.889709"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// VisitResponse is a stay: consecutive locations within a small radius of
+// each other for at least a few minutes.
+type VisitResponse struct {
+	Latitude     float64   `json:"latitude" example:"43.238949"` // centroid of the locations
+	Longitude    float64   `json:"longitude" example:"76.889709"`
+	ArrivedAt    time.Time `json:"arrived_at"`
+	DepartedAt   time.Time `json:"departed_at"`
+	DwellMinutes float64   `json:"dwell_minutes" example:"95.5"`
+	Points       int       `json:"points" example:"12"`
+}
+
+// TripResponse is the movement between two visits, or before the first and
+// after the last one.
+type TripResponse struct {
+	StartedAt       time.Time `json:"started_at"`
+	EndedAt         time.Time `json:"ended_at"`
+	DurationMinutes float64   `json:"duration_minutes" example:"24"`
+	DistanceMeters  float64   `json:"distance_meters" example:"5230.7"`
+	AverageSpeedKmh float64   `json:"average_speed_kmh" example:"13.1"`
+	From            Point     `json:"from"`
+	To              Point     `json:"to"`
+	Points          int       `json:"points" example:"8"`
+}
+
+type LocationExportQuery struct {
+	TimeRangeQuery
+	Format    string // geojson or gpx
+	HeartRate bool   // attach the closest heart rate measurement to each point
+}
+
+type Point struct {
+	Latitude  float64 `json:"latitude" example:"43.238949"`
+	Longitude float64 `json:"longitude" example:"76.889709"`
+}
+
+type BlockedUserResponse struct {
+	UserID    int       `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SOSRequest struct {
+	UserId    int     `json:"-"`
+	Latitude  float64 `json:"latitude" example:"43.238949"`
+	Longitude float64 `json:"longitude" example:"76.889709"`
+	Message   string  `json:"message" example:"Fell on the trail, cannot walk"`
+}
+
+type ResolveIncidentRequest struct {
+	Note string `json:"note" example:"Ambulance arrived"`
+}
+
+type IncidentResponse struct {
+	ID         int                         `json:"id"`
+	UserID     int                         `json:"user_id"` // who raised the incident
+	Latitude   float64                     `json:"latitude" example:"43.238949"`
+	Longitude  float64                     `json:"longitude" example:"76.889709"`
+	Message    string                      `json:"message"`
+	Status     string                      `json:"status" example:"open"` // open or resolved
+	CreatedAt  time.Time                   `json:"created_at"`
+	ResolvedAt *time.Time                  `json:"resolved_at"`
+	Responders []IncidentResponderResponse `json:"responders,omitempty"` // only shown to the user who raised the incident
+}
+
+type IncidentResponderResponse struct {
+	UserID         *int       `json:"user_id"`                       // null until the responder acknowledges
+	DistanceMeters float64    `json:"distance_meters" example:"400"` // rounded up to 100 m
+	NotifiedAt     *time.Time `json:"notified_at"`                   // null while the notification was not delivered
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+}
+
+type IncidentEventResponse struct {
+	Event     string    `json:"event" example:"acknowledged"` // created, notified, notify_failed, acknowledged or resolved
+	UserID    *int      `json:"user_id"`                      // the user the event is about; hidden for responders who have not acknowledged
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SOSSettings struct {
+	UserId    int  `json:"-"`
+	Responder bool `json:"responder"` // alert the user about emergencies nearby
+}
+
+type CareInvitationRequest struct {
+	UserId      int      `json:"-"`
+	Email       string   `json:"email" binding:"required" example:"daughter@example.com"` // the caregiver, who may not have an account yet
+	MetricTypes []string `json:"metric_types" example:"heart_rate,blood_glucose"`         // metric types the caregiver may read
+	Location    bool     `json:"location"`                                                // whether the caregiver may read the location history
+}
+
+type GrantScopeRequest struct {
+	MetricTypes []string `json:"metric_types" example:"heart_rate,blood_glucose"`
+	Location    bool     `json:"location"`
+}
+
+type RecommendationQuery struct {
+	UserId int
+	Cursor string
+	Limit  int
 }
 
 type RecommendationResponse struct {
-	Recommendation string `json:"recommendation"`
+	Recommendation  string                  `json:"recommendation"` // message of the top item, kept for older clients
+	Recommendations []models.Recommendation `json:"recommendations"`
+	NextCursor      string                  `json:"next_cursor,omitempty"`
+	HasMore         bool                    `json:"has_more"`
+	Limit           int                     `json:"limit" example:"20"`
 }
@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrTokenReused     = errors.New("refresh token reuse detected")
+	ErrSessionClosed   = errors.New("session is revoked")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrInvalidParam    = errors.New("invalid parameter")
+	ErrForbidden       = errors.New("forbidden")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrConflict        = errors.New("conflict")
+	ErrMFARequired     = errors.New("second factor required")
+
+	ErrInsufficientData = errors.New("not enough data")
+	ErrModelUnavailable = errors.New("prediction model unavailable")
+)
+
+// RetryAfterError rejects a rate limited request; the client may retry after
+// RetryAfter. It matches ErrTooManyRequests.
+type RetryAfterError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return ErrTooManyRequests.Error()
+}
+
+func (e *RetryAfterError) Is(target error) bool {
+	return target == ErrTooManyRequests
+}
+
+// MFAChallengeError ends the password step of a login for a user with
+// two-factor authentication. Token, valid for ExpiresIn, is exchanged for a
+// session together with a code. It matches ErrMFARequired.
+type MFAChallengeError struct {
+	Token     string
+	ExpiresIn time.Duration
+}
+
+func (e *MFAChallengeError) Error() string {
+	return ErrMFARequired.Error()
+}
+
+func (e *MFAChallengeError) Is(target error) bool {
+	return target == ErrMFARequired
+}
+
+// FieldError says what is wrong with one field of a request. Field is the
+// JSON name of the field.
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Message string `json:"message" example:"must be a valid email address"`
+}
+
+// ValidationError lists every invalid field of a request. It matches
+// ErrInvalidParam.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+" "+f.Message)
+	}
+	return ErrInvalidParam.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidParam
+}
+
+// ConflictError reports a field whose value is already taken by another
+// record. It matches ErrConflict.
+type ConflictError struct {
+	Field string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Field + " is already taken"
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
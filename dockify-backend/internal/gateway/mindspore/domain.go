@@ -25,6 +25,7 @@ type PredictSleepResponse struct {
 	SleepQualityScore float64 `json:"sleep_quality_score"`
 	SleepStage        string  `json:"sleep_stage"`
 	Interpretation    string  `json:"interpretation"`
+	ModelVersion      string  `json:"model_version,omitempty"`
 }
 
 type PredictLifestyleRequest struct {
@@ -48,4 +49,5 @@ type PredictLifestyleResponse struct {
 	NextDayCalories   float64 `json:"next_day_calories"`
 	HealthRiskScore   float64 `json:"health_risk_score"`
 	Interpretation    string  `json:"interpretation"`
+	ModelVersion      string  `json:"model_version,omitempty"`
 }
@@ -29,7 +29,7 @@ func (m *mindspore) PredictLifestyle(ctx context.Context, body PredictLifestyleR
 	b := new(bytes.Buffer)
 
 	if err := json.NewEncoder(b).Encode(body); err != nil {
-		return PredictLifestyleResponse{}, fmt.Errorf("could not encode request for jira stats: %w", err)
+		return PredictLifestyleResponse{}, fmt.Errorf("could not encode request for mindspore model: %w", err)
 	}
 
 	req := utils.Request{
@@ -49,6 +49,10 @@ func (m *mindspore) PredictLifestyle(ctx context.Context, body PredictLifestyleR
 		return PredictLifestyleResponse{}, fmt.Errorf("could not unmarshal response from mindspore model: %w", err)
 	}
 
+	if predictResponse.ModelVersion == "" {
+		predictResponse.ModelVersion = m.cfg.MindsporeModelVersion
+	}
+
 	return predictResponse, nil
 }
 
@@ -57,7 +61,7 @@ func (m *mindspore) PredictSleep(ctx context.Context, body PredictSleepRequest)
 	b := new(bytes.Buffer)
 
 	if err := json.NewEncoder(b).Encode(body); err != nil {
-		return PredictSleepResponse{}, fmt.Errorf("could not encode request for jira stats: %w", err)
+		return PredictSleepResponse{}, fmt.Errorf("could not encode request for mindspore model: %w", err)
 	}
 
 	req := utils.Request{
@@ -77,5 +81,9 @@ func (m *mindspore) PredictSleep(ctx context.Context, body PredictSleepRequest)
 		return PredictSleepResponse{}, fmt.Errorf("could not unmarshal response from mindspore model: %w", err)
 	}
 
+	if predictResponse.ModelVersion == "" {
+		predictResponse.ModelVersion = m.cfg.MindsporeModelVersion
+	}
+
 	return predictResponse, nil
 }
@@ -0,0 +1,313 @@
+package care
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/services"
+	"github.com/askaroe/dockify-backend/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Care interface {
+	CreateInvitation(c *gin.Context)
+	GetInvitations(c *gin.Context)
+	AcceptInvitation(c *gin.Context)
+	DeclineInvitation(c *gin.Context)
+	CancelInvitation(c *gin.Context)
+	GetGrants(c *gin.Context)
+	UpdateGrant(c *gin.Context)
+	RevokeGrant(c *gin.Context)
+	GetAccessLog(c *gin.Context)
+}
+
+type care struct {
+	s      *services.Service
+	logger *utils.Logger
+}
+
+func NewCareHandler(s *services.Service, logger *utils.Logger) Care {
+	return &care{s: s, logger: logger}
+}
+
+// CreateInvitation
+// @Summary Invite a caregiver
+// @Description Invites the owner of an email address to read the caller's data: the listed metric types and, with
+// @Description location set, the location history. The invitee is notified by email and, with an account, in the app.
+// @Description Invitations expire after 7 days.
+// @Tags Care
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.CareInvitationRequest true "Invitee and scope of the access"
+// @Success 201 {object} models.CareInvitation
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to create invitation"
+// @Router /api/v1/care/invitations [post]
+func (h *care) CreateInvitation(c *gin.Context) {
+	var request entity.CareInvitationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	request.UserId = c.GetInt(entity.ContextKeyUserID)
+
+	invitation, err := h.s.Care.CreateInvitation(c.Request.Context(), request)
+	if err != nil {
+		h.respondError(c, "CreateInvitation", err, "failed to create invitation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// GetInvitations
+// @Summary List invitations
+// @Description The latest 100 invitations the caller sent or received at their account email, newest first.
+// @Description Received invitations are only listed once the account email is verified.
+// @Description Pending invitations past expires_at are reported as expired.
+// @Tags Care
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.CareInvitation
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to get invitations"
+// @Router /api/v1/care/invitations [get]
+func (h *care) GetInvitations(c *gin.Context) {
+	invitations, err := h.s.Care.GetInvitations(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		h.respondError(c, "GetInvitations", err, "failed to get invitations")
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptInvitation
+// @Summary Accept invitation
+// @Description Grants the caller, the invitee, read access within the scope of the invitation. An existing grant from
+// @Description the same user gets the scope of the invitation. The invitee's account email has to be verified.
+// @Tags Care
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} models.Grant
+// @Failure 400 {object} entity.ErrorMessage "invitation is no longer pending"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "not the invitee or email not verified"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to accept invitation"
+// @Router /api/v1/care/invitations/{id}/accept [post]
+func (h *care) AcceptInvitation(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	grant, err := h.s.Care.AcceptInvitation(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), id)
+	if err != nil {
+		h.respondError(c, "AcceptInvitation", err, "failed to accept invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// DeclineInvitation
+// @Summary Decline invitation
+// @Description Declines an invitation sent to the caller.
+// @Tags Care
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invitation is no longer pending"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "not the invitee or email not verified"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to decline invitation"
+// @Router /api/v1/care/invitations/{id}/decline [post]
+func (h *care) DeclineInvitation(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.s.Care.DeclineInvitation(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), id); err != nil {
+		h.respondError(c, "DeclineInvitation", err, "failed to decline invitation")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CancelInvitation
+// @Summary Cancel invitation
+// @Description Withdraws a pending invitation the caller sent.
+// @Tags Care
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invitation is no longer pending"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "not the sender"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to cancel invitation"
+// @Router /api/v1/care/invitations/{id} [delete]
+func (h *care) CancelInvitation(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.s.Care.CancelInvitation(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), id); err != nil {
+		h.respondError(c, "CancelInvitation", err, "failed to cancel invitation")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetGrants
+// @Summary List grants
+// @Description Active grants the caller gave (owner_id is the caller) and received (grantee_id is the caller).
+// @Tags Care
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Grant
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get grants"
+// @Router /api/v1/care/grants [get]
+func (h *care) GetGrants(c *gin.Context) {
+	grants, err := h.s.Care.GetGrants(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		h.respondError(c, "GetGrants", err, "failed to get grants")
+		return
+	}
+
+	c.JSON(http.StatusOK, grants)
+}
+
+// UpdateGrant
+// @Summary Update grant
+// @Description Replaces the scope of a grant the caller gave. To share nothing, revoke the grant instead.
+// @Tags Care
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Grant ID"
+// @Param request body entity.GrantScopeRequest true "Scope of the access"
+// @Success 200 {object} models.Grant
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "not the owner of the data"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to update grant"
+// @Router /api/v1/care/grants/{id} [put]
+func (h *care) UpdateGrant(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var request entity.GrantScopeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	grant, err := h.s.Care.UpdateGrant(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), id, request)
+	if err != nil {
+		h.respondError(c, "UpdateGrant", err, "failed to update grant")
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// RevokeGrant
+// @Summary Revoke grant
+// @Description Ends a grant the caller gave or received. The other side is notified.
+// @Tags Care
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Grant ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to revoke grant"
+// @Router /api/v1/care/grants/{id} [delete]
+func (h *care) RevokeGrant(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.s.Care.RevokeGrant(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), id); err != nil {
+		h.respondError(c, "RevokeGrant", err, "failed to revoke grant")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetAccessLog
+// @Summary Get access log
+// @Description Reads made through grants, newest first: of the caller's data by caregivers and of others' data by the caller.
+// @Tags Care
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Maximum number of items (max 200)" default(50)
+// @Success 200 {array} models.GrantAccess
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get access log"
+// @Router /api/v1/care/access-log [get]
+func (h *care) GetAccessLog(c *gin.Context) {
+	var limit int
+	if raw := c.Query(entity.QueryParamLimit); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "limit must be a positive integer"})
+			return
+		}
+	}
+
+	accesses, err := h.s.Care.GetAccessLog(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), limit)
+	if err != nil {
+		h.respondError(c, "GetAccessLog", err, "failed to get access log")
+		return
+	}
+
+	c.JSON(http.StatusOK, accesses)
+}
+
+func (h *care) respondError(c *gin.Context, op string, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidParam):
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrForbidden):
+		c.JSON(http.StatusForbidden, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrNotFound):
+		c.JSON(http.StatusNotFound, entity.ErrorMessage{Message: "not found"})
+	default:
+		h.logger.Errorf("%s error: %v", op, err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: message})
+	}
+}
+
+func parseID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param(entity.RequestParamID))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
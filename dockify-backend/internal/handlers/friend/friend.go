@@ -0,0 +1,202 @@
+package friend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/services"
+	"github.com/askaroe/dockify-backend/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Friend interface {
+	GetFriends(c *gin.Context)
+	SendFriendRequest(c *gin.Context)
+	AcceptFriendRequest(c *gin.Context)
+	RemoveFriend(c *gin.Context)
+	GetBlockedUsers(c *gin.Context)
+	BlockUser(c *gin.Context)
+	UnblockUser(c *gin.Context)
+}
+
+type friend struct {
+	s      *services.Service
+	logger *utils.Logger
+}
+
+func NewFriendHandler(s *services.Service, logger *utils.Logger) Friend {
+	return &friend{s: s, logger: logger}
+}
+
+// GetFriends
+// @Summary List friends
+// @Description Friends and pending friend requests of the caller, newest first. incoming marks requests sent to the caller.
+// @Tags Friends
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entity.FriendResponse
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get friends"
+// @Router /api/v1/friends [get]
+func (f *friend) GetFriends(c *gin.Context) {
+	friends, err := f.s.Friend.GetFriends(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		f.logger.Errorf("GetFriends error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to get friends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, friends)
+}
+
+// SendFriendRequest
+// @Summary Send friend request
+// @Description Ask a user to become a friend. A pending request from that user is accepted instead.
+// @Tags Friends
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} entity.FriendResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "user not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to send friend request"
+// @Router /api/v1/friends/{id} [post]
+func (f *friend) SendFriendRequest(c *gin.Context) {
+	otherID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	friendship, err := f.s.Friend.SendFriendRequest(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), otherID)
+	if err != nil {
+		f.respondError(c, "SendFriendRequest", err, "failed to send friend request")
+		return
+	}
+
+	c.JSON(http.StatusOK, friendship)
+}
+
+// AcceptFriendRequest
+// @Summary Accept friend request
+// @Tags Friends
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID of the user who sent the request"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to accept friend request"
+// @Router /api/v1/friends/{id}/accept [post]
+func (f *friend) AcceptFriendRequest(c *gin.Context) {
+	f.update(c, "AcceptFriendRequest", f.s.Friend.AcceptFriendRequest, "failed to accept friend request")
+}
+
+// RemoveFriend
+// @Summary Remove friend
+// @Description End a friendship, or withdraw or decline a pending request.
+// @Tags Friends
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to remove friend"
+// @Router /api/v1/friends/{id} [delete]
+func (f *friend) RemoveFriend(c *gin.Context) {
+	f.update(c, "RemoveFriend", f.s.Friend.RemoveFriend, "failed to remove friend")
+}
+
+// GetBlockedUsers
+// @Summary List blocked users
+// @Tags Friends
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entity.BlockedUserResponse
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get blocked users"
+// @Router /api/v1/blocks [get]
+func (f *friend) GetBlockedUsers(c *gin.Context) {
+	blocked, err := f.s.Friend.GetBlockedUsers(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		f.logger.Errorf("GetBlockedUsers error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to get blocked users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, blocked)
+}
+
+// BlockUser
+// @Summary Block user
+// @Description Hide the caller and the user from each other in nearby searches and end their friendship.
+// @Tags Friends
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "user not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to block user"
+// @Router /api/v1/blocks/{id} [post]
+func (f *friend) BlockUser(c *gin.Context) {
+	f.update(c, "BlockUser", f.s.Friend.BlockUser, "failed to block user")
+}
+
+// UnblockUser
+// @Summary Unblock user
+// @Tags Friends
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to unblock user"
+// @Router /api/v1/blocks/{id} [delete]
+func (f *friend) UnblockUser(c *gin.Context) {
+	f.update(c, "UnblockUser", f.s.Friend.UnblockUser, "failed to unblock user")
+}
+
+func (f *friend) update(c *gin.Context, op string, fn func(ctx context.Context, userID, otherID int) error, message string) {
+	otherID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := fn(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), otherID); err != nil {
+		f.respondError(c, op, err, message)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (f *friend) respondError(c *gin.Context, op string, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidParam):
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrNotFound):
+		c.JSON(http.StatusNotFound, entity.ErrorMessage{Message: "not found"})
+	default:
+		f.logger.Errorf("%s error: %v", op, err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: message})
+	}
+}
+
+func userIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param(entity.RequestParamID))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,203 @@
+package health
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type AlertRules interface {
+	CreateAlertRule(c *gin.Context)
+	GetAlertRules(c *gin.Context)
+	GetAlertRule(c *gin.Context)
+	UpdateAlertRule(c *gin.Context)
+	DeleteAlertRule(c *gin.Context)
+	GetAlerts(c *gin.Context)
+}
+
+// CreateAlertRule
+// @Summary Create alert rule
+// @Description Create a rule that alerts when readings of a metric compare to a threshold for duration_minutes,
+// @Description at most once per cooldown_minutes. Rules are checked whenever metrics are uploaded; alerts go to the
+// @Description user in the app and to the rule's channels (email, webhook or log). At most 50 rules per user.
+// @Description Email channels can only target the account's verified email address, which is used when the target is empty.
+// @Tags Alerts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.AlertRuleRequest true "alert rule payload"
+// @Success 201 {object} models.AlertRule
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to create alert rule"
+// @Router /api/v1/alerts/rules [post]
+func (h *health) CreateAlertRule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	result, err := h.s.Health.CreateAlertRule(ctx, c.GetInt(entity.ContextKeyUserID), req)
+	if err != nil {
+		h.respondError(c, "CreateAlertRule", err, "failed to create alert rule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// GetAlertRules
+// @Summary List alert rules
+// @Description List the authenticated user's alert rules
+// @Tags Alerts
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.AlertRule
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get alert rules"
+// @Router /api/v1/alerts/rules [get]
+func (h *health) GetAlertRules(c *gin.Context) {
+	result, err := h.s.Health.GetAlertRules(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		h.respondError(c, "GetAlertRules", err, "failed to get alert rules")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAlertRule
+// @Summary Get alert rule
+// @Description Get one of the authenticated user's alert rules
+// @Tags Alerts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.AlertRule
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to get alert rule"
+// @Router /api/v1/alerts/rules/{id} [get]
+func (h *health) GetAlertRule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.s.Health.GetAlertRule(ctx, c.GetInt(entity.ContextKeyUserID), id)
+	if err != nil {
+		h.respondError(c, "GetAlertRule", err, "failed to get alert rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateAlertRule
+// @Summary Update alert rule
+// @Description Replace one of the authenticated user's alert rules
+// @Tags Alerts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param request body entity.AlertRuleRequest true "alert rule payload"
+// @Success 200 {object} models.AlertRule
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to update alert rule"
+// @Router /api/v1/alerts/rules/{id} [put]
+func (h *health) UpdateAlertRule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	result, err := h.s.Health.UpdateAlertRule(ctx, c.GetInt(entity.ContextKeyUserID), id, req)
+	if err != nil {
+		h.respondError(c, "UpdateAlertRule", err, "failed to update alert rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteAlertRule
+// @Summary Delete alert rule
+// @Description Delete one of the authenticated user's alert rules. Alerts it fired are kept.
+// @Tags Alerts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to delete alert rule"
+// @Router /api/v1/alerts/rules/{id} [delete]
+func (h *health) DeleteAlertRule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.s.Health.DeleteAlertRule(ctx, c.GetInt(entity.ContextKeyUserID), id); err != nil {
+		h.respondError(c, "DeleteAlertRule", err, "failed to delete alert rule")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetAlerts
+// @Summary List fired alerts
+// @Description List the authenticated user's fired alerts, newest first, with the delivery outcome per channel
+// @Tags Alerts
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Maximum number of items (max 200)" default(50)
+// @Success 200 {array} models.Alert
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get alerts"
+// @Router /api/v1/alerts [get]
+func (h *health) GetAlerts(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var limit int
+	if raw := c.Query(entity.QueryParamLimit); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "limit must be a positive integer"})
+			return
+		}
+	}
+
+	result, err := h.s.Health.GetAlerts(ctx, c.GetInt(entity.ContextKeyUserID), limit)
+	if err != nil {
+		h.respondError(c, "GetAlerts", err, "failed to get alerts")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
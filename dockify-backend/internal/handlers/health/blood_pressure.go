@@ -0,0 +1,176 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type BloodPressure interface {
+	CreateBloodPressure(c *gin.Context)
+	GetBloodPressureReadings(c *gin.Context)
+	GetBloodPressure(c *gin.Context)
+	UpdateBloodPressure(c *gin.Context)
+	DeleteBloodPressure(c *gin.Context)
+}
+
+// CreateBloodPressure
+// @Summary Create blood pressure reading
+// @Description Store a blood pressure reading for the authenticated user
+// @Tags Blood Pressure
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.BloodPressureRequest true "blood pressure reading payload"
+// @Success 201 {object} models.BloodPressureReading
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to create blood pressure reading"
+// @Router /api/v1/metrics/blood-pressure [post]
+func (h *health) CreateBloodPressure(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.BloodPressureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	result, err := h.s.Health.CreateBloodPressure(ctx, c.GetInt(entity.ContextKeyUserID), req)
+	if err != nil {
+		h.respondError(c, "CreateBloodPressure", err, "failed to create blood pressure reading")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// GetBloodPressureReadings
+// @Summary List blood pressure readings
+// @Description List the authenticated user's blood pressure readings in a time range, newest first
+// @Tags Blood Pressure
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Inclusive lower bound (RFC3339)"
+// @Param to query string false "Exclusive upper bound (RFC3339)"
+// @Param limit query int false "Maximum number of items (max 1000)" default(100)
+// @Success 200 {array} models.BloodPressureReading
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get blood pressure readings"
+// @Router /api/v1/metrics/blood-pressure [get]
+func (h *health) GetBloodPressureReadings(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query, err := parseTimeRangeQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+		return
+	}
+
+	result, err := h.s.Health.GetBloodPressureReadings(ctx, query)
+	if err != nil {
+		h.respondError(c, "GetBloodPressureReadings", err, "failed to get blood pressure readings")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetBloodPressure
+// @Summary Get blood pressure reading
+// @Description Get one of the authenticated user's blood pressure readings
+// @Tags Blood Pressure
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.BloodPressureReading
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to get blood pressure reading"
+// @Router /api/v1/metrics/blood-pressure/{id} [get]
+func (h *health) GetBloodPressure(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.s.Health.GetBloodPressure(ctx, c.GetInt(entity.ContextKeyUserID), id)
+	if err != nil {
+		h.respondError(c, "GetBloodPressure", err, "failed to get blood pressure reading")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateBloodPressure
+// @Summary Update blood pressure reading
+// @Description Replace one of the authenticated user's blood pressure readings
+// @Tags Blood Pressure
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param request body entity.BloodPressureRequest true "blood pressure reading payload"
+// @Success 200 {object} models.BloodPressureReading
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to update blood pressure reading"
+// @Router /api/v1/metrics/blood-pressure/{id} [put]
+func (h *health) UpdateBloodPressure(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.BloodPressureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	result, err := h.s.Health.UpdateBloodPressure(ctx, c.GetInt(entity.ContextKeyUserID), id, req)
+	if err != nil {
+		h.respondError(c, "UpdateBloodPressure", err, "failed to update blood pressure reading")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteBloodPressure
+// @Summary Delete blood pressure reading
+// @Description Delete one of the authenticated user's blood pressure readings
+// @Tags Blood Pressure
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to delete blood pressure reading"
+// @Router /api/v1/metrics/blood-pressure/{id} [delete]
+func (h *health) DeleteBloodPressure(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.s.Health.DeleteBloodPressure(ctx, c.GetInt(entity.ContextKeyUserID), id); err != nil {
+		h.respondError(c, "DeleteBloodPressure", err, "failed to delete blood pressure reading")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
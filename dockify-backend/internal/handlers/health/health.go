@@ -1,8 +1,11 @@
 package health
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/askaroe/dockify-backend/internal/entity"
 	"github.com/askaroe/dockify-backend/internal/services"
@@ -13,6 +16,14 @@ import (
 type Health interface {
 	CreateHealthMetrics(c *gin.Context)
 	GetHealthMetrics(c *gin.Context)
+	AggregateHealthMetrics(c *gin.Context)
+	GetMetricTypes(c *gin.Context)
+
+	BloodPressure
+	Workouts
+	SleepSessions
+	AlertRules
+	PatientMetrics
 }
 
 type health struct {
@@ -26,13 +37,16 @@ func NewHealthHandler(s *services.Service, logger *utils.Logger) Health {
 
 // CreateHealthMetrics
 // @Summary Create health metrics
-// @Description Create health metrics for a user
+// @Description Create health metrics for the authenticated user. Metrics carrying a source and external_id are
+// @Description deduplicated, so a batch can be resent safely. Every item is reported as inserted, duplicate or rejected.
 // @Tags Metrics
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param request body entity.HealthMetricsRequest true "Health metrics payload"
-// @Success 201 {object} map[string]string "status message"
+// @Success 201 {object} entity.MetricsIngestResponse "per-item results"
 // @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
 // @Failure 500 {object} entity.ErrorMessage "failed to create health metrics"
 // @Router /api/v1/metrics [post]
 func (h *health) CreateHealthMetrics(c *gin.Context) {
@@ -43,56 +57,177 @@ func (h *health) CreateHealthMetrics(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
 		return
 	}
+	req.UserId = c.GetInt(entity.ContextKeyUserID)
 
-	err := h.s.Health.CreateHealthMetric(ctx, req)
+	result, err := h.s.Health.CreateHealthMetric(ctx, req)
 	if err != nil {
 		h.logger.Errorf("CreateHealthMetrics error: %v", err)
 		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to create health metrics"})
 		return
 	}
 
-	err = h.s.Location.CreateLocation(ctx, req)
-	if err != nil {
-		h.logger.Errorf("CreateLocation error: %v", err)
-		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to create location"})
-		return
-	}
-
-	c.JSON(http.StatusCreated, gin.H{"status": "health metrics created"})
+	c.JSON(http.StatusCreated, result)
 }
 
 // GetHealthMetrics
 // @Summary Get health metrics
-// @Description Retrieve health metrics for a given user by query parameter user_id
+// @Description Retrieve a page of the authenticated user's health metrics, optionally filtered by type and time range
 // @Tags Metrics
+// @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param user_id query int true "User ID"
-// @Success 200 {array} object "list of health metrics"
-// @Failure 400 {object} entity.ErrorMessage "invalid user_id or missing parameter"
+// @Param metric_type query []string false "Metric type, repeatable" collectionFormat(multi)
+// @Param from query string false "Inclusive lower bound (RFC3339)"
+// @Param to query string false "Exclusive upper bound (RFC3339)"
+// @Param order query string false "Sort order by recorded_at" Enums(asc, desc) default(desc)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param limit query int false "Page size (max 1000)" default(100)
+// @Success 200 {object} entity.HealthMetricsPage "page of health metrics"
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
 // @Failure 500 {object} entity.ErrorMessage "failed to get health metrics"
 // @Router /api/v1/metrics [get]
 func (h *health) GetHealthMetrics(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	userIdParam := c.Query(entity.RequestParamUserID)
-	if userIdParam == "" {
-		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+	query, err := parseHealthMetricsQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
 		return
 	}
+	query.UserId = c.GetInt(entity.ContextKeyUserID)
 
-	// Convert userIdParam to int
-	userId, err := strconv.Atoi(userIdParam)
+	metrics, err := h.s.Health.GetMetrics(ctx, query)
+	if errors.Is(err, entity.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid cursor"})
+		return
+	}
 	if err != nil {
 		h.logger.Errorf("GetHealthMetrics error: %v", err)
-		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to get health metrics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
+
+// AggregateHealthMetrics
+// @Summary Aggregate health metrics
+// @Description Bucketed time series of one metric type for the authenticated user. Bucket boundaries follow the given timezone.
+// @Tags Metrics
+// @Security BearerAuth
+// @Produce json
+// @Param metric_type query string true "Metric type" example(heart_rate)
+// @Param bucket query string false "Bucket size" Enums(1h, 1d, 1w, 1M) default(1d)
+// @Param fn query string false "Comma separated aggregates: avg,min,max,sum,count,stddev,p50,p75,p90,p95,p99" default(avg,min,max,count)
+// @Param from query string false "Inclusive lower bound (RFC3339)"
+// @Param to query string false "Exclusive upper bound (RFC3339), defaults to now"
+// @Param tz query string false "IANA timezone" default(UTC) example(Asia/Almaty)
+// @Success 200 {object} entity.MetricAggregateResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to aggregate health metrics"
+// @Router /api/v1/metrics/aggregate [get]
+func (h *health) AggregateHealthMetrics(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query, err := parseAggregateQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+		return
 	}
+	query.UserId = c.GetInt(entity.ContextKeyUserID)
 
-	metrics, err := h.s.Health.GetMetricsByUserId(ctx, userId)
+	response, err := h.s.Health.AggregateMetrics(ctx, query)
+	if errors.Is(err, entity.ErrInvalidParam) {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to get health metrics"})
+		h.logger.Errorf("AggregateHealthMetrics error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to aggregate health metrics"})
 		return
 	}
 
-	c.JSON(http.StatusOK, metrics)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMetricTypes
+// @Summary List metric types
+// @Description Returns the metric catalog with labels, canonical and accepted units and valid ranges
+// @Tags Metrics
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entity.MetricTypeInfo
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Router /api/v1/metrics/types [get]
+func (h *health) GetMetricTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, h.s.Health.GetMetricTypes())
+}
+
+func parseHealthMetricsQuery(c *gin.Context) (entity.HealthMetricsQuery, error) {
+	query := entity.HealthMetricsQuery{
+		MetricTypes: c.QueryArray(entity.QueryParamMetricType),
+		Order:       c.DefaultQuery(entity.QueryParamOrder, entity.OrderDesc),
+		Cursor:      c.Query(entity.QueryParamCursor),
+	}
+
+	if query.Order != entity.OrderAsc && query.Order != entity.OrderDesc {
+		return entity.HealthMetricsQuery{}, errors.New("order must be asc or desc")
+	}
+
+	var err error
+	if query.From, err = parseTimeQuery(c, entity.QueryParamFrom); err != nil {
+		return entity.HealthMetricsQuery{}, err
+	}
+	if query.To, err = parseTimeQuery(c, entity.QueryParamTo); err != nil {
+		return entity.HealthMetricsQuery{}, err
+	}
+
+	if limit := c.Query(entity.QueryParamLimit); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit <= 0 {
+			return entity.HealthMetricsQuery{}, errors.New("limit must be a positive integer")
+		}
+	}
+
+	return query, nil
+}
+
+func parseAggregateQuery(c *gin.Context) (entity.MetricAggregateQuery, error) {
+	query := entity.MetricAggregateQuery{
+		MetricType: c.Query(entity.QueryParamMetricType),
+		Bucket:     c.DefaultQuery(entity.QueryParamBucket, "1d"),
+		Timezone:   c.Query(entity.QueryParamTimezone),
+	}
+
+	if fn := c.Query(entity.QueryParamFunctions); fn != "" {
+		for _, f := range strings.Split(fn, ",") {
+			query.Functions = append(query.Functions, strings.TrimSpace(f))
+		}
+	}
+
+	var err error
+	if query.From, err = parseTimeQuery(c, entity.QueryParamFrom); err != nil {
+		return entity.MetricAggregateQuery{}, err
+	}
+	if query.To, err = parseTimeQuery(c, entity.QueryParamTo); err != nil {
+		return entity.MetricAggregateQuery{}, err
+	}
+
+	return query, nil
+}
+
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.New(key + " must be an RFC3339 timestamp")
+	}
+
+	return &t, nil
 }
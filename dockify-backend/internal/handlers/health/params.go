@@ -0,0 +1,58 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+func parseTimeRangeQuery(c *gin.Context) (entity.TimeRangeQuery, error) {
+	query := entity.TimeRangeQuery{UserId: c.GetInt(entity.ContextKeyUserID)}
+
+	var err error
+	if query.From, err = parseTimeQuery(c, entity.QueryParamFrom); err != nil {
+		return entity.TimeRangeQuery{}, err
+	}
+	if query.To, err = parseTimeQuery(c, entity.QueryParamTo); err != nil {
+		return entity.TimeRangeQuery{}, err
+	}
+
+	if limit := c.Query(entity.QueryParamLimit); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit <= 0 {
+			return entity.TimeRangeQuery{}, errors.New("limit must be a positive integer")
+		}
+	}
+
+	return query, nil
+}
+
+func parseID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param(entity.RequestParamID))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps service errors onto status codes, logging only the
+// unexpected ones.
+func (h *health) respondError(c *gin.Context, op string, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		c.JSON(http.StatusNotFound, entity.ErrorMessage{Message: "not found"})
+	case errors.Is(err, entity.ErrInvalidParam):
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid cursor"})
+	case errors.Is(err, entity.ErrForbidden):
+		c.JSON(http.StatusForbidden, entity.ErrorMessage{Message: err.Error()})
+	default:
+		h.logger.Errorf("%s error: %v", op, err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: message})
+	}
+}
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type PatientMetrics interface {
+	GetPatientMetrics(c *gin.Context)
+	AggregatePatientMetrics(c *gin.Context)
+}
+
+// GetPatientMetrics
+// @Summary Get a care team member's health metrics
+// @Description Paginated health metrics of the user with the given id, who granted the caller access to them.
+// @Description Without metric_type all shared metric types are returned. Every read is recorded in the access log.
+// @Tags Care
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID of the user who granted access"
+// @Param metric_type query []string false "Filter by metric type, repeatable" collectionFormat(multi)
+// @Param from query string false "Inclusive lower bound (RFC3339)"
+// @Param to query string false "Exclusive upper bound (RFC3339)"
+// @Param order query string false "Sort order by recorded_at" Enums(asc, desc) default(desc)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param limit query int false "Page size (max 1000)" default(100)
+// @Success 200 {object} entity.HealthMetricsPage "page of health metrics"
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "metric type is not shared"
+// @Failure 404 {object} entity.ErrorMessage "no active grant"
+// @Failure 500 {object} entity.ErrorMessage "failed to get health metrics"
+// @Router /api/v1/care/patients/{id}/metrics [get]
+func (h *health) GetPatientMetrics(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	patientID, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	query, err := parseHealthMetricsQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+		return
+	}
+	query.UserId = patientID
+
+	metrics, err := h.s.Care.GetPatientMetrics(ctx, c.GetInt(entity.ContextKeyUserID), query)
+	if err != nil {
+		h.respondError(c, "GetPatientMetrics", err, "failed to get health metrics")
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
+
+// AggregatePatientMetrics
+// @Summary Aggregate a care team member's health metrics
+// @Description Bucketed time series of one metric type the user with the given id shared with the caller.
+// @Description Every read is recorded in the access log.
+// @Tags Care
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID of the user who granted access"
+// @Param metric_type query string true "Metric type" example(heart_rate)
+// @Param bucket query string false "Bucket size" Enums(1h, 1d, 1w, 1M) default(1d)
+// @Param fn query string false "Comma separated aggregates: avg,min,max,sum,count,stddev,p50,p75,p90,p95,p99" default(avg,min,max,count)
+// @Param from query string false "Inclusive lower bound (RFC3339)"
+// @Param to query string false "Exclusive upper bound (RFC3339), defaults to now"
+// @Param tz query string false "IANA timezone" default(UTC) example(Asia/Almaty)
+// @Success 200 {object} entity.MetricAggregateResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "metric type is not shared"
+// @Failure 404 {object} entity.ErrorMessage "no active grant"
+// @Failure 500 {object} entity.ErrorMessage "failed to aggregate health metrics"
+// @Router /api/v1/care/patients/{id}/metrics/aggregate [get]
+func (h *health) AggregatePatientMetrics(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	patientID, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	query, err := parseAggregateQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+		return
+	}
+	query.UserId = patientID
+
+	response, err := h.s.Care.AggregatePatientMetrics(ctx, c.GetInt(entity.ContextKeyUserID), query)
+	if err != nil {
+		h.respondError(c, "AggregatePatientMetrics", err, "failed to aggregate health metrics")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
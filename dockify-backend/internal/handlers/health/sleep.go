@@ -0,0 +1,176 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type SleepSessions interface {
+	CreateSleepSession(c *gin.Context)
+	GetSleepSessions(c *gin.Context)
+	GetSleepSession(c *gin.Context)
+	UpdateSleepSession(c *gin.Context)
+	DeleteSleepSession(c *gin.Context)
+}
+
+// CreateSleepSession
+// @Summary Create sleep session
+// @Description Store a sleep session for the authenticated user
+// @Tags Sleep
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.SleepSessionRequest true "sleep session payload"
+// @Success 201 {object} models.SleepSession
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to create sleep session"
+// @Router /api/v1/sleep-sessions [post]
+func (h *health) CreateSleepSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.SleepSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	result, err := h.s.Health.CreateSleepSession(ctx, c.GetInt(entity.ContextKeyUserID), req)
+	if err != nil {
+		h.respondError(c, "CreateSleepSession", err, "failed to create sleep session")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// GetSleepSessions
+// @Summary List sleep sessions
+// @Description List the authenticated user's sleep sessions in a time range, newest first
+// @Tags Sleep
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Inclusive lower bound (RFC3339)"
+// @Param to query string false "Exclusive upper bound (RFC3339)"
+// @Param limit query int false "Maximum number of items (max 1000)" default(100)
+// @Success 200 {array} models.SleepSession
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get sleep sessions"
+// @Router /api/v1/sleep-sessions [get]
+func (h *health) GetSleepSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query, err := parseTimeRangeQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+		return
+	}
+
+	result, err := h.s.Health.GetSleepSessions(ctx, query)
+	if err != nil {
+		h.respondError(c, "GetSleepSessions", err, "failed to get sleep sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetSleepSession
+// @Summary Get sleep session
+// @Description Get one of the authenticated user's sleep sessions
+// @Tags Sleep
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.SleepSession
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to get sleep session"
+// @Router /api/v1/sleep-sessions/{id} [get]
+func (h *health) GetSleepSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.s.Health.GetSleepSession(ctx, c.GetInt(entity.ContextKeyUserID), id)
+	if err != nil {
+		h.respondError(c, "GetSleepSession", err, "failed to get sleep session")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateSleepSession
+// @Summary Update sleep session
+// @Description Replace one of the authenticated user's sleep sessions
+// @Tags Sleep
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param request body entity.SleepSessionRequest true "sleep session payload"
+// @Success 200 {object} models.SleepSession
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to update sleep session"
+// @Router /api/v1/sleep-sessions/{id} [put]
+func (h *health) UpdateSleepSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.SleepSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	result, err := h.s.Health.UpdateSleepSession(ctx, c.GetInt(entity.ContextKeyUserID), id, req)
+	if err != nil {
+		h.respondError(c, "UpdateSleepSession", err, "failed to update sleep session")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteSleepSession
+// @Summary Delete sleep session
+// @Description Delete one of the authenticated user's sleep sessions
+// @Tags Sleep
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to delete sleep session"
+// @Router /api/v1/sleep-sessions/{id} [delete]
+func (h *health) DeleteSleepSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.s.Health.DeleteSleepSession(ctx, c.GetInt(entity.ContextKeyUserID), id); err != nil {
+		h.respondError(c, "DeleteSleepSession", err, "failed to delete sleep session")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
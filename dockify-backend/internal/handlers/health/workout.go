@@ -0,0 +1,176 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+type Workouts interface {
+	CreateWorkout(c *gin.Context)
+	GetWorkouts(c *gin.Context)
+	GetWorkout(c *gin.Context)
+	UpdateWorkout(c *gin.Context)
+	DeleteWorkout(c *gin.Context)
+}
+
+// CreateWorkout
+// @Summary Create workout
+// @Description Store a workout for the authenticated user
+// @Tags Workouts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.WorkoutRequest true "workout payload"
+// @Success 201 {object} models.Workout
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to create workout"
+// @Router /api/v1/workouts [post]
+func (h *health) CreateWorkout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.WorkoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	result, err := h.s.Health.CreateWorkout(ctx, c.GetInt(entity.ContextKeyUserID), req)
+	if err != nil {
+		h.respondError(c, "CreateWorkout", err, "failed to create workout")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// GetWorkouts
+// @Summary List workouts
+// @Description List the authenticated user's workouts in a time range, newest first
+// @Tags Workouts
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Inclusive lower bound (RFC3339)"
+// @Param to query string false "Exclusive upper bound (RFC3339)"
+// @Param limit query int false "Maximum number of items (max 1000)" default(100)
+// @Success 200 {array} models.Workout
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get workouts"
+// @Router /api/v1/workouts [get]
+func (h *health) GetWorkouts(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query, err := parseTimeRangeQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+		return
+	}
+
+	result, err := h.s.Health.GetWorkouts(ctx, query)
+	if err != nil {
+		h.respondError(c, "GetWorkouts", err, "failed to get workouts")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetWorkout
+// @Summary Get workout
+// @Description Get one of the authenticated user's workouts
+// @Tags Workouts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.Workout
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to get workout"
+// @Router /api/v1/workouts/{id} [get]
+func (h *health) GetWorkout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.s.Health.GetWorkout(ctx, c.GetInt(entity.ContextKeyUserID), id)
+	if err != nil {
+		h.respondError(c, "GetWorkout", err, "failed to get workout")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateWorkout
+// @Summary Update workout
+// @Description Replace one of the authenticated user's workouts
+// @Tags Workouts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param request body entity.WorkoutRequest true "workout payload"
+// @Success 200 {object} models.Workout
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to update workout"
+// @Router /api/v1/workouts/{id} [put]
+func (h *health) UpdateWorkout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.WorkoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	result, err := h.s.Health.UpdateWorkout(ctx, c.GetInt(entity.ContextKeyUserID), id, req)
+	if err != nil {
+		h.respondError(c, "UpdateWorkout", err, "failed to update workout")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteWorkout
+// @Summary Delete workout
+// @Description Delete one of the authenticated user's workouts
+// @Tags Workouts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to delete workout"
+// @Router /api/v1/workouts/{id} [delete]
+func (h *health) DeleteWorkout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.s.Health.DeleteWorkout(ctx, c.GetInt(entity.ContextKeyUserID), id); err != nil {
+		h.respondError(c, "DeleteWorkout", err, "failed to delete workout")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
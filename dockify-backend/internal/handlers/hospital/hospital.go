@@ -0,0 +1,61 @@
+package hospital
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/services"
+	"github.com/askaroe/dockify-backend/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Hospital interface {
+	GetNearestHospitals(c *gin.Context)
+}
+
+type hospital struct {
+	s      *services.Service
+	logger *utils.Logger
+}
+
+func NewHospitalHandler(s *services.Service, logger *utils.Logger) Hospital {
+	return &hospital{s: s, logger: logger}
+}
+
+// GetNearestHospitals godoc
+// @Summary Get Nearest Hospitals
+// @Description Returns hospitals within radius metres (default 5000, max 50000) of the provided location, nearest
+// @Description first. Optionally only hospitals offering a specialty, open now or with an emergency department.
+// @Tags Hospitals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.NearestHospitalsRequest true "Nearest hospitals request"
+// @Success 200 {array} entity.HospitalResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get nearest hospitals"
+// @Router /api/v1/hospitals/nearest [post]
+func (h *hospital) GetNearestHospitals(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.NearestHospitalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	hospitals, err := h.s.Hospital.GetNearestHospitals(ctx, req)
+	if errors.Is(err, entity.ErrInvalidParam) {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("GetNearestHospitals error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to get nearest hospitals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hospitals)
+}
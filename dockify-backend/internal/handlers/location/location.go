@@ -1,7 +1,10 @@
 package location
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/askaroe/dockify-backend/internal/entity"
 	"github.com/askaroe/dockify-backend/internal/services"
@@ -11,6 +14,13 @@ import (
 
 type Location interface {
 	GetNearestUsers(c *gin.Context)
+	GetLocationSharing(c *gin.Context)
+	UpdateLocationSharing(c *gin.Context)
+	GetLocationHistory(c *gin.Context)
+	GetVisits(c *gin.Context)
+	GetTrips(c *gin.Context)
+	ExportLocationHistory(c *gin.Context)
+	GetPatientLocationHistory(c *gin.Context)
 }
 
 type location struct {
@@ -24,14 +34,18 @@ func NewLocationHandler(s *services.Service, logger *utils.Logger) Location {
 
 // GetNearestUsers godoc
 // @Summary Get nearest users
-// @Description Retrieves users nearest to given coordinates within a radius.
+// @Description Retrieves users nearest to given coordinates within a radius (default 5000, max 50000 metres).
+// @Description Only users who share their location with the caller and reported it recently are returned.
+// @Description Friends see exact positions; other users only see the centre of a grid cell (approximate=true).
 // @Tags location
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param request body entity.NearestUsersRequest true "Nearest users request"
 // @Success 200 {array} entity.NearestUsersResponse
 // @Success 204 {object} nil "no content"
 // @Failure 400 {object} entity.ErrorMessage
+// @Failure 401 {object} entity.ErrorMessage
 // @Failure 500 {object} entity.ErrorMessage
 // @Router /api/v1/location/nearest [post]
 func (l *location) GetNearestUsers(c *gin.Context) {
@@ -42,8 +56,13 @@ func (l *location) GetNearestUsers(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
 		return
 	}
+	request.UserId = c.GetInt(entity.ContextKeyUserID)
 
 	users, err := l.s.Location.GetNearestUsers(ctx, request)
+	if errors.Is(err, entity.ErrInvalidParam) {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+		return
+	}
 	if err != nil {
 		l.logger.Errorf("GetNearestUsers error: %v", err)
 		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to get nearest users"})
@@ -56,3 +75,320 @@ func (l *location) GetNearestUsers(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, users)
 }
+
+// GetLocationSharing godoc
+// @Summary Get location sharing settings
+// @Description Returns who the caller's location is shared with in nearby user searches.
+// @Tags location
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} entity.LocationSharingResponse
+// @Failure 401 {object} entity.ErrorMessage
+// @Failure 404 {object} entity.ErrorMessage
+// @Failure 500 {object} entity.ErrorMessage
+// @Router /api/v1/location/sharing [get]
+func (l *location) GetLocationSharing(c *gin.Context) {
+	settings, err := l.s.Location.GetLocationSharing(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if errors.Is(err, entity.ErrNotFound) {
+		c.JSON(http.StatusNotFound, entity.ErrorMessage{Message: "user not found"})
+		return
+	}
+	if err != nil {
+		l.logger.Errorf("GetLocationSharing error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to get location sharing"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateLocationSharing godoc
+// @Summary Update location sharing settings
+// @Description Sets who sees the caller in nearby user searches: off (nobody, the default), friends or everyone.
+// @Tags location
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.LocationSharingRequest true "Sharing mode"
+// @Success 200 {object} entity.LocationSharingResponse
+// @Failure 400 {object} entity.ErrorMessage
+// @Failure 401 {object} entity.ErrorMessage
+// @Failure 404 {object} entity.ErrorMessage
+// @Failure 500 {object} entity.ErrorMessage
+// @Router /api/v1/location/sharing [put]
+func (l *location) UpdateLocationSharing(c *gin.Context) {
+	var request entity.LocationSharingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	request.UserId = c.GetInt(entity.ContextKeyUserID)
+
+	settings, err := l.s.Location.UpdateLocationSharing(c.Request.Context(), request)
+	switch {
+	case errors.Is(err, entity.ErrInvalidParam):
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+		return
+	case errors.Is(err, entity.ErrNotFound):
+		c.JSON(http.StatusNotFound, entity.ErrorMessage{Message: "user not found"})
+		return
+	case err != nil:
+		l.logger.Errorf("UpdateLocationSharing error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to update location sharing"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// GetLocationHistory godoc
+// @Summary Get location history
+// @Description Returns the caller's recorded locations in a time range, oldest first. The range defaults to the
+// @Description last 24 hours and may span at most 31 days.
+// @Tags location
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Inclusive lower bound (RFC3339), default 24 hours before to"
+// @Param to query string false "Exclusive upper bound (RFC3339), default now"
+// @Param limit query int false "Maximum number of points (max 10000)" default(1000)
+// @Success 200 {array} entity.LocationPoint
+// @Failure 400 {object} entity.ErrorMessage
+// @Failure 401 {object} entity.ErrorMessage
+// @Failure 500 {object} entity.ErrorMessage
+// @Router /api/v1/location/history [get]
+func (l *location) GetLocationHistory(c *gin.Context) {
+	query, ok := parseHistoryQuery(c)
+	if !ok {
+		return
+	}
+
+	points, err := l.s.Location.GetLocationHistory(c.Request.Context(), query)
+	if err != nil {
+		l.respondError(c, "GetLocationHistory", err, "failed to get location history")
+		return
+	}
+
+	c.JSON(http.StatusOK, points)
+}
+
+// GetPatientLocationHistory godoc
+// @Summary Get a care team member's location history
+// @Description Recorded locations of the user with the given id, who shared their location with the caller, oldest
+// @Description first. The range defaults to the last 24 hours and may span at most 31 days. Every read is recorded in
+// @Description the access log.
+// @Tags Care
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID of the user who granted access"
+// @Param from query string false "Inclusive lower bound (RFC3339), default 24 hours before to"
+// @Param to query string false "Exclusive upper bound (RFC3339), default now"
+// @Param limit query int false "Maximum number of points (max 10000)" default(1000)
+// @Success 200 {array} entity.LocationPoint
+// @Failure 400 {object} entity.ErrorMessage
+// @Failure 401 {object} entity.ErrorMessage
+// @Failure 403 {object} entity.ErrorMessage "location is not shared"
+// @Failure 404 {object} entity.ErrorMessage "no active grant"
+// @Failure 500 {object} entity.ErrorMessage
+// @Router /api/v1/care/patients/{id}/location/history [get]
+func (l *location) GetPatientLocationHistory(c *gin.Context) {
+	patientID, err := strconv.Atoi(c.Param(entity.RequestParamID))
+	if err != nil || patientID <= 0 {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid id"})
+		return
+	}
+
+	query, ok := parseHistoryQuery(c)
+	if !ok {
+		return
+	}
+	query.UserId = patientID
+
+	points, err := l.s.Care.GetPatientLocationHistory(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), query)
+	if err != nil {
+		l.respondError(c, "GetPatientLocationHistory", err, "failed to get location history")
+		return
+	}
+
+	c.JSON(http.StatusOK, points)
+}
+
+// GetVisits godoc
+// @Summary Get visits
+// @Description Clusters the caller's locations in a time range into stays: consecutive locations within 100 m of
+// @Description their centroid spanning at least 10 minutes.
+// @Tags location
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Inclusive lower bound (RFC3339), default 24 hours before to"
+// @Param to query string false "Exclusive upper bound (RFC3339), default now"
+// @Success 200 {array} entity.VisitResponse
+// @Failure 400 {object} entity.ErrorMessage
+// @Failure 401 {object} entity.ErrorMessage
+// @Failure 500 {object} entity.ErrorMessage
+// @Router /api/v1/location/history/visits [get]
+func (l *location) GetVisits(c *gin.Context) {
+	query, ok := parseHistoryQuery(c)
+	if !ok {
+		return
+	}
+
+	visits, err := l.s.Location.GetVisits(c.Request.Context(), query)
+	if err != nil {
+		l.respondError(c, "GetVisits", err, "failed to get visits")
+		return
+	}
+
+	c.JSON(http.StatusOK, visits)
+}
+
+// GetTrips godoc
+// @Summary Get trips
+// @Description Returns the movements between the caller's visits in a time range with the distance travelled.
+// @Tags location
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Inclusive lower bound (RFC3339), default 24 hours before to"
+// @Param to query string false "Exclusive upper bound (RFC3339), default now"
+// @Success 200 {array} entity.TripResponse
+// @Failure 400 {object} entity.ErrorMessage
+// @Failure 401 {object} entity.ErrorMessage
+// @Failure 500 {object} entity.ErrorMessage
+// @Router /api/v1/location/history/trips [get]
+func (l *location) GetTrips(c *gin.Context) {
+	query, ok := parseHistoryQuery(c)
+	if !ok {
+		return
+	}
+
+	trips, err := l.s.Location.GetTrips(c.Request.Context(), query)
+	if err != nil {
+		l.respondError(c, "GetTrips", err, "failed to get trips")
+		return
+	}
+
+	c.JSON(http.StatusOK, trips)
+}
+
+// ExportLocationHistory godoc
+// @Summary Export location history
+// @Description Streams the caller's locations in a time range as a GeoJSON FeatureCollection (LineString tracks
+// @Description followed by Point features) or as a GPX 1.1 track. Gaps of more than an hour start a new track
+// @Description segment. With heart_rate=true each point carries the heart rate measured closest to it within two
+// @Description minutes, as a property in GeoJSON and a Garmin TrackPointExtension in GPX. The range defaults to
+// @Description the last 30 days and may span at most 366 days.
+// @Tags location
+// @Security BearerAuth
+// @Produce application/geo+json
+// @Produce application/gpx+xml
+// @Param format query string false "geojson or gpx" default(geojson)
+// @Param from query string false "Inclusive lower bound (RFC3339), default 30 days before to"
+// @Param to query string false "Exclusive upper bound (RFC3339), default now"
+// @Param heart_rate query bool false "Attach heart rate measurements" default(false)
+// @Success 200 {file} file
+// @Failure 400 {object} entity.ErrorMessage
+// @Failure 401 {object} entity.ErrorMessage
+// @Failure 500 {object} entity.ErrorMessage
+// @Router /api/v1/location/history/export [get]
+func (l *location) ExportLocationHistory(c *gin.Context) {
+	rangeQuery, ok := parseHistoryQuery(c)
+	if !ok {
+		return
+	}
+
+	query := entity.LocationExportQuery{TimeRangeQuery: rangeQuery, Format: c.DefaultQuery(entity.QueryParamFormat, "geojson")}
+	if heartRate := c.Query(entity.QueryParamHeartRate); heartRate != "" {
+		var err error
+		if query.HeartRate, err = strconv.ParseBool(heartRate); err != nil {
+			c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "heart_rate must be true or false"})
+			return
+		}
+	}
+
+	contentType := "application/geo+json"
+	if query.Format == "gpx" {
+		contentType = "application/gpx+xml"
+	}
+	w := &streamWriter{
+		c:           c,
+		contentType: contentType,
+		filename:    "location-history-" + time.Now().UTC().Format("20060102") + "." + query.Format,
+	}
+
+	err := l.s.Location.ExportLocationHistory(c.Request.Context(), query, w)
+	if err == nil {
+		return
+	}
+	if w.started {
+		// the status is already sent; the client sees a truncated file
+		l.logger.Errorf("ExportLocationHistory error: %v", err)
+		return
+	}
+	l.respondError(c, "ExportLocationHistory", err, "failed to export location history")
+}
+
+// streamWriter sends the response headers with the first write, so errors
+// found before any output can still be answered with a JSON error.
+type streamWriter struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.c.Header("Content-Type", w.contentType)
+		w.c.Header("Content-Disposition", `attachment; filename="`+w.filename+`"`)
+		w.c.Status(http.StatusOK)
+	}
+	return w.c.Writer.Write(p)
+}
+
+func parseHistoryQuery(c *gin.Context) (entity.TimeRangeQuery, bool) {
+	query := entity.TimeRangeQuery{UserId: c.GetInt(entity.ContextKeyUserID)}
+
+	bounds := []struct {
+		key  string
+		dest **time.Time
+	}{{entity.QueryParamFrom, &query.From}, {entity.QueryParamTo, &query.To}}
+
+	for _, b := range bounds {
+		key, dest := b.key, b.dest
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: key + " must be an RFC3339 timestamp"})
+			return entity.TimeRangeQuery{}, false
+		}
+		*dest = &t
+	}
+
+	if limit := c.Query(entity.QueryParamLimit); limit != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit <= 0 {
+			c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "limit must be a positive integer"})
+			return entity.TimeRangeQuery{}, false
+		}
+	}
+
+	return query, true
+}
+
+func (l *location) respondError(c *gin.Context, op string, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidParam):
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrForbidden):
+		c.JSON(http.StatusForbidden, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrNotFound):
+		c.JSON(http.StatusNotFound, entity.ErrorMessage{Message: "not found"})
+	default:
+		l.logger.Errorf("%s error: %v", op, err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: message})
+	}
+}
@@ -0,0 +1,202 @@
+package prediction
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/gateway/mindspore"
+	"github.com/askaroe/dockify-backend/internal/services"
+	"github.com/askaroe/dockify-backend/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Prediction interface {
+	PredictSleep(c *gin.Context)
+	PredictLifestyle(c *gin.Context)
+	PredictSleepFromData(c *gin.Context)
+	PredictLifestyleFromData(c *gin.Context)
+	GetPredictions(c *gin.Context)
+}
+
+type prediction struct {
+	s      *services.Service
+	logger *utils.Logger
+}
+
+func NewPredictionHandler(s *services.Service, logger *utils.Logger) Prediction {
+	return &prediction{s: s, logger: logger}
+}
+
+// PredictSleep
+// @Summary Predict sleep quality
+// @Description Run the MindSpore sleep model on the given input and store the result
+// @Tags Predictions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body mindspore.PredictSleepRequest true "sleep model input"
+// @Success 200 {object} entity.SleepPredictionResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to predict sleep"
+// @Failure 502 {object} entity.ErrorMessage "prediction model unavailable"
+// @Router /api/v1/predict/sleep [post]
+func (p *prediction) PredictSleep(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req mindspore.PredictSleepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	result, err := p.s.Prediction.PredictSleep(ctx, c.GetInt(entity.ContextKeyUserID), req)
+	if err != nil {
+		p.respondError(c, "PredictSleep", err, "failed to predict sleep")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PredictLifestyle
+// @Summary Predict lifestyle
+// @Description Run the MindSpore lifestyle model on the given input and store the result. bmi is computed from
+// @Description weight and height when omitted.
+// @Tags Predictions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body mindspore.PredictLifestyleRequest true "lifestyle model input"
+// @Success 200 {object} entity.LifestylePredictionResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to predict lifestyle"
+// @Failure 502 {object} entity.ErrorMessage "prediction model unavailable"
+// @Router /api/v1/predict/lifestyle [post]
+func (p *prediction) PredictLifestyle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req mindspore.PredictLifestyleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	result, err := p.s.Prediction.PredictLifestyle(ctx, c.GetInt(entity.ContextKeyUserID), req)
+	if err != nil {
+		p.respondError(c, "PredictLifestyle", err, "failed to predict lifestyle")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PredictSleepFromData
+// @Summary Predict sleep quality from my data
+// @Description Predict the quality of the authenticated user's latest sleep session. Heart rate falls back to the
+// @Description heart_rate samples recorded during the session.
+// @Tags Predictions
+// @Security BearerAuth
+// @Produce json
+// @Param tz query string false "IANA timezone for day of week and start hour" default(UTC)
+// @Success 200 {object} entity.SleepPredictionResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 422 {object} entity.ErrorMessage "not enough data"
+// @Failure 500 {object} entity.ErrorMessage "failed to predict sleep"
+// @Failure 502 {object} entity.ErrorMessage "prediction model unavailable"
+// @Router /api/v1/predict/sleep/me [post]
+func (p *prediction) PredictSleepFromData(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	result, err := p.s.Prediction.PredictSleepFromData(ctx, c.GetInt(entity.ContextKeyUserID), c.Query(entity.QueryParamTimezone))
+	if err != nil {
+		p.respondError(c, "PredictSleepFromData", err, "failed to predict sleep")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PredictLifestyleFromData
+// @Summary Predict lifestyle from my data
+// @Description Predict the authenticated user's lifestyle from their birth date, latest weight, height, body fat
+// @Description and resting heart rate, workouts of the last 4 weeks and daily calories and water of the last 7 days.
+// @Description Responds 422 listing every missing input.
+// @Tags Predictions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} entity.LifestylePredictionResponse
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 422 {object} entity.ErrorMessage "not enough data"
+// @Failure 500 {object} entity.ErrorMessage "failed to predict lifestyle"
+// @Failure 502 {object} entity.ErrorMessage "prediction model unavailable"
+// @Router /api/v1/predict/lifestyle/me [post]
+func (p *prediction) PredictLifestyleFromData(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	result, err := p.s.Prediction.PredictLifestyleFromData(ctx, c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		p.respondError(c, "PredictLifestyleFromData", err, "failed to predict lifestyle")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetPredictions
+// @Summary List predictions
+// @Description List the authenticated user's stored predictions, newest first
+// @Tags Predictions
+// @Security BearerAuth
+// @Produce json
+// @Param kind query string false "sleep or lifestyle"
+// @Param limit query int false "Maximum number of items (max 100)" default(20)
+// @Success 200 {array} models.Prediction
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get predictions"
+// @Router /api/v1/predictions [get]
+func (p *prediction) GetPredictions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query := entity.PredictionsQuery{
+		UserId: c.GetInt(entity.ContextKeyUserID),
+		Kind:   c.Query(entity.QueryParamKind),
+	}
+
+	if limit := c.Query(entity.QueryParamLimit); limit != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit <= 0 {
+			c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "limit must be a positive integer"})
+			return
+		}
+	}
+
+	result, err := p.s.Prediction.GetPredictions(ctx, query)
+	if err != nil {
+		p.respondError(c, "GetPredictions", err, "failed to get predictions")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (p *prediction) respondError(c *gin.Context, op string, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidParam):
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrInsufficientData):
+		c.JSON(http.StatusUnprocessableEntity, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrModelUnavailable):
+		p.logger.Errorf("%s error: %v", op, err)
+		c.JSON(http.StatusBadGateway, entity.ErrorMessage{Message: "prediction model unavailable"})
+	default:
+		p.logger.Errorf("%s error: %v", op, err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: message})
+	}
+}
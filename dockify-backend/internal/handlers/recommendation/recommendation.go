@@ -0,0 +1,128 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/services"
+	"github.com/askaroe/dockify-backend/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Recommendation interface {
+	GetRecommendations(c *gin.Context)
+	MarkRecommendationRead(c *gin.Context)
+	DismissRecommendation(c *gin.Context)
+}
+
+type recommendation struct {
+	s      *services.Service
+	logger *utils.Logger
+}
+
+func NewRecommendationHandler(s *services.Service, logger *utils.Logger) Recommendation {
+	return &recommendation{s: s, logger: logger}
+}
+
+// GetRecommendations
+// @Summary Get recommendations
+// @Description Evaluate the authenticated user's recent metrics, sleep, blood pressure and latest predictions and
+// @Description return open recommendations ranked by priority. Rules are re-evaluated when the first page is
+// @Description requested. recommendation holds the top message for older clients.
+// @Tags Recommendation
+// @Security BearerAuth
+// @Produce json
+// @Param cursor query string false "Opaque cursor from next_cursor of the previous page"
+// @Param limit query int false "Page size (max 100)" default(20)
+// @Success 200 {object} entity.RecommendationResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get recommendations"
+// @Router /api/v1/recommendation [get]
+func (r *recommendation) GetRecommendations(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query := entity.RecommendationQuery{
+		UserId: c.GetInt(entity.ContextKeyUserID),
+		Cursor: c.Query(entity.QueryParamCursor),
+	}
+
+	if limit := c.Query(entity.QueryParamLimit); limit != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit <= 0 {
+			c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "limit must be a positive integer"})
+			return
+		}
+	}
+
+	result, err := r.s.Recommendation.GetRecommendations(ctx, query)
+	if errors.Is(err, entity.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid cursor"})
+		return
+	}
+	if err != nil {
+		r.logger.Errorf("GetRecommendations error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to get recommendations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// MarkRecommendationRead
+// @Summary Mark recommendation as read
+// @Tags Recommendation
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Recommendation ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to mark recommendation as read"
+// @Router /api/v1/recommendation/{id}/read [post]
+func (r *recommendation) MarkRecommendationRead(c *gin.Context) {
+	r.update(c, "MarkRecommendationRead", r.s.Recommendation.MarkRecommendationRead, "failed to mark recommendation as read")
+}
+
+// DismissRecommendation
+// @Summary Dismiss recommendation
+// @Description Hide a recommendation. Its rule will not produce a new recommendation for 7 days.
+// @Tags Recommendation
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Recommendation ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to dismiss recommendation"
+// @Router /api/v1/recommendation/{id}/dismiss [post]
+func (r *recommendation) DismissRecommendation(c *gin.Context) {
+	r.update(c, "DismissRecommendation", r.s.Recommendation.DismissRecommendation, "failed to dismiss recommendation")
+}
+
+func (r *recommendation) update(c *gin.Context, op string, fn func(ctx context.Context, userID, id int) error, message string) {
+	id, err := strconv.Atoi(c.Param(entity.RequestParamID))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid id"})
+		return
+	}
+
+	err = fn(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), id)
+	if errors.Is(err, entity.ErrNotFound) {
+		c.JSON(http.StatusNotFound, entity.ErrorMessage{Message: "not found"})
+		return
+	}
+	if err != nil {
+		r.logger.Errorf("%s error: %v", op, err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: message})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
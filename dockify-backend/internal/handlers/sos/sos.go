@@ -0,0 +1,289 @@
+package sos
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/services"
+	"github.com/askaroe/dockify-backend/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type SOS interface {
+	CreateIncident(c *gin.Context)
+	GetIncidents(c *gin.Context)
+	GetIncident(c *gin.Context)
+	GetIncidentTimeline(c *gin.Context)
+	AcknowledgeIncident(c *gin.Context)
+	ResolveIncident(c *gin.Context)
+	GetSOSSettings(c *gin.Context)
+	UpdateSOSSettings(c *gin.Context)
+}
+
+type sos struct {
+	s      *services.Service
+	logger *utils.Logger
+}
+
+func NewSOSHandler(s *services.Service, logger *utils.Logger) SOS {
+	return &sos{s: s, logger: logger}
+}
+
+// CreateIncident
+// @Summary Raise SOS
+// @Description Creates an incident at the given location and alerts the nearest users who opted in as responders and reported a location nearby recently.
+// @Description The response lists the alerted responders, with distances rounded up to 100 m and user ids only once they acknowledge; a failed delivery is recorded in the timeline.
+// @Description A user can raise 3 incidents per 10 minutes.
+// @Tags SOS
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.SOSRequest true "Location of the emergency"
+// @Success 201 {object} entity.IncidentResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 429 {object} entity.ErrorMessage "too many requests"
+// @Failure 500 {object} entity.ErrorMessage "failed to create incident"
+// @Router /api/v1/sos [post]
+func (s *sos) CreateIncident(c *gin.Context) {
+	var request entity.SOSRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	request.UserId = c.GetInt(entity.ContextKeyUserID)
+
+	incident, err := s.s.SOS.CreateIncident(c.Request.Context(), request)
+	if err != nil {
+		s.respondError(c, "CreateIncident", err, "failed to create incident")
+		return
+	}
+
+	c.JSON(http.StatusCreated, incident)
+}
+
+// GetIncidents
+// @Summary List incidents
+// @Description The latest 50 incidents the caller raised or was alerted about, newest first.
+// @Tags SOS
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entity.IncidentResponse
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get incidents"
+// @Router /api/v1/sos [get]
+func (s *sos) GetIncidents(c *gin.Context) {
+	incidents, err := s.s.SOS.GetIncidents(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		s.logger.Errorf("GetIncidents error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to get incidents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, incidents)
+}
+
+// GetIncident
+// @Summary Get incident
+// @Description Visible to the user who raised the incident, who also sees the responders, and to the alerted responders.
+// @Tags SOS
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Incident ID"
+// @Success 200 {object} entity.IncidentResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to get incident"
+// @Router /api/v1/sos/{id} [get]
+func (s *sos) GetIncident(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+
+	incident, err := s.s.SOS.GetIncident(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), id)
+	if err != nil {
+		s.respondError(c, "GetIncident", err, "failed to get incident")
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+// GetIncidentTimeline
+// @Summary Get incident timeline
+// @Description Events of the incident in order: created, notified, notify_failed, acknowledged and resolved.
+// @Description Responders do not see the events about other responders.
+// @Tags SOS
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Incident ID"
+// @Success 200 {array} entity.IncidentEventResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid id"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to get incident timeline"
+// @Router /api/v1/sos/{id}/timeline [get]
+func (s *sos) GetIncidentTimeline(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+
+	events, err := s.s.SOS.GetIncidentTimeline(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), id)
+	if err != nil {
+		s.respondError(c, "GetIncidentTimeline", err, "failed to get incident timeline")
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// AcknowledgeIncident
+// @Summary Acknowledge incident
+// @Description Tells the user who raised the incident that the caller, an alerted responder, is on the way.
+// @Tags SOS
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Incident ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "incident is already resolved"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "not a responder"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to acknowledge incident"
+// @Router /api/v1/sos/{id}/acknowledge [post]
+func (s *sos) AcknowledgeIncident(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+
+	if err := s.s.SOS.AcknowledgeIncident(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), id); err != nil {
+		s.respondError(c, "AcknowledgeIncident", err, "failed to acknowledge incident")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ResolveIncident
+// @Summary Resolve incident
+// @Description Closes the caller's incident and tells the alerted responders that help is no longer needed.
+// @Tags SOS
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Incident ID"
+// @Param request body entity.ResolveIncidentRequest false "Optional note for the timeline"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "incident is already resolved"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "not the user who raised the incident"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to resolve incident"
+// @Router /api/v1/sos/{id}/resolve [post]
+func (s *sos) ResolveIncident(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+
+	var request entity.ResolveIncidentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+			return
+		}
+	}
+
+	if err := s.s.SOS.ResolveIncident(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), id, request); err != nil {
+		s.respondError(c, "ResolveIncident", err, "failed to resolve incident")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSOSSettings
+// @Summary Get SOS settings
+// @Description Whether the caller is alerted about emergencies nearby.
+// @Tags SOS
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} entity.SOSSettings
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to get sos settings"
+// @Router /api/v1/sos/settings [get]
+func (s *sos) GetSOSSettings(c *gin.Context) {
+	settings, err := s.s.SOS.GetSOSSettings(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		s.respondError(c, "GetSOSSettings", err, "failed to get sos settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSOSSettings
+// @Summary Update SOS settings
+// @Description Opt in to or out of alerts about emergencies near the caller's latest location.
+// @Tags SOS
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.SOSSettings true "SOS settings"
+// @Success 200 {object} entity.SOSSettings
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to update sos settings"
+// @Router /api/v1/sos/settings [put]
+func (s *sos) UpdateSOSSettings(c *gin.Context) {
+	var request entity.SOSSettings
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	request.UserId = c.GetInt(entity.ContextKeyUserID)
+
+	settings, err := s.s.SOS.UpdateSOSSettings(c.Request.Context(), request)
+	if err != nil {
+		s.respondError(c, "UpdateSOSSettings", err, "failed to update sos settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (s *sos) respondError(c *gin.Context, op string, err error, message string) {
+	var retry *entity.RetryAfterError
+	switch {
+	case errors.As(err, &retry):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retry.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrInvalidParam):
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrForbidden):
+		c.JSON(http.StatusForbidden, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrNotFound):
+		c.JSON(http.StatusNotFound, entity.ErrorMessage{Message: "not found"})
+	default:
+		s.logger.Errorf("%s error: %v", op, err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: message})
+	}
+}
+
+func incidentID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param(entity.RequestParamID))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
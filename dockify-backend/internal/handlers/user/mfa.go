@@ -0,0 +1,184 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginMFA
+// @Summary Complete login with a second factor
+// @Description Exchange the mfa_token from login, valid for 5 minutes, and a code from the authenticator app or a
+// @Description recovery code for tokens. A code works only once. Wrong codes count as failed logins.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param request body entity.MFALoginRequest true "Challenge token and code"
+// @Success 200 {object} entity.LoginResponse "authenticated user and tokens"
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "invalid or expired code"
+// @Failure 429 {object} entity.ErrorMessage "too many requests"
+// @Router /api/v1/login/mfa [post]
+func (u *user) LoginMFA(c *gin.Context) {
+	var req entity.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	req.IPAddress = c.ClientIP()
+
+	loginResponse, err := u.s.User.LoginMFA(c.Request.Context(), req)
+	if errors.Is(err, entity.ErrTooManyRequests) {
+		u.respondError(c, "LoginMFA", err, "failed to login")
+		return
+	}
+	if err != nil {
+		u.logger.Errorf("LoginMFA error: %v", err)
+		c.JSON(http.StatusUnauthorized, entity.ErrorMessage{Message: "invalid or expired code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse)
+}
+
+// GetMFAStatus
+// @Summary Get two-factor authentication status
+// @Description Whether logging in needs a code from an authenticator app, and how many recovery codes are left
+// @Tags MFA
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} entity.MFAStatusResponse
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get mfa status"
+// @Router /api/v1/me/mfa [get]
+func (u *user) GetMFAStatus(c *gin.Context) {
+	status, err := u.s.User.GetMFAStatus(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		u.respondError(c, "GetMFAStatus", err, "failed to get mfa status")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// SetupMFA
+// @Summary Set up two-factor authentication
+// @Description Create a TOTP secret for an authenticator app, which imports it from a QR code of provisioning_uri.
+// @Description Nothing changes until the setup is confirmed with a code at /me/mfa/enable; setting up again replaces
+// @Description an unconfirmed secret.
+// @Tags MFA
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} entity.MFASetupResponse
+// @Failure 400 {object} entity.ErrorMessage "two-factor authentication is already enabled"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to set up mfa"
+// @Router /api/v1/me/mfa/setup [post]
+func (u *user) SetupMFA(c *gin.Context) {
+	setup, err := u.s.User.SetupMFA(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		u.respondError(c, "SetupMFA", err, "failed to set up mfa")
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// EnableMFA
+// @Summary Enable two-factor authentication
+// @Description Confirm the setup with a code from the authenticator app. Logging in needs a code from then on.
+// @Description The response lists 10 single use recovery codes for a lost phone; they are not shown again.
+// @Tags MFA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} entity.RecoveryCodesResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "invalid code"
+// @Failure 429 {object} entity.ErrorMessage "too many requests"
+// @Failure 500 {object} entity.ErrorMessage "failed to enable mfa"
+// @Router /api/v1/me/mfa/enable [post]
+func (u *user) EnableMFA(c *gin.Context) {
+	var req entity.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	req.IPAddress = c.ClientIP()
+
+	codes, err := u.s.User.EnableMFA(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), req)
+	if err != nil {
+		u.respondError(c, "EnableMFA", err, "failed to enable mfa")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// DisableMFA
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off with the password and a code from the authenticator app or a
+// @Description recovery code. The recovery codes are deleted.
+// @Tags MFA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.DisableMFARequest true "Password and code"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "two-factor authentication is not enabled"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "password or code is incorrect"
+// @Failure 429 {object} entity.ErrorMessage "too many requests"
+// @Failure 500 {object} entity.ErrorMessage "failed to disable mfa"
+// @Router /api/v1/me/mfa/disable [post]
+func (u *user) DisableMFA(c *gin.Context) {
+	var req entity.DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	req.IPAddress = c.ClientIP()
+
+	if err := u.s.User.DisableMFA(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), req); err != nil {
+		u.respondError(c, "DisableMFA", err, "failed to disable mfa")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes with 10 new ones after checking a code from the authenticator app
+// @Tags MFA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} entity.RecoveryCodesResponse
+// @Failure 400 {object} entity.ErrorMessage "two-factor authentication is not enabled"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "invalid code"
+// @Failure 429 {object} entity.ErrorMessage "too many requests"
+// @Failure 500 {object} entity.ErrorMessage "failed to regenerate recovery codes"
+// @Router /api/v1/me/mfa/recovery-codes [post]
+func (u *user) RegenerateRecoveryCodes(c *gin.Context) {
+	var req entity.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	req.IPAddress = c.ClientIP()
+
+	codes, err := u.s.User.RegenerateRecoveryCodes(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), req)
+	if err != nil {
+		u.respondError(c, "RegenerateRecoveryCodes", err, "failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
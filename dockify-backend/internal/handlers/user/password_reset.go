@@ -0,0 +1,67 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// ForgotPassword
+// @Summary Forgot password
+// @Description Email a single use password reset token, valid for one hour, if an account exists for the email.
+// @Description The response is the same whether or not it does. Limited per email and per client IP.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body entity.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} nil "accepted"
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 429 {object} entity.ErrorMessage "too many requests"
+// @Header 429 {integer} Retry-After "seconds until another request is allowed"
+// @Router /api/v1/password/forgot [post]
+func (u *user) ForgotPassword(c *gin.Context) {
+	var req entity.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	req.IPAddress = c.ClientIP()
+
+	if err := u.s.User.ForgotPassword(c.Request.Context(), req); err != nil {
+		u.respondError(c, "ForgotPassword", err, "failed to send password reset email")
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword
+// @Summary Reset password
+// @Description Set a new password with the token from the password reset email. The token is used up and every
+// @Description session of the account is signed out. Limited per client IP.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body entity.ResetPasswordRequest true "Token and new password"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid or expired token"
+// @Failure 429 {object} entity.ErrorMessage "too many requests"
+// @Header 429 {integer} Retry-After "seconds until another request is allowed"
+// @Failure 500 {object} entity.ErrorMessage "failed to reset password"
+// @Router /api/v1/password/reset [post]
+func (u *user) ResetPassword(c *gin.Context) {
+	var req entity.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	req.IPAddress = c.ClientIP()
+
+	if err := u.s.User.ResetPassword(c.Request.Context(), req); err != nil {
+		u.respondError(c, "ResetPassword", err, "failed to reset password")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
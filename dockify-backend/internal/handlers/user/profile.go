@@ -0,0 +1,203 @@
+package user
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// GetProfile
+// @Summary Get profile
+// @Description Profile of the authenticated user
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.User
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to get profile"
+// @Router /api/v1/me [get]
+func (u *user) GetProfile(c *gin.Context) {
+	profile, err := u.s.User.GetProfile(c.Request.Context(), c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		u.respondError(c, "GetProfile", err, "failed to get profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile
+// @Summary Update profile
+// @Description Change the fields present in the request. Changing the email needs current_password; the new address
+// @Description has to be verified again and is sent a verification email, and the old one is told about the change.
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.UpdateProfileRequest true "Profile fields to change"
+// @Success 200 {object} models.User
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "current password is incorrect"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 409 {object} entity.ErrorMessage "username or email is already taken"
+// @Failure 500 {object} entity.ErrorMessage "failed to update profile"
+// @Router /api/v1/me [patch]
+func (u *user) UpdateProfile(c *gin.Context) {
+	var req entity.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	req.UserId = c.GetInt(entity.ContextKeyUserID)
+
+	profile, err := u.s.User.UpdateProfile(c.Request.Context(), req)
+	if err != nil {
+		u.respondError(c, "UpdateProfile", err, "failed to update profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ChangePassword
+// @Summary Change password
+// @Description Replace the password of the authenticated user and sign out every other session
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.ChangePasswordRequest true "Current and new password"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "current password is incorrect"
+// @Failure 500 {object} entity.ErrorMessage "failed to change password"
+// @Router /api/v1/me/password [post]
+func (u *user) ChangePassword(c *gin.Context) {
+	var req entity.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	err := u.s.User.ChangePassword(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), c.GetInt(entity.ContextKeySessionID), req)
+	if err != nil {
+		u.respondError(c, "ChangePassword", err, "failed to change password")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SendEmailVerification
+// @Summary Resend verification email
+// @Description Send a new verification email to the authenticated user's unverified address. The token in it is valid for 24 hours.
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "email is already verified"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to send verification email"
+// @Router /api/v1/me/email/verification [post]
+func (u *user) SendEmailVerification(c *gin.Context) {
+	if err := u.s.User.SendEmailVerification(c.Request.Context(), c.GetInt(entity.ContextKeyUserID)); err != nil {
+		u.respondError(c, "SendEmailVerification", err, "failed to send verification email")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// VerifyEmail
+// @Summary Verify email
+// @Description Verify an email address with the token from the verification email. Needs no access token.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body entity.VerifyEmailRequest true "Token from the verification email"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid or expired token"
+// @Failure 500 {object} entity.ErrorMessage "failed to verify email"
+// @Router /api/v1/email/verify [post]
+func (u *user) VerifyEmail(c *gin.Context) {
+	var req entity.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	if err := u.s.User.VerifyEmail(c.Request.Context(), req); err != nil {
+		u.respondError(c, "VerifyEmail", err, "failed to verify email")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteAccount
+// @Summary Delete account
+// @Description Schedule the authenticated user's account for deletion and sign out every session. After the grace
+// @Description period the account is purged with all of its data; logging in before then restores it.
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.DeleteAccountRequest true "Password confirmation"
+// @Success 202 {object} entity.AccountDeletionResponse
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 403 {object} entity.ErrorMessage "password is incorrect"
+// @Failure 404 {object} entity.ErrorMessage "not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to delete account"
+// @Router /api/v1/me [delete]
+func (u *user) DeleteAccount(c *gin.Context) {
+	var req entity.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	response, err := u.s.User.DeleteAccount(c.Request.Context(), c.GetInt(entity.ContextKeyUserID), req)
+	if err != nil {
+		u.respondError(c, "DeleteAccount", err, "failed to delete account")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+func (u *user) respondError(c *gin.Context, op string, err error, message string) {
+	var (
+		retry      *entity.RetryAfterError
+		validation *entity.ValidationError
+		conflict   *entity.ConflictError
+	)
+	switch {
+	case errors.As(err, &retry):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retry.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, entity.ErrorMessage{Message: err.Error()})
+	case errors.As(err, &validation):
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "validation failed", Details: validation.Fields})
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, entity.ErrorMessage{
+			Message: err.Error(),
+			Details: []entity.FieldError{{Field: conflict.Field, Message: "is already taken"}},
+		})
+	case errors.Is(err, entity.ErrInvalidParam):
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrForbidden):
+		c.JSON(http.StatusForbidden, entity.ErrorMessage{Message: err.Error()})
+	case errors.Is(err, entity.ErrNotFound):
+		c.JSON(http.StatusNotFound, entity.ErrorMessage{Message: "not found"})
+	default:
+		u.logger.Errorf("%s error: %v", op, err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: message})
+	}
+}
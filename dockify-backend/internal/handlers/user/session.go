@@ -0,0 +1,115 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// Logout
+// @Summary Logout
+// @Description Revoke the session the access token belongs to
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Success 204 {object} nil "no content"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to logout"
+// @Router /api/v1/logout [post]
+func (u *user) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	err := u.s.User.Logout(ctx, c.GetInt(entity.ContextKeyUserID), c.GetInt(entity.ContextKeySessionID))
+	if err != nil && !errors.Is(err, entity.ErrNotFound) {
+		u.logger.Errorf("Logout error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to logout"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll
+// @Summary Logout everywhere
+// @Description Revoke every session of the authenticated user
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Success 204 {object} nil "no content"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to logout"
+// @Router /api/v1/logout/all [post]
+func (u *user) LogoutAll(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	err := u.s.User.LogoutAll(ctx, c.GetInt(entity.ContextKeyUserID))
+	if err != nil {
+		u.logger.Errorf("LogoutAll error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to logout"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSessions
+// @Summary List sessions
+// @Description List active sessions (devices) of the authenticated user
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entity.SessionResponse
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 500 {object} entity.ErrorMessage "failed to get sessions"
+// @Router /api/v1/sessions [get]
+func (u *user) GetSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	sessions, err := u.s.User.GetSessions(ctx, c.GetInt(entity.ContextKeyUserID), c.GetInt(entity.ContextKeySessionID))
+	if err != nil {
+		u.logger.Errorf("GetSessions error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to get sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// DeleteSession
+// @Summary Revoke session
+// @Description Revoke one of the authenticated user's sessions
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 204 {object} nil "no content"
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "unauthorized"
+// @Failure 404 {object} entity.ErrorMessage "session not found"
+// @Failure 500 {object} entity.ErrorMessage "failed to revoke session"
+// @Router /api/v1/sessions/{id} [delete]
+func (u *user) DeleteSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	sessionID, err := strconv.Atoi(c.Param(entity.RequestParamID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+
+	err = u.s.User.RevokeSession(ctx, c.GetInt(entity.ContextKeyUserID), sessionID)
+	if errors.Is(err, entity.ErrNotFound) {
+		c.JSON(http.StatusNotFound, entity.ErrorMessage{Message: "session not found"})
+		return
+	}
+	if err != nil {
+		u.logger.Errorf("DeleteSession error: %v", err)
+		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to revoke session"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
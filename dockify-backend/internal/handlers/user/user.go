@@ -1,6 +1,7 @@
 package user
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/askaroe/dockify-backend/internal/entity"
@@ -12,6 +13,27 @@ import (
 type User interface {
 	Register(c *gin.Context)
 	Login(c *gin.Context)
+	RefreshToken(c *gin.Context)
+	Logout(c *gin.Context)
+	LogoutAll(c *gin.Context)
+	GetSessions(c *gin.Context)
+	DeleteSession(c *gin.Context)
+
+	GetProfile(c *gin.Context)
+	UpdateProfile(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	SendEmailVerification(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	DeleteAccount(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+
+	LoginMFA(c *gin.Context)
+	GetMFAStatus(c *gin.Context)
+	SetupMFA(c *gin.Context)
+	EnableMFA(c *gin.Context)
+	DisableMFA(c *gin.Context)
+	RegenerateRecoveryCodes(c *gin.Context)
 }
 
 type user struct {
@@ -27,13 +49,16 @@ func NewUserHandler(s *services.Service, logger *utils.Logger) User {
 
 // Register
 // @Summary Register a new user
-// @Description Create a new user account
+// @Description Create a new user account. The username is 3 to 50 letters, digits, '_', '.' or '-'. The password is
+// @Description at least 8 characters, differs from the username and email and is not a known breached password.
+// @Description Invalid fields are listed in details.
 // @Tags User
 // @Accept json
 // @Produce json
 // @Param request body entity.UserRegisterRequest true "User registration payload"
 // @Success 201 {object} entity.CreatedUserResponse "created user id"
-// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 400 {object} entity.ErrorMessage "validation failed"
+// @Failure 409 {object} entity.ErrorMessage "username or email is already taken"
 // @Failure 500 {object} entity.ErrorMessage "failed to register user"
 // @Router /api/v1/register [post]
 func (u *user) Register(c *gin.Context) {
@@ -41,14 +66,13 @@ func (u *user) Register(c *gin.Context) {
 
 	var req entity.UserRegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		u.logger.Errorf("Bind json error: %v", err)
 		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
 	}
 
 	userId, err := u.s.User.Register(ctx, req)
 	if err != nil {
-		u.logger.Errorf("Register error: %v", err)
-		c.JSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to register user"})
+		u.respondError(c, "Register", err, "failed to register user")
 		return
 	}
 
@@ -57,14 +81,19 @@ func (u *user) Register(c *gin.Context) {
 
 // Login
 // @Summary User login
-// @Description Authenticate user and return user information
+// @Description Authenticate user and return user information with an access/refresh token pair.
+// @Description Repeated failures for an account or from a client make further attempts wait, doubling each time, and
+// @Description lock them out temporarily; until then the response is 429 with a Retry-After header in seconds.
+// @Description With two-factor authentication enabled the response is 202 with an mfa_token for /login/mfa instead.
 // @Tags User
 // @Accept json
 // @Produce json
 // @Param request body entity.UserLoginRequest true "User login payload"
-// @Success 200 {object} map[string]interface{} "authenticated user"
+// @Success 200 {object} entity.LoginResponse "authenticated user and tokens"
+// @Success 202 {object} entity.MFAChallengeResponse "second factor required"
 // @Failure 400 {object} entity.ErrorMessage "invalid request"
 // @Failure 401 {object} entity.ErrorMessage "invalid email or password"
+// @Failure 429 {object} entity.ErrorMessage "too many requests"
 // @Failure 500 {object} entity.ErrorMessage "internal server error"
 // @Router /api/v1/login [post]
 func (u *user) Login(c *gin.Context) {
@@ -75,13 +104,58 @@ func (u *user) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
 		return
 	}
+	req.IPAddress = c.ClientIP()
 
-	userResponse, err := u.s.User.Login(ctx, req)
+	loginResponse, err := u.s.User.Login(ctx, req)
+	var challenge *entity.MFAChallengeError
+	if errors.As(err, &challenge) {
+		c.JSON(http.StatusAccepted, entity.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    challenge.Token,
+			ExpiresIn:   int(challenge.ExpiresIn.Seconds()),
+		})
+		return
+	}
+	if errors.Is(err, entity.ErrTooManyRequests) {
+		u.respondError(c, "Login", err, "failed to login")
+		return
+	}
 	if err != nil {
 		u.logger.Errorf("Login error: %v", err)
 		c.JSON(http.StatusUnauthorized, entity.ErrorMessage{Message: "failed to login"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": userResponse})
+	c.JSON(http.StatusOK, loginResponse)
+}
+
+// RefreshToken
+// @Summary Refresh tokens
+// @Description Exchange a valid refresh token for a new access/refresh token pair. Reusing an already rotated refresh token revokes the session.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body entity.RefreshTokenRequest true "Refresh token payload"
+// @Success 200 {object} entity.TokenResponse "new tokens"
+// @Failure 400 {object} entity.ErrorMessage "invalid request"
+// @Failure 401 {object} entity.ErrorMessage "invalid refresh token"
+// @Router /api/v1/token/refresh [post]
+func (u *user) RefreshToken(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req entity.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, entity.ErrorMessage{Message: "invalid request"})
+		return
+	}
+	req.IPAddress = c.ClientIP()
+
+	tokens, err := u.s.User.RefreshToken(ctx, req)
+	if err != nil {
+		u.logger.Errorf("RefreshToken error: %v", err)
+		c.JSON(http.StatusUnauthorized, entity.ErrorMessage{Message: "invalid refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
 }
@@ -0,0 +1,94 @@
+package hospitalimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const sourceCSV = "csv"
+
+// csvColumns maps the accepted header names to the OSM tags they stand for.
+var csvColumns = map[string]string{
+	"id":            "@id",
+	"amenity":       "amenity",
+	"name":          "name",
+	"address":       "addr:full",
+	"phone":         "phone",
+	"specialties":   "healthcare:speciality",
+	"emergency":     "emergency",
+	"opening_hours": "opening_hours",
+	"timezone":      "timezone",
+	"latitude":      "@lat",
+	"lat":           "@lat",
+	"longitude":     "@lon",
+	"lon":           "@lon",
+	"lng":           "@lon",
+}
+
+// readCSV reads a file with a header row naming the columns in csvColumns.
+// amenity defaults to hospital and specialties are separated by ";".
+func readCSV(path string, opts Options, result *Result) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open csv: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("read csv header: %w", err)
+	}
+
+	// spreadsheet exports often start with a byte order mark
+	keys := make([]string, len(header))
+	for i, name := range header {
+		keys[i] = csvColumns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))]
+	}
+	for _, required := range []string{"name", "@lat", "@lon"} {
+		if !slices.Contains(keys, required) {
+			return errors.New("read csv header: name, latitude and longitude columns are required")
+		}
+	}
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read csv: %w", err)
+		}
+
+		tags := map[string]string{"amenity": "hospital"}
+		for i, value := range record {
+			if i < len(keys) && keys[i] != "" && strings.TrimSpace(value) != "" {
+				tags[keys[i]] = value
+			}
+		}
+
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(tags["@lat"]), 64)
+		lon, lonErr := strconv.ParseFloat(strings.TrimSpace(tags["@lon"]), 64)
+		if latErr != nil || lonErr != nil {
+			result.skip(SkipInvalidLocation)
+			continue
+		}
+
+		var externalID *string
+		if id := strings.TrimSpace(tags["@id"]); id != "" {
+			externalID = &id
+		}
+		result.add(tags, lat, lon, sourceCSV, externalID, opts.Timezone)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package hospitalimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+const sourceGeoJSON = "geojson"
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	ID         any            `json:"id"`
+	Geometry   *geometry      `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// osmID matches the ids of features exported from OSM, e.g. by overpass-turbo.
+var osmID = regexp.MustCompile(`^(node|way|relation)/\d+$`)
+
+// readGeoJSON reads a FeatureCollection whose properties are OSM style tags.
+// Polygons are placed at the centroid of their outer ring.
+func readGeoJSON(path string, opts Options, result *Result) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("open geojson: %w", err)
+	}
+
+	var fc featureCollection
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return fmt.Errorf("decode geojson: %w", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return fmt.Errorf("decode geojson: expected a FeatureCollection, got %q", fc.Type)
+	}
+
+	for _, f := range fc.Features {
+		if f.Geometry == nil {
+			result.skip(SkipInvalidLocation)
+			continue
+		}
+		lat, lon, ok := f.Geometry.center()
+		if !ok {
+			result.skip(SkipUnsupportedShape)
+			continue
+		}
+
+		tags := make(map[string]string, len(f.Properties))
+		for k, v := range f.Properties {
+			if s := propertyString(v); s != "" {
+				tags[k] = s
+			}
+		}
+
+		id := tags["@id"]
+		if id == "" {
+			id = propertyString(f.ID)
+		}
+		source := sourceGeoJSON
+		if osmID.MatchString(id) {
+			source = sourceOSM
+		}
+
+		var externalID *string
+		if id != "" {
+			externalID = &id
+		}
+		result.add(tags, lat, lon, source, externalID, opts.Timezone)
+	}
+
+	return nil
+}
+
+// propertyString flattens scalar property values; nested objects are ignored.
+func propertyString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	default:
+		return ""
+	}
+}
+
+func (g *geometry) center() (float64, float64, bool) {
+	var ring [][]float64
+	switch g.Type {
+	case "Point":
+		var p []float64
+		if err := json.Unmarshal(g.Coordinates, &p); err != nil || len(p) < 2 {
+			return 0, 0, false
+		}
+		return p[1], p[0], true
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &rings); err != nil || len(rings) == 0 {
+			return 0, 0, false
+		}
+		ring = rings[0]
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygons); err != nil || len(polygons) == 0 || len(polygons[0]) == 0 {
+			return 0, 0, false
+		}
+		ring = polygons[0][0]
+	default:
+		return 0, 0, false
+	}
+
+	// the last position of a ring repeats the first
+	if len(ring) > 1 {
+		ring = ring[:len(ring)-1]
+	}
+
+	var lat, lon float64
+	var count int
+	for _, p := range ring {
+		if len(p) < 2 {
+			continue
+		}
+		lon += p[0]
+		lat += p[1]
+		count++
+	}
+	if count == 0 {
+		return 0, 0, false
+	}
+	return lat / float64(count), lon / float64(count), true
+}
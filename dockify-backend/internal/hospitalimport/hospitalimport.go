@@ -0,0 +1,224 @@
+// Package hospitalimport reads hospital directory entries from OpenStreetMap
+// extracts (PBF or XML), GeoJSON FeatureCollections and CSV files and
+// normalizes them into models.Hospital records ready to be upserted.
+package hospitalimport
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+const (
+	FormatPBF     = "pbf"
+	FormatOSM     = "osm"
+	FormatGeoJSON = "geojson"
+	FormatCSV     = "csv"
+
+	sourceOSM = "osm"
+)
+
+// Amenities are the OSM amenity values that are imported.
+var Amenities = []string{"hospital", "clinic", "doctors", "pharmacy"}
+
+// Skip reasons reported for records that are not imported.
+const (
+	SkipUnsupportedAmenity = "unsupported amenity"
+	SkipMissingName        = "missing name"
+	SkipInvalidLocation    = "invalid location"
+	SkipUnsupportedShape   = "unsupported geometry"
+	SkipRelation           = "relation not supported"
+)
+
+type Options struct {
+	// Format is one of the Format constants; it is detected from the file
+	// extension when empty.
+	Format string
+	// Timezone is stored for entries that do not carry one and is used to
+	// evaluate their opening hours.
+	Timezone string
+}
+
+type Result struct {
+	Hospitals []models.Hospital
+	Skipped   map[string]int
+}
+
+func (r *Result) skip(reason string) {
+	r.Skipped[reason]++
+}
+
+// add normalizes the tags of a feature at (lat, lon) and keeps it when it is
+// a supported, named amenity.
+func (r *Result) add(tags map[string]string, lat, lon float64, source string, externalID *string, timezone string) {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 || (lat == 0 && lon == 0) {
+		r.skip(SkipInvalidLocation)
+		return
+	}
+
+	h, reason := fromTags(tags)
+	if reason != "" {
+		r.skip(reason)
+		return
+	}
+
+	h.Latitude, h.Longitude = lat, lon
+	h.Source, h.ExternalID = source, externalID
+	if h.Timezone == "" {
+		h.Timezone = timezone
+	}
+	r.Hospitals = append(r.Hospitals, h)
+}
+
+// ReadFile reads every supported entry of the file at path.
+func ReadFile(ctx context.Context, path string, opts Options) (Result, error) {
+	format := opts.Format
+	if format == "" {
+		format = detectFormat(path)
+	}
+	if opts.Timezone == "" {
+		opts.Timezone = "UTC"
+	}
+
+	result := Result{Skipped: make(map[string]int)}
+
+	var err error
+	switch format {
+	case FormatPBF, FormatOSM:
+		err = readOSM(ctx, path, format == FormatPBF, opts, &result)
+	case FormatGeoJSON:
+		err = readGeoJSON(path, opts, &result)
+	case FormatCSV:
+		err = readCSV(path, opts, &result)
+	default:
+		return Result{}, fmt.Errorf("unsupported format %q, use one of pbf, osm, geojson or csv", format)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result, nil
+}
+
+func detectFormat(path string) string {
+	name := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(name, ".pbf"):
+		return FormatPBF
+	case strings.HasSuffix(name, ".osm"), strings.HasSuffix(name, ".xml"):
+		return FormatOSM
+	case strings.HasSuffix(name, ".geojson"), strings.HasSuffix(name, ".json"):
+		return FormatGeoJSON
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV
+	default:
+		return strings.TrimPrefix(filepath.Ext(name), ".")
+	}
+}
+
+// fromTags builds a record from OSM style tags and returns a skip reason when
+// the feature is not importable.
+func fromTags(tags map[string]string) (models.Hospital, string) {
+	amenity := strings.ToLower(strings.TrimSpace(tags["amenity"]))
+	if amenity == "" {
+		// healthcare=* is the newer tagging scheme for the same facilities
+		amenity = strings.ToLower(strings.TrimSpace(tags["healthcare"]))
+	}
+	if !slices.Contains(Amenities, amenity) {
+		return models.Hospital{}, SkipUnsupportedAmenity
+	}
+
+	name := ""
+	for _, key := range []string{"name", "name:en", "official_name"} {
+		if name = normalizeText(tags[key]); name != "" {
+			break
+		}
+	}
+	if name == "" {
+		return models.Hospital{}, SkipMissingName
+	}
+
+	return models.Hospital{
+		Amenity:      amenity,
+		Name:         strings.Trim(name, `"«»`),
+		Address:      address(tags),
+		Phone:        phone(tags),
+		Specialties:  specialties(tags["healthcare:speciality"]),
+		Emergency:    isYes(tags["emergency"]),
+		OpeningHours: NormalizeOpeningHours(tags["opening_hours"]),
+		Timezone:     strings.TrimSpace(tags["timezone"]),
+	}, ""
+}
+
+func address(tags map[string]string) string {
+	if full := normalizeText(tags["addr:full"]); full != "" {
+		return full
+	}
+
+	street := normalizeText(tags["addr:street"] + " " + tags["addr:housenumber"])
+	var parts []string
+	for _, part := range []string{street, tags["addr:city"], tags["addr:postcode"]} {
+		if part = normalizeText(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// phone keeps the first number when several are listed.
+func phone(tags map[string]string) string {
+	for _, key := range []string{"phone", "contact:phone"} {
+		if value, _, _ := strings.Cut(tags[key], ";"); normalizeText(value) != "" {
+			return normalizeText(value)
+		}
+	}
+	return ""
+}
+
+func specialties(value string) []string {
+	result := []string{}
+	for _, s := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == '|' }) {
+		s = strings.ToLower(normalizeText(s))
+		if s != "" && !slices.Contains(result, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func isYes(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "true", "1":
+		return true
+	}
+	return false
+}
+
+func normalizeText(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+var (
+	singleDigitHour = regexp.MustCompile(`\b(\d):(\d\d)\b`)
+	closedKeyword   = regexp.MustCompile(`(?i)\b(off|closed)\b`)
+	dashes          = strings.NewReplacer("–", "-", "—", "-", " - ", "-")
+)
+
+// NormalizeOpeningHours cleans up common deviations from the opening_hours
+// syntax: typographic dashes, single digit hours, extra spaces and a trailing
+// separator.
+func NormalizeOpeningHours(value string) string {
+	value = normalizeText(dashes.Replace(value))
+	value = singleDigitHour.ReplaceAllString(value, "0$1:$2")
+	value = strings.TrimRight(value, "; ")
+
+	if strings.EqualFold(value, "24/7") {
+		return "24/7"
+	}
+	return closedKeyword.ReplaceAllStringFunc(value, strings.ToLower)
+}
@@ -0,0 +1,146 @@
+package hospitalimport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/paulmach/osm"
+	"github.com/paulmach/osm/osmpbf"
+	"github.com/paulmach/osm/osmxml"
+)
+
+type osmScanner interface {
+	Scan() bool
+	Object() osm.Object
+	Err() error
+	Close() error
+}
+
+type osmWay struct {
+	id    osm.WayID
+	tags  map[string]string
+	nodes []osm.NodeID
+}
+
+// readOSM makes two passes over the extract: the first collects matching ways
+// and the nodes they reference, the second matching nodes and the coordinates
+// of those referenced nodes. A way is placed at the centroid of its nodes.
+func readOSM(ctx context.Context, path string, pbf bool, opts Options, result *Result) error {
+	var ways []osmWay
+	needed := make(map[osm.NodeID]*[2]float64)
+
+	err := scanOSM(ctx, path, pbf, true, func(o osm.Object) {
+		switch o := o.(type) {
+		case *osm.Way:
+			if !isMedical(o.Tags) {
+				return
+			}
+			w := osmWay{id: o.ID, tags: o.Tags.Map()}
+			for _, n := range o.Nodes {
+				w.nodes = append(w.nodes, n.ID)
+				needed[n.ID] = nil
+			}
+			ways = append(ways, w)
+		case *osm.Relation:
+			if isMedical(o.Tags) {
+				result.skip(SkipRelation)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = scanOSM(ctx, path, pbf, false, func(o osm.Object) {
+		n, ok := o.(*osm.Node)
+		if !ok {
+			return
+		}
+		if _, ok := needed[n.ID]; ok {
+			needed[n.ID] = &[2]float64{n.Lat, n.Lon}
+		}
+		if isMedical(n.Tags) {
+			id := fmt.Sprintf("node/%d", n.ID)
+			result.add(n.Tags.Map(), n.Lat, n.Lon, sourceOSM, &id, opts.Timezone)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, w := range ways {
+		id := fmt.Sprintf("way/%d", w.id)
+		lat, lon, ok := wayCentroid(w.nodes, needed)
+		if !ok {
+			result.skip(SkipInvalidLocation)
+			continue
+		}
+		result.add(w.tags, lat, lon, sourceOSM, &id, opts.Timezone)
+	}
+
+	return nil
+}
+
+// scanOSM calls fn for every object of the extract; the first pass reads ways
+// and relations, the second nodes.
+func scanOSM(ctx context.Context, path string, pbf, firstPass bool, fn func(osm.Object)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open osm extract: %w", err)
+	}
+	defer f.Close()
+
+	var scanner osmScanner
+	if pbf {
+		s := osmpbf.New(ctx, f, runtime.GOMAXPROCS(0))
+		s.SkipNodes = firstPass
+		s.SkipWays = !firstPass
+		s.SkipRelations = !firstPass
+		scanner = s
+	} else {
+		scanner = osmxml.New(ctx, f)
+	}
+	defer scanner.Close()
+
+	for scanner.Scan() {
+		fn(scanner.Object())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read osm extract: %w", err)
+	}
+	return nil
+}
+
+func isMedical(tags osm.Tags) bool {
+	amenity := tags.Find("amenity")
+	if amenity == "" {
+		amenity = tags.Find("healthcare")
+	}
+	return slices.Contains(Amenities, strings.ToLower(amenity))
+}
+
+// wayCentroid averages the coordinates of the way's nodes, counting the
+// closing node of an area only once.
+func wayCentroid(nodes []osm.NodeID, coords map[osm.NodeID]*[2]float64) (float64, float64, bool) {
+	if len(nodes) > 1 && nodes[0] == nodes[len(nodes)-1] {
+		nodes = nodes[:len(nodes)-1]
+	}
+
+	var lat, lon float64
+	var count int
+	for _, id := range nodes {
+		if c := coords[id]; c != nil {
+			lat += c[0]
+			lon += c[1]
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, 0, false
+	}
+	return lat / float64(count), lon / float64(count), true
+}
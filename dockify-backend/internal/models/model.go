@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -13,17 +14,209 @@ type User struct {
 	LastName     string     `json:"last_name"`
 	Email        string     `json:"email"`
 	PasswordHash string     `json:"-"`
+	BirthDate    *time.Time `json:"birth_date"`
 	CreatedAt    *time.Time `json:"created_at"`
+
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`     // null until the current email is verified
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"` // set while a deleted account can still be restored
 }
 
 type HealthMetrics struct {
 	ID          int        `json:"id"`
 	UserId      int        `json:"user_id"`
 	MetricType  string     `json:"metric_type"`
-	MetricValue string     `json:"metric_value"`
+	MetricValue float64    `json:"metric_value"`
+	Source      string     `json:"source"`
+	ExternalID  *string    `json:"external_id"`
 	RecordedAt  *time.Time `json:"recorded_at"`
 }
 
+// UpsertResult reports the id of an upserted row and whether it was newly inserted.
+type UpsertResult struct {
+	ID       int
+	Inserted bool
+}
+
+// HealthMetricsFilter narrows a health metrics query. AfterRecordedAt/AfterID
+// hold the keyset position of the last row of the previous page.
+type HealthMetricsFilter struct {
+	UserId          int
+	MetricTypes     []string
+	From            *time.Time
+	To              *time.Time
+	Descending      bool
+	AfterRecordedAt *time.Time
+	AfterID         int
+	Limit           int
+}
+
+// MetricAggregateFilter describes a bucketed aggregation. Unit is a
+// date_trunc field and Timezone an IANA name used for bucket boundaries.
+type MetricAggregateFilter struct {
+	UserId     int
+	MetricType string
+	Unit       string
+	Timezone   string
+	Functions  []string
+	From       time.Time
+	To         time.Time
+}
+
+type MetricAggregate struct {
+	Bucket time.Time
+	Values map[string]*float64
+}
+
+// TimeRangeFilter selects a user's rows whose timestamp is in [From, To).
+type TimeRangeFilter struct {
+	UserId int
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+}
+
+type BloodPressureReading struct {
+	ID         int        `json:"id"`
+	UserId     int        `json:"user_id"`
+	Systolic   int        `json:"systolic"`
+	Diastolic  int        `json:"diastolic"`
+	Pulse      *int       `json:"pulse"`
+	Source     string     `json:"source"`
+	RecordedAt *time.Time `json:"recorded_at"`
+}
+
+type Workout struct {
+	ID              int        `json:"id"`
+	UserId          int        `json:"user_id"`
+	WorkoutType     string     `json:"workout_type"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         time.Time  `json:"ended_at"`
+	DurationMinutes float64    `json:"duration_minutes"`
+	CaloriesBurned  *float64   `json:"calories_burned"`
+	AvgBpm          *int       `json:"avg_bpm"`
+	MaxBpm          *int       `json:"max_bpm"`
+	Source          string     `json:"source"`
+	CreatedAt       *time.Time `json:"created_at"`
+}
+
+type SleepStage struct {
+	Stage     string    `json:"stage"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+}
+
+type SleepSession struct {
+	ID               int          `json:"id"`
+	UserId           int          `json:"user_id"`
+	StartedAt        time.Time    `json:"started_at"`
+	EndedAt          time.Time    `json:"ended_at"`
+	Stages           []SleepStage `json:"stages"`
+	AvgHeartRate     *int         `json:"avg_heart_rate"`
+	MovementsPerHour *float64     `json:"movements_per_hour"`
+	SnoreMinutes     *int         `json:"snore_minutes"`
+	Notes            []string     `json:"notes"`
+	Source           string       `json:"source"`
+	CreatedAt        *time.Time   `json:"created_at"`
+
+	// derived from the session bounds and stages, not stored
+	TimeInBedHours     float64 `json:"time_in_bed_hours"`
+	SleepDurationHours float64 `json:"sleep_duration_hours"`
+	SleepEfficiency    float64 `json:"sleep_efficiency"`
+}
+
+// Prediction is a stored MindSpore result. Input and Output hold the exact
+// request sent to and response returned by the model.
+type Prediction struct {
+	ID           int             `json:"id"`
+	UserId       int             `json:"user_id"`
+	Kind         string          `json:"kind"`
+	ModelVersion string          `json:"model_version"`
+	Input        json.RawMessage `json:"input" swaggertype:"object"`
+	Output       json.RawMessage `json:"output" swaggertype:"object"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type PredictionFilter struct {
+	UserId int
+	Kind   string
+	Limit  int
+}
+
+// Recommendation is produced by a rule of the recommendation engine. At most
+// one open (neither dismissed nor resolved) recommendation exists per rule.
+type Recommendation struct {
+	ID            int            `json:"id"`
+	UserId        int            `json:"user_id"`
+	Rule          string         `json:"rule"`
+	Category      string         `json:"category"`
+	Priority      int            `json:"priority"`
+	Title         string         `json:"title"`
+	Message       string         `json:"message"`
+	Reason        string         `json:"reason"`
+	SourceMetrics map[string]any `json:"source_metrics"`
+	ReadAt        *time.Time     `json:"read_at"`
+	DismissedAt   *time.Time     `json:"dismissed_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// RecommendationFilter pages through open recommendations ordered by priority.
+// AfterPriority/AfterID hold the keyset of the last row of the previous page.
+type RecommendationFilter struct {
+	UserId        int
+	AfterPriority *int
+	AfterID       int
+	Limit         int
+}
+
+// AlertRule fires when readings of MetricType compare to Threshold for at
+// least DurationMinutes, at most once per CooldownMinutes.
+type AlertRule struct {
+	ID              int            `json:"id"`
+	UserId          int            `json:"user_id"`
+	Name            string         `json:"name"`
+	MetricType      string         `json:"metric_type"`
+	Comparator      string         `json:"comparator"` // gt, gte, lt or lte
+	Threshold       float64        `json:"threshold"`
+	DurationMinutes int            `json:"duration_minutes"`
+	CooldownMinutes int            `json:"cooldown_minutes"`
+	Channels        []AlertChannel `json:"channels"`
+	Enabled         bool           `json:"enabled"`
+	LastFiredAt     *time.Time     `json:"last_fired_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// AlertChannel is where alerts of a rule are sent besides the app, e.g. a
+// webhook or the user's own verified email address.
+type AlertChannel struct {
+	Type   string `json:"type"` // webhook, email or log
+	Target string `json:"target"`
+}
+
+// Alert is a fired alert rule. The rule fields are copied so the alert still
+// reads correctly after the rule changed.
+type Alert struct {
+	ID         int             `json:"id"`
+	RuleId     *int            `json:"rule_id"` // null once the rule was deleted
+	UserId     int             `json:"user_id"`
+	RuleName   string          `json:"rule_name"`
+	MetricType string          `json:"metric_type"`
+	Comparator string          `json:"comparator"`
+	Threshold  float64         `json:"threshold"`
+	Value      float64         `json:"value"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	Deliveries []AlertDelivery `json:"deliveries"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AlertDelivery struct {
+	Channel string `json:"channel"` // app or the channel type
+	Target  string `json:"target,omitempty"`
+	Status  string `json:"status"` // sent or failed
+	Error   string `json:"error,omitempty"`
+}
+
 type Location struct {
 	ID         int             `json:"id"`
 	UserId     int             `json:"user_id"`
@@ -31,3 +224,171 @@ type Location struct {
 	Longitude  decimal.Decimal `json:"longitude"`
 	RecordedAt *time.Time      `json:"recorded_at"`
 }
+
+type NearestUsersFilter struct {
+	Latitude  float64
+	Longitude float64
+	Radius    int           // metres
+	MaxAge    time.Duration // locations recorded earlier are ignored; zero keeps all
+}
+
+// TrackFilter selects the user's locations in [From, To) for an export.
+// HeartRateWindow > 0 attaches the heart rate measured closest to each
+// location within that window.
+type TrackFilter struct {
+	UserId          int
+	From            time.Time
+	To              time.Time
+	HeartRateWindow time.Duration
+}
+
+type TrackPoint struct {
+	Latitude   float64
+	Longitude  float64
+	RecordedAt time.Time
+	HeartRate  *float64
+}
+
+type Friendship struct {
+	RequesterId int        `json:"requester_id"`
+	AddresseeId int        `json:"addressee_id"`
+	Status      string     `json:"status"` // pending or accepted
+	CreatedAt   time.Time  `json:"created_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+}
+
+type UserBlock struct {
+	BlockerId int       `json:"blocker_id"`
+	BlockedId int       `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Incident is an SOS raised by a user at a location.
+type Incident struct {
+	ID         int        `json:"id"`
+	UserId     int        `json:"user_id"`
+	Latitude   float64    `json:"latitude"`
+	Longitude  float64    `json:"longitude"`
+	Message    string     `json:"message"`
+	Status     string     `json:"status"` // open or resolved
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// IncidentResponder is a user alerted about an incident.
+type IncidentResponder struct {
+	IncidentId     int        `json:"incident_id"`
+	UserId         int        `json:"user_id"`
+	Distance       float64    `json:"distance"` // metres from the incident when it was raised
+	NotifiedAt     *time.Time `json:"notified_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+}
+
+// IncidentEvent is an entry of an incident's timeline. UserId is the user the
+// event is about, if any.
+type IncidentEvent struct {
+	ID         int       `json:"id"`
+	IncidentId int       `json:"incident_id"`
+	UserId     *int      `json:"user_id"`
+	Event      string    `json:"event"`
+	Details    string    `json:"details"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CareInvitation offers the invitee read access to the owner's data. It is
+// addressed by email so people without an account yet can be invited.
+type CareInvitation struct {
+	ID          int        `json:"id"`
+	OwnerId     int        `json:"owner_id"`
+	Email       string     `json:"email"`
+	MetricTypes []string   `json:"metric_types"`
+	Location    bool       `json:"location"`
+	Status      string     `json:"status"` // pending, accepted, declined, cancelled or expired
+	GranteeId   *int       `json:"grantee_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at"`
+}
+
+// Grant gives GranteeId read access to the listed metric types and, with
+// Location set, the location history of OwnerId.
+type Grant struct {
+	ID          int        `json:"id"`
+	OwnerId     int        `json:"owner_id"`
+	GranteeId   int        `json:"grantee_id"`
+	MetricTypes []string   `json:"metric_types"`
+	Location    bool       `json:"location"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
+
+type GrantAccess struct {
+	ID        int       `json:"id"`
+	GrantId   int       `json:"grant_id"`
+	OwnerId   int       `json:"owner_id"`
+	GranteeId int       `json:"grantee_id"`
+	Resource  string    `json:"resource"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Hospital struct {
+	ID           int      `json:"id"`
+	Amenity      string   `json:"amenity"` // hospital, clinic, doctors or pharmacy
+	Name         string   `json:"name"`
+	Address      string   `json:"address"`
+	Phone        string   `json:"phone"`
+	Specialties  []string `json:"specialties"`
+	Emergency    bool     `json:"emergency"`
+	OpeningHours string   `json:"opening_hours"`
+	Timezone     string   `json:"timezone"`
+	Latitude     float64  `json:"latitude"`
+	Longitude    float64  `json:"longitude"`
+	Source       string   `json:"source"`
+	ExternalID   *string  `json:"external_id"` // id in the source, e.g. node/123 for OpenStreetMap
+	Distance     float64  `json:"distance"`    // metres from the search point, set by searches
+}
+
+type HospitalFilter struct {
+	Latitude      float64
+	Longitude     float64
+	Radius        int
+	Amenity       string
+	Specialty     string
+	EmergencyOnly bool
+}
+
+// PasswordResetToken is a single use token for resetting a forgotten
+// password. Only the hash of the token is stored.
+type PasswordResetToken struct {
+	ID        int        `json:"id"`
+	UserId    int        `json:"user_id"`
+	TokenHash string     `json:"-"`
+	IPAddress string     `json:"ip_address"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+// MFA is the TOTP second factor of a user. Secret is encrypted; the factor
+// is only checked at login once EnabledAt is set.
+type MFA struct {
+	UserId       int        `json:"user_id"`
+	Secret       string     `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	LastUsedStep int64      `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type Session struct {
+	ID               int        `json:"id"`
+	UserId           int        `json:"user_id"`
+	RefreshTokenHash string     `json:"-"`
+	DeviceName       string     `json:"device_name"`
+	Platform         string     `json:"platform"`
+	IPAddress        string     `json:"ip_address"`
+	CreatedAt        *time.Time `json:"created_at"`
+	LastSeenAt       *time.Time `json:"last_seen_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+}
@@ -0,0 +1,25 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/pkg/mailer"
+)
+
+type emailNotifier struct {
+	mailer mailer.Sender
+}
+
+// NewEmailNotifier returns a notifier that emails each message to its To
+// address.
+func NewEmailNotifier(m mailer.Sender) Notifier {
+	return &emailNotifier{mailer: m}
+}
+
+func (e *emailNotifier) Notify(ctx context.Context, msg Message) error {
+	if err := e.mailer.Send(ctx, msg.To, msg.Subject, msg.Body); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/askaroe/dockify-backend/pkg/utils"
+)
+
+const (
+	ChannelLog     = "log"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+// ErrUnsupportedChannel is returned for messages on a channel that is not
+// configured.
+var ErrUnsupportedChannel = errors.New("unsupported notification channel")
+
+// Message is a notification for a single user. Data carries machine readable
+// fields, e.g. the incident id, for clients that act on the notification.
+// Channel and To address the message to a channel outside the app, e.g. an
+// email address; an empty Channel delivers to the user in the app.
+type Message struct {
+	UserID  int
+	Subject string
+	Body    string
+	Data    map[string]string
+	Channel string
+	To      string
+}
+
+// Notifier delivers messages to users. Implementations must be safe for
+// concurrent use.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+type logNotifier struct {
+	logger *utils.Logger
+}
+
+// NewLogNotifier returns a notifier that only writes messages to the log, for
+// deployments without a delivery channel.
+func NewLogNotifier(logger *utils.Logger) Notifier {
+	return &logNotifier{logger: logger}
+}
+
+func (l *logNotifier) Notify(_ context.Context, msg Message) error {
+	l.logger.WithField("user_id", msg.UserID).WithField("data", msg.Data).Infof("notification: %s: %s", msg.Subject, msg.Body)
+	return nil
+}
+
+type dispatcher struct {
+	app      Notifier
+	channels map[string]Notifier
+}
+
+// NewDispatcher returns a notifier that delivers messages without a channel
+// through app and the others through the notifier of their channel.
+func NewDispatcher(app Notifier, channels map[string]Notifier) Notifier {
+	return &dispatcher{app: app, channels: channels}
+}
+
+func (d *dispatcher) Notify(ctx context.Context, msg Message) error {
+	if msg.Channel == "" {
+		return d.app.Notify(ctx, msg)
+	}
+	n, ok := d.channels[msg.Channel]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedChannel, msg.Channel)
+	}
+	return n.Notify(ctx, msg)
+}
+
+// Memory keeps delivered messages in process so they can be inspected, e.g.
+// by tests.
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (m *Memory) Notify(_ context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages returns a copy of the messages delivered so far.
+func (m *Memory) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/askaroe/dockify-backend/config"
+)
+
+const (
+	defaultWebhookTimeout = 10 * time.Second
+
+	// SignatureHeader carries "sha256=" and the hex HMAC of the request body
+	// when a webhook secret is configured.
+	SignatureHeader = "X-Dockify-Signature"
+)
+
+var errPrivateAddress = errors.New("webhook address is not public")
+
+type webhookNotifier struct {
+	client *http.Client
+	secret []byte
+}
+
+// NewWebhookNotifier returns a notifier that POSTs each message as JSON to its
+// To URL. Webhook URLs are chosen by users, so connections to loopback,
+// private and link-local addresses are refused.
+func NewWebhookNotifier(cfg config.WebhookConfig) Notifier {
+	timeout := time.Duration(cfg.WebhookTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: timeout, Control: publicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &webhookNotifier{
+		client: &http.Client{Timeout: timeout, Transport: transport},
+		secret: []byte(cfg.WebhookSecret),
+	}
+}
+
+type webhookPayload struct {
+	UserID  int               `json:"user_id"`
+	Subject string            `json:"subject"`
+	Body    string            `json:"body"`
+	Data    map[string]string `json:"data,omitempty"`
+	SentAt  time.Time         `json:"sent_at"`
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, msg Message) error {
+	if err := ValidateWebhookURL(msg.To); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		UserID:  msg.UserID,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+		Data:    msg.Data,
+		SentAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.To, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.secret) > 0 {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ValidateWebhookURL checks that rawURL is an absolute http or https URL.
+func ValidateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook url must be an absolute http or https url")
+	}
+	return nil
+}
+
+// publicOnly refuses connections to addresses that are not publicly routable.
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	addr := addrPort.Addr().Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsUnspecified() || addr.IsMulticast() || addr.IsInterfaceLocalMulticast() {
+		return fmt.Errorf("%w: %s", errPrivateAddress, addr)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package care
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/pkg/psql"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	InvitationPending   = "pending"
+	InvitationAccepted  = "accepted"
+	InvitationDeclined  = "declined"
+	InvitationCancelled = "cancelled"
+
+	ResourceMetrics          = "metrics"
+	ResourceMetricsAggregate = "metrics_aggregate"
+	ResourceLocationHistory  = "location_history"
+)
+
+type Care interface {
+	CreateInvitation(ctx context.Context, invitation models.CareInvitation) (models.CareInvitation, error)
+	GetInvitation(ctx context.Context, id int) (models.CareInvitation, error)
+	GetInvitations(ctx context.Context, userID int, email string, limit int) ([]models.CareInvitation, error)
+	HasPendingInvitation(ctx context.Context, ownerID int, email string) (bool, error)
+	RespondInvitation(ctx context.Context, id int, status string, granteeID *int) (models.CareInvitation, error)
+	AcceptInvitation(ctx context.Context, id, granteeID int) (models.CareInvitation, error)
+
+	UpsertGrant(ctx context.Context, grant models.Grant) (models.Grant, error)
+	GetGrant(ctx context.Context, id int) (models.Grant, error)
+	GetActiveGrant(ctx context.Context, ownerID, granteeID int) (models.Grant, error)
+	GetGrants(ctx context.Context, userID int) ([]models.Grant, error)
+	UpdateGrant(ctx context.Context, grant models.Grant) (models.Grant, error)
+	RevokeGrant(ctx context.Context, id int) error
+
+	LogGrantAccess(ctx context.Context, access models.GrantAccess) error
+	GetGrantAccessLog(ctx context.Context, userID, limit int) ([]models.GrantAccess, error)
+}
+
+type care struct {
+	db psql.DB
+}
+
+func NewCareRepository(db psql.DB) Care {
+	return &care{db: db}
+}
+
+const invitationColumns = `id, owner_id, email, metric_types, location, status, grantee_id, created_at, expires_at, responded_at`
+
+func scanInvitation(row pgx.Row) (models.CareInvitation, error) {
+	var i models.CareInvitation
+	err := row.Scan(&i.ID, &i.OwnerId, &i.Email, &i.MetricTypes, &i.Location, &i.Status, &i.GranteeId,
+		&i.CreatedAt, &i.ExpiresAt, &i.RespondedAt)
+	return i, err
+}
+
+func (c *care) CreateInvitation(ctx context.Context, invitation models.CareInvitation) (models.CareInvitation, error) {
+	query := `INSERT INTO care_invitations (owner_id, email, metric_types, location, status, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + invitationColumns
+	created, err := scanInvitation(c.db.QueryRow(ctx, query, invitation.OwnerId, invitation.Email,
+		invitation.MetricTypes, invitation.Location, InvitationPending, invitation.ExpiresAt))
+	if err != nil {
+		return models.CareInvitation{}, fmt.Errorf("create invitation: %w", err)
+	}
+	return created, nil
+}
+
+func (c *care) GetInvitation(ctx context.Context, id int) (models.CareInvitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM care_invitations WHERE id = $1`
+	invitation, err := scanInvitation(c.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return models.CareInvitation{}, fmt.Errorf("get invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// GetInvitations returns the invitations the user sent or that were sent to
+// email, newest first.
+func (c *care) GetInvitations(ctx context.Context, userID int, email string, limit int) ([]models.CareInvitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM care_invitations
+	WHERE owner_id = $1 OR LOWER(email) = LOWER($2)
+	ORDER BY created_at DESC, id DESC
+	LIMIT $3`
+
+	rows, err := c.db.Query(ctx, query, userID, email, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []models.CareInvitation
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan invitation: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+func (c *care) HasPendingInvitation(ctx context.Context, ownerID int, email string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM care_invitations
+	WHERE owner_id = $1 AND LOWER(email) = LOWER($2) AND status = $3 AND expires_at > NOW())`
+	var exists bool
+	if err := c.db.QueryRow(ctx, query, ownerID, email, InvitationPending).Scan(&exists); err != nil {
+		return false, fmt.Errorf("has pending invitation: %w", err)
+	}
+	return exists, nil
+}
+
+// RespondInvitation moves a pending invitation to status. It returns
+// pgx.ErrNoRows when the invitation is no longer pending, so of two concurrent
+// responses only one wins.
+func (c *care) RespondInvitation(ctx context.Context, id int, status string, granteeID *int) (models.CareInvitation, error) {
+	query := `UPDATE care_invitations SET status = $2, grantee_id = $3, responded_at = NOW()
+	WHERE id = $1 AND status = $4
+	RETURNING ` + invitationColumns
+	invitation, err := scanInvitation(c.db.QueryRow(ctx, query, id, status, granteeID, InvitationPending))
+	if err != nil {
+		return models.CareInvitation{}, fmt.Errorf("respond invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation marks a pending invitation accepted by granteeID, but only
+// while the grantee's verified account email is the invited one. It returns
+// pgx.ErrNoRows otherwise.
+func (c *care) AcceptInvitation(ctx context.Context, id, granteeID int) (models.CareInvitation, error) {
+	query := `UPDATE care_invitations SET status = $2, grantee_id = $3, responded_at = NOW()
+	WHERE id = $1 AND status = $4
+	  AND EXISTS (
+	    SELECT 1 FROM users
+	    WHERE users.id = $3 AND LOWER(users.email) = LOWER(care_invitations.email) AND users.email_verified_at IS NOT NULL
+	  )
+	RETURNING ` + invitationColumns
+	invitation, err := scanInvitation(c.db.QueryRow(ctx, query, id, InvitationAccepted, granteeID, InvitationPending))
+	if err != nil {
+		return models.CareInvitation{}, fmt.Errorf("accept invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+const grantColumns = `id, owner_id, grantee_id, metric_types, location, created_at, updated_at, revoked_at`
+
+func scanGrant(row pgx.Row) (models.Grant, error) {
+	var g models.Grant
+	err := row.Scan(&g.ID, &g.OwnerId, &g.GranteeId, &g.MetricTypes, &g.Location, &g.CreatedAt, &g.UpdatedAt, &g.RevokedAt)
+	return g, err
+}
+
+// UpsertGrant creates the grant or, when the pair already has an active
+// grant, replaces its scope.
+func (c *care) UpsertGrant(ctx context.Context, grant models.Grant) (models.Grant, error) {
+	query := `INSERT INTO grants (owner_id, grantee_id, metric_types, location) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (owner_id, grantee_id) WHERE revoked_at IS NULL DO UPDATE
+	SET metric_types = EXCLUDED.metric_types, location = EXCLUDED.location, updated_at = NOW()
+	RETURNING ` + grantColumns
+	upserted, err := scanGrant(c.db.QueryRow(ctx, query, grant.OwnerId, grant.GranteeId, grant.MetricTypes, grant.Location))
+	if err != nil {
+		return models.Grant{}, fmt.Errorf("upsert grant: %w", err)
+	}
+	return upserted, nil
+}
+
+func (c *care) GetGrant(ctx context.Context, id int) (models.Grant, error) {
+	query := `SELECT ` + grantColumns + ` FROM grants WHERE id = $1`
+	grant, err := scanGrant(c.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return models.Grant{}, fmt.Errorf("get grant: %w", err)
+	}
+	return grant, nil
+}
+
+// GetActiveGrant returns the unrevoked grant from ownerID to granteeID. A
+// grant of an owner whose account is scheduled for deletion is not active.
+func (c *care) GetActiveGrant(ctx context.Context, ownerID, granteeID int) (models.Grant, error) {
+	query := `SELECT ` + grantColumns + ` FROM grants
+	WHERE owner_id = $1 AND grantee_id = $2 AND revoked_at IS NULL
+	  AND owner_id IN (SELECT id FROM users WHERE deletion_scheduled_at IS NULL)`
+	grant, err := scanGrant(c.db.QueryRow(ctx, query, ownerID, granteeID))
+	if err != nil {
+		return models.Grant{}, fmt.Errorf("get active grant: %w", err)
+	}
+	return grant, nil
+}
+
+// GetGrants returns the active grants the user gave or received, newest first.
+func (c *care) GetGrants(ctx context.Context, userID int) ([]models.Grant, error) {
+	query := `SELECT ` + grantColumns + ` FROM grants
+	WHERE (owner_id = $1 OR grantee_id = $1) AND revoked_at IS NULL
+	ORDER BY created_at DESC, id DESC`
+
+	rows, err := c.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []models.Grant
+	for rows.Next() {
+		grant, err := scanGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan grant: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
+
+func (c *care) UpdateGrant(ctx context.Context, grant models.Grant) (models.Grant, error) {
+	query := `UPDATE grants SET metric_types = $2, location = $3, updated_at = NOW()
+	WHERE id = $1 AND revoked_at IS NULL
+	RETURNING ` + grantColumns
+	updated, err := scanGrant(c.db.QueryRow(ctx, query, grant.ID, grant.MetricTypes, grant.Location))
+	if err != nil {
+		return models.Grant{}, fmt.Errorf("update grant: %w", err)
+	}
+	return updated, nil
+}
+
+func (c *care) RevokeGrant(ctx context.Context, id int) error {
+	query := `UPDATE grants SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL RETURNING id`
+	if err := c.db.QueryRow(ctx, query, id).Scan(&id); err != nil {
+		return fmt.Errorf("revoke grant: %w", err)
+	}
+	return nil
+}
+
+func (c *care) LogGrantAccess(ctx context.Context, access models.GrantAccess) error {
+	query := `INSERT INTO grant_access_log (grant_id, owner_id, grantee_id, resource, details) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := c.db.Exec(ctx, query, access.GrantId, access.OwnerId, access.GranteeId, access.Resource, access.Details); err != nil {
+		return fmt.Errorf("log grant access: %w", err)
+	}
+	return nil
+}
+
+// GetGrantAccessLog returns the accesses to the user's data and the accesses
+// the user made to others' data, newest first.
+func (c *care) GetGrantAccessLog(ctx context.Context, userID, limit int) ([]models.GrantAccess, error) {
+	query := `SELECT id, grant_id, owner_id, grantee_id, resource, details, created_at FROM grant_access_log
+	WHERE owner_id = $1 OR grantee_id = $1
+	ORDER BY created_at DESC, id DESC
+	LIMIT $2`
+
+	rows, err := c.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get grant access log: %w", err)
+	}
+	defer rows.Close()
+
+	var accesses []models.GrantAccess
+	for rows.Next() {
+		var a models.GrantAccess
+		if err := rows.Scan(&a.ID, &a.GrantId, &a.OwnerId, &a.GranteeId, &a.Resource, &a.Details, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan grant access: %w", err)
+		}
+		accesses = append(accesses, a)
+	}
+
+	return accesses, rows.Err()
+}
@@ -0,0 +1,176 @@
+package friend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/pkg/psql"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+)
+
+type Friend interface {
+	GetFriendship(ctx context.Context, userID, otherID int) (models.Friendship, error)
+	CreateFriendRequest(ctx context.Context, requesterID, addresseeID int) error
+	AcceptFriendRequest(ctx context.Context, requesterID, addresseeID int) error
+	DeleteFriendship(ctx context.Context, userID, otherID int) error
+	GetFriendships(ctx context.Context, userID int) ([]models.Friendship, error)
+	GetFriendIDs(ctx context.Context, userID int) ([]int, error)
+
+	BlockUser(ctx context.Context, blockerID, blockedID int) error
+	UnblockUser(ctx context.Context, blockerID, blockedID int) error
+	GetBlocks(ctx context.Context, blockerID int) ([]models.UserBlock, error)
+	GetBlockedUserIDs(ctx context.Context, userID int) ([]int, error)
+}
+
+type friend struct {
+	db psql.DB
+}
+
+func NewFriendRepository(db psql.DB) Friend {
+	return &friend{db: db}
+}
+
+const friendshipColumns = `requester_id, addressee_id, status, created_at, accepted_at`
+
+func scanFriendship(row pgx.Row) (models.Friendship, error) {
+	var f models.Friendship
+	err := row.Scan(&f.RequesterId, &f.AddresseeId, &f.Status, &f.CreatedAt, &f.AcceptedAt)
+	return f, err
+}
+
+// GetFriendship returns the friendship or request between the two users in
+// either direction.
+func (f *friend) GetFriendship(ctx context.Context, userID, otherID int) (models.Friendship, error) {
+	query := `SELECT ` + friendshipColumns + ` FROM friendships
+	WHERE (requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1)`
+	friendship, err := scanFriendship(f.db.QueryRow(ctx, query, userID, otherID))
+	if err != nil {
+		return models.Friendship{}, fmt.Errorf("get friendship: %w", err)
+	}
+	return friendship, nil
+}
+
+func (f *friend) CreateFriendRequest(ctx context.Context, requesterID, addresseeID int) error {
+	query := `INSERT INTO friendships (requester_id, addressee_id) VALUES ($1, $2)`
+	if _, err := f.db.Exec(ctx, query, requesterID, addresseeID); err != nil {
+		return fmt.Errorf("create friend request: %w", err)
+	}
+	return nil
+}
+
+func (f *friend) AcceptFriendRequest(ctx context.Context, requesterID, addresseeID int) error {
+	query := `UPDATE friendships SET status = $3, accepted_at = NOW()
+	WHERE requester_id = $1 AND addressee_id = $2 AND status = $4 RETURNING requester_id`
+	err := f.db.QueryRow(ctx, query, requesterID, addresseeID, StatusAccepted, StatusPending).Scan(&requesterID)
+	if err != nil {
+		return fmt.Errorf("accept friend request: %w", err)
+	}
+	return nil
+}
+
+// DeleteFriendship removes a friendship, or a pending request in either
+// direction.
+func (f *friend) DeleteFriendship(ctx context.Context, userID, otherID int) error {
+	query := `DELETE FROM friendships
+	WHERE (requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1)
+	RETURNING requester_id`
+	if err := f.db.QueryRow(ctx, query, userID, otherID).Scan(&userID); err != nil {
+		return fmt.Errorf("delete friendship: %w", err)
+	}
+	return nil
+}
+
+func (f *friend) GetFriendships(ctx context.Context, userID int) ([]models.Friendship, error) {
+	query := `SELECT ` + friendshipColumns + ` FROM friendships
+	WHERE requester_id = $1 OR addressee_id = $1
+	ORDER BY created_at DESC`
+	rows, err := f.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get friendships: %w", err)
+	}
+	defer rows.Close()
+
+	var friendships []models.Friendship
+	for rows.Next() {
+		friendship, err := scanFriendship(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan friendship: %w", err)
+		}
+		friendships = append(friendships, friendship)
+	}
+	return friendships, rows.Err()
+}
+
+// GetFriendIDs returns the users with an accepted friendship with userID.
+func (f *friend) GetFriendIDs(ctx context.Context, userID int) ([]int, error) {
+	query := `SELECT CASE WHEN requester_id = $1 THEN addressee_id ELSE requester_id END
+	FROM friendships
+	WHERE (requester_id = $1 OR addressee_id = $1) AND status = $2`
+	rows, err := f.db.Query(ctx, query, userID, StatusAccepted)
+	if err != nil {
+		return nil, fmt.Errorf("get friend ids: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("get friend ids: %w", err)
+	}
+	return ids, nil
+}
+
+func (f *friend) BlockUser(ctx context.Context, blockerID, blockedID int) error {
+	query := `INSERT INTO user_blocks (blocker_id, blocked_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := f.db.Exec(ctx, query, blockerID, blockedID); err != nil {
+		return fmt.Errorf("block user: %w", err)
+	}
+	return nil
+}
+
+func (f *friend) UnblockUser(ctx context.Context, blockerID, blockedID int) error {
+	query := `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2 RETURNING blocker_id`
+	if err := f.db.QueryRow(ctx, query, blockerID, blockedID).Scan(&blockerID); err != nil {
+		return fmt.Errorf("unblock user: %w", err)
+	}
+	return nil
+}
+
+func (f *friend) GetBlocks(ctx context.Context, blockerID int) ([]models.UserBlock, error) {
+	query := `SELECT blocker_id, blocked_id, created_at FROM user_blocks WHERE blocker_id = $1 ORDER BY created_at DESC`
+	rows, err := f.db.Query(ctx, query, blockerID)
+	if err != nil {
+		return nil, fmt.Errorf("get blocks: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []models.UserBlock
+	for rows.Next() {
+		var b models.UserBlock
+		if err := rows.Scan(&b.BlockerId, &b.BlockedId, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan block: %w", err)
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+// GetBlockedUserIDs returns the users userID blocked or was blocked by; they
+// do not see each other.
+func (f *friend) GetBlockedUserIDs(ctx context.Context, userID int) ([]int, error) {
+	query := `SELECT blocked_id FROM user_blocks WHERE blocker_id = $1
+	UNION
+	SELECT blocker_id FROM user_blocks WHERE blocked_id = $1`
+	rows, err := f.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get blocked user ids: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("get blocked user ids: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+// aggregateExpressions maps the public aggregate names to SQL. Only names from
+// this map ever reach the query text.
+var aggregateExpressions = map[string]string{
+	"avg":    "AVG(metric_value)",
+	"min":    "MIN(metric_value)",
+	"max":    "MAX(metric_value)",
+	"sum":    "SUM(metric_value)",
+	"count":  "COUNT(*)::DOUBLE PRECISION",
+	"stddev": "STDDEV_SAMP(metric_value)",
+	"p50":    "PERCENTILE_CONT(0.50) WITHIN GROUP (ORDER BY metric_value)",
+	"p75":    "PERCENTILE_CONT(0.75) WITHIN GROUP (ORDER BY metric_value)",
+	"p90":    "PERCENTILE_CONT(0.90) WITHIN GROUP (ORDER BY metric_value)",
+	"p95":    "PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY metric_value)",
+	"p99":    "PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY metric_value)",
+}
+
+var truncUnits = map[string]bool{"hour": true, "day": true, "week": true, "month": true}
+
+// AggregateMetrics groups a metric into buckets. recorded_at is stored as UTC,
+// so it is shifted into the requested timezone before truncation to get local
+// day/week/month boundaries. The returned bucket starts are local wall-clock
+// times (in a UTC time.Time) and must be re-attached to the timezone by the caller.
+func (h *health) AggregateMetrics(ctx context.Context, filter models.MetricAggregateFilter) ([]models.MetricAggregate, error) {
+	if !truncUnits[filter.Unit] {
+		return nil, fmt.Errorf("unsupported bucket unit %q", filter.Unit)
+	}
+
+	columns := make([]string, 0, len(filter.Functions))
+	for _, fn := range filter.Functions {
+		expr, ok := aggregateExpressions[fn]
+		if !ok {
+			return nil, fmt.Errorf("unsupported aggregate %q", fn)
+		}
+		columns = append(columns, expr)
+	}
+
+	query := fmt.Sprintf(`SELECT DATE_TRUNC('%s', (recorded_at AT TIME ZONE 'UTC') AT TIME ZONE $5) AS bucket, %s
+	FROM health_metrics
+	WHERE user_id = $1 AND metric_type = $2 AND recorded_at >= $3 AND recorded_at < $4
+	GROUP BY bucket
+	ORDER BY bucket`, filter.Unit, strings.Join(columns, ", "))
+
+	rows, err := h.db.Query(ctx, query, filter.UserId, filter.MetricType, filter.From, filter.To, filter.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []models.MetricAggregate
+	for rows.Next() {
+		var bucket time.Time
+		values := make([]*float64, len(filter.Functions))
+
+		dest := make([]any, 0, len(values)+1)
+		dest = append(dest, &bucket)
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan aggregate: %w", err)
+		}
+
+		aggregate := models.MetricAggregate{Bucket: bucket, Values: make(map[string]*float64, len(values))}
+		for i, fn := range filter.Functions {
+			aggregate.Values[fn] = values[i]
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+
+	return aggregates, rows.Err()
+}
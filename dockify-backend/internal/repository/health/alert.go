@@ -0,0 +1,207 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	ComparatorGreater        = "gt"
+	ComparatorGreaterOrEqual = "gte"
+	ComparatorLess           = "lt"
+	ComparatorLessOrEqual    = "lte"
+)
+
+var comparatorOperators = map[string]string{
+	ComparatorGreater:        ">",
+	ComparatorGreaterOrEqual: ">=",
+	ComparatorLess:           "<",
+	ComparatorLessOrEqual:    "<=",
+}
+
+type AlertRules interface {
+	CreateAlertRule(ctx context.Context, req models.AlertRule) (models.AlertRule, error)
+	GetAlertRule(ctx context.Context, userID, id int) (models.AlertRule, error)
+	GetAlertRules(ctx context.Context, userID int) ([]models.AlertRule, error)
+	GetEnabledAlertRules(ctx context.Context, userID int, metricTypes []string) ([]models.AlertRule, error)
+	CountAlertRules(ctx context.Context, userID int) (int, error)
+	UpdateAlertRule(ctx context.Context, req models.AlertRule) (models.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, userID, id int) error
+	ClaimAlertRule(ctx context.Context, id int) error
+	GetBreachStart(ctx context.Context, rule models.AlertRule, at, since time.Time) (*time.Time, error)
+
+	CreateAlert(ctx context.Context, req models.Alert) (int, error)
+	SetAlertDeliveries(ctx context.Context, id int, deliveries []models.AlertDelivery) error
+	GetAlerts(ctx context.Context, userID, limit int) ([]models.Alert, error)
+}
+
+const alertRuleColumns = `id, user_id, name, metric_type, comparator, threshold, duration_minutes, cooldown_minutes,
+	channels, enabled, last_fired_at, created_at, updated_at`
+
+func scanAlertRule(row pgx.Row) (models.AlertRule, error) {
+	var r models.AlertRule
+	err := row.Scan(&r.ID, &r.UserId, &r.Name, &r.MetricType, &r.Comparator, &r.Threshold, &r.DurationMinutes,
+		&r.CooldownMinutes, &r.Channels, &r.Enabled, &r.LastFiredAt, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+func (h *health) CreateAlertRule(ctx context.Context, req models.AlertRule) (models.AlertRule, error) {
+	query := `INSERT INTO alert_rules (user_id, name, metric_type, comparator, threshold, duration_minutes, cooldown_minutes, channels, enabled)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING ` + alertRuleColumns
+	rule, err := scanAlertRule(h.db.QueryRow(ctx, query, req.UserId, req.Name, req.MetricType, req.Comparator, req.Threshold,
+		req.DurationMinutes, req.CooldownMinutes, req.Channels, req.Enabled))
+	if err != nil {
+		return models.AlertRule{}, fmt.Errorf("create alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (h *health) GetAlertRule(ctx context.Context, userID, id int) (models.AlertRule, error) {
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE id = $1 AND user_id = $2`
+	rule, err := scanAlertRule(h.db.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		return models.AlertRule{}, fmt.Errorf("get alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (h *health) GetAlertRules(ctx context.Context, userID int) ([]models.AlertRule, error) {
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE user_id = $1 ORDER BY id`
+	return h.queryAlertRules(ctx, query, userID)
+}
+
+// GetEnabledAlertRules returns the user's enabled rules on any of the metric
+// types.
+func (h *health) GetEnabledAlertRules(ctx context.Context, userID int, metricTypes []string) ([]models.AlertRule, error) {
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules
+	WHERE user_id = $1 AND metric_type = ANY($2) AND enabled ORDER BY id`
+	return h.queryAlertRules(ctx, query, userID, metricTypes)
+}
+
+func (h *health) queryAlertRules(ctx context.Context, query string, args ...any) ([]models.AlertRule, error) {
+	rows, err := h.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.AlertRule
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan alert rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (h *health) CountAlertRules(ctx context.Context, userID int) (int, error) {
+	var count int
+	if err := h.db.QueryRow(ctx, `SELECT COUNT(*) FROM alert_rules WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count alert rules: %w", err)
+	}
+	return count, nil
+}
+
+func (h *health) UpdateAlertRule(ctx context.Context, req models.AlertRule) (models.AlertRule, error) {
+	query := `UPDATE alert_rules SET name = $3, metric_type = $4, comparator = $5, threshold = $6, duration_minutes = $7,
+		cooldown_minutes = $8, channels = $9, enabled = $10, updated_at = NOW()
+	WHERE id = $1 AND user_id = $2 RETURNING ` + alertRuleColumns
+	rule, err := scanAlertRule(h.db.QueryRow(ctx, query, req.ID, req.UserId, req.Name, req.MetricType, req.Comparator,
+		req.Threshold, req.DurationMinutes, req.CooldownMinutes, req.Channels, req.Enabled))
+	if err != nil {
+		return models.AlertRule{}, fmt.Errorf("update alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (h *health) DeleteAlertRule(ctx context.Context, userID, id int) error {
+	query := `DELETE FROM alert_rules WHERE id = $1 AND user_id = $2 RETURNING id`
+	if err := h.db.QueryRow(ctx, query, id, userID).Scan(&id); err != nil {
+		return fmt.Errorf("delete alert rule: %w", err)
+	}
+	return nil
+}
+
+// ClaimAlertRule marks the rule as fired unless it is still cooling down from
+// the previous alert, in which case it fails with pgx.ErrNoRows. Concurrent
+// uploads therefore fire a rule at most once.
+func (h *health) ClaimAlertRule(ctx context.Context, id int) error {
+	query := `UPDATE alert_rules SET last_fired_at = NOW()
+	WHERE id = $1 AND (last_fired_at IS NULL OR last_fired_at <= NOW() - make_interval(mins => cooldown_minutes))
+	RETURNING id`
+	if err := h.db.QueryRow(ctx, query, id).Scan(&id); err != nil {
+		return fmt.Errorf("claim alert rule: %w", err)
+	}
+	return nil
+}
+
+// GetBreachStart returns when the rule's condition started to hold without
+// interruption up to the reading at at: the first reading after the last one
+// in [since, at] that did not breach the threshold. It returns nil when the
+// reading at at does not breach it either.
+func (h *health) GetBreachStart(ctx context.Context, rule models.AlertRule, at, since time.Time) (*time.Time, error) {
+	operator, ok := comparatorOperators[rule.Comparator]
+	if !ok {
+		return nil, fmt.Errorf("get breach start: unknown comparator %q", rule.Comparator)
+	}
+
+	query := `SELECT MIN(recorded_at) FROM health_metrics
+	WHERE user_id = $1 AND metric_type = $2 AND recorded_at >= $4 AND recorded_at <= $5
+	  AND recorded_at > COALESCE((
+	    SELECT MAX(recorded_at) FROM health_metrics
+	    WHERE user_id = $1 AND metric_type = $2 AND recorded_at >= $4 AND recorded_at <= $5
+	      AND NOT (metric_value ` + operator + ` $3)
+	  ), '-infinity')`
+	var start *time.Time
+	if err := h.db.QueryRow(ctx, query, rule.UserId, rule.MetricType, rule.Threshold, since, at).Scan(&start); err != nil {
+		return nil, fmt.Errorf("get breach start: %w", err)
+	}
+	return start, nil
+}
+
+func (h *health) CreateAlert(ctx context.Context, req models.Alert) (int, error) {
+	query := `INSERT INTO alerts (rule_id, user_id, rule_name, metric_type, comparator, threshold, value, recorded_at, deliveries)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	err := h.db.QueryRow(ctx, query, req.RuleId, req.UserId, req.RuleName, req.MetricType, req.Comparator, req.Threshold,
+		req.Value, req.RecordedAt, req.Deliveries).Scan(&req.ID)
+	if err != nil {
+		return 0, fmt.Errorf("create alert: %w", err)
+	}
+	return req.ID, nil
+}
+
+func (h *health) SetAlertDeliveries(ctx context.Context, id int, deliveries []models.AlertDelivery) error {
+	query := `UPDATE alerts SET deliveries = $2 WHERE id = $1 RETURNING id`
+	if err := h.db.QueryRow(ctx, query, id, deliveries).Scan(&id); err != nil {
+		return fmt.Errorf("set alert deliveries: %w", err)
+	}
+	return nil
+}
+
+// GetAlerts returns the user's latest fired alerts, newest first.
+func (h *health) GetAlerts(ctx context.Context, userID, limit int) ([]models.Alert, error) {
+	query := `SELECT id, rule_id, user_id, rule_name, metric_type, comparator, threshold, value, recorded_at, deliveries, created_at
+	FROM alerts WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`
+	rows, err := h.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []models.Alert
+	for rows.Next() {
+		var a models.Alert
+		if err := rows.Scan(&a.ID, &a.RuleId, &a.UserId, &a.RuleName, &a.MetricType, &a.Comparator, &a.Threshold,
+			&a.Value, &a.RecordedAt, &a.Deliveries, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan alert: %w", err)
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
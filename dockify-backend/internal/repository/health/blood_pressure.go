@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+type BloodPressure interface {
+	CreateBloodPressure(ctx context.Context, req models.BloodPressureReading) (int, error)
+	GetBloodPressure(ctx context.Context, userID, id int) (models.BloodPressureReading, error)
+	GetBloodPressureReadings(ctx context.Context, filter models.TimeRangeFilter) ([]models.BloodPressureReading, error)
+	UpdateBloodPressure(ctx context.Context, req models.BloodPressureReading) error
+	DeleteBloodPressure(ctx context.Context, userID, id int) error
+}
+
+const bloodPressureColumns = `id, user_id, systolic, diastolic, pulse, source, recorded_at`
+
+func (h *health) CreateBloodPressure(ctx context.Context, req models.BloodPressureReading) (int, error) {
+	query := `INSERT INTO blood_pressure_readings (user_id, systolic, diastolic, pulse, source, recorded_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err := h.db.QueryRow(ctx, query, req.UserId, req.Systolic, req.Diastolic, req.Pulse, req.Source, req.RecordedAt).Scan(&req.ID)
+	if err != nil {
+		return 0, fmt.Errorf("create blood pressure: %w", err)
+	}
+	return req.ID, nil
+}
+
+func (h *health) GetBloodPressure(ctx context.Context, userID, id int) (models.BloodPressureReading, error) {
+	query := `SELECT ` + bloodPressureColumns + ` FROM blood_pressure_readings WHERE id = $1 AND user_id = $2`
+	var reading models.BloodPressureReading
+	err := h.db.QueryRow(ctx, query, id, userID).Scan(&reading.ID, &reading.UserId, &reading.Systolic,
+		&reading.Diastolic, &reading.Pulse, &reading.Source, &reading.RecordedAt)
+	if err != nil {
+		return models.BloodPressureReading{}, fmt.Errorf("get blood pressure: %w", err)
+	}
+	return reading, nil
+}
+
+func (h *health) GetBloodPressureReadings(ctx context.Context, filter models.TimeRangeFilter) ([]models.BloodPressureReading, error) {
+	tail, args := timeRangeQuery("recorded_at", filter)
+	rows, err := h.db.Query(ctx, `SELECT `+bloodPressureColumns+` FROM blood_pressure_readings `+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get blood pressure readings: %w", err)
+	}
+	defer rows.Close()
+
+	var readings []models.BloodPressureReading
+	for rows.Next() {
+		var reading models.BloodPressureReading
+		if err := rows.Scan(&reading.ID, &reading.UserId, &reading.Systolic, &reading.Diastolic,
+			&reading.Pulse, &reading.Source, &reading.RecordedAt); err != nil {
+			return nil, fmt.Errorf("scan blood pressure: %w", err)
+		}
+		readings = append(readings, reading)
+	}
+
+	return readings, rows.Err()
+}
+
+func (h *health) UpdateBloodPressure(ctx context.Context, req models.BloodPressureReading) error {
+	query := `UPDATE blood_pressure_readings SET systolic = $3, diastolic = $4, pulse = $5, source = $6, recorded_at = $7
+	WHERE id = $1 AND user_id = $2 RETURNING id`
+	err := h.db.QueryRow(ctx, query, req.ID, req.UserId, req.Systolic, req.Diastolic, req.Pulse, req.Source, req.RecordedAt).Scan(&req.ID)
+	if err != nil {
+		return fmt.Errorf("update blood pressure: %w", err)
+	}
+	return nil
+}
+
+func (h *health) DeleteBloodPressure(ctx context.Context, userID, id int) error {
+	query := `DELETE FROM blood_pressure_readings WHERE id = $1 AND user_id = $2 RETURNING id`
+	if err := h.db.QueryRow(ctx, query, id, userID).Scan(&id); err != nil {
+		return fmt.Errorf("delete blood pressure: %w", err)
+	}
+	return nil
+}
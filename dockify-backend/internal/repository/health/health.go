@@ -2,33 +2,85 @@ package health
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/askaroe/dockify-backend/internal/models"
 	"github.com/askaroe/dockify-backend/pkg/psql"
+	"github.com/jackc/pgx/v5"
 )
 
 type Health interface {
-	GetMetricsByUserId(ctx context.Context, id int) (models.HealthMetrics, error)
+	GetMetrics(ctx context.Context, filter models.HealthMetricsFilter) ([]models.HealthMetrics, error)
+	AggregateMetrics(ctx context.Context, filter models.MetricAggregateFilter) ([]models.MetricAggregate, error)
 	CreateHealthMetric(ctx context.Context, req models.HealthMetrics) (int, error)
-	CreateHealthMetrics(ctx context.Context, req []models.HealthMetrics) error
+	CreateHealthMetrics(ctx context.Context, req []models.HealthMetrics) ([]models.UpsertResult, error)
+
+	BloodPressure
+	Workouts
+	SleepSessions
+	AlertRules
 }
 
 type health struct {
-	db *psql.Client
+	db psql.DB
 }
 
-func NewHealthRepository(db *psql.Client) Health {
+func NewHealthRepository(db psql.DB) Health {
 	return &health{db: db}
 }
 
-func (h *health) GetMetricsByUserId(ctx context.Context, id int) (models.HealthMetrics, error) {
-	query := `SELECT id, user_id, metric_type, metric_value, recorded_at FROM health_metrics WHERE user_id = $1`
-	var metrics models.HealthMetrics
-	err := h.db.QueryRow(ctx, query, id).Scan(&metrics.ID, &metrics.UserId, &metrics.MetricType, &metrics.MetricValue, &metrics.RecordedAt)
+func (h *health) GetMetrics(ctx context.Context, filter models.HealthMetricsFilter) ([]models.HealthMetrics, error) {
+	conditions := []string{"user_id = $1"}
+	args := []any{filter.UserId}
+
+	addArg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.MetricTypes) > 0 {
+		conditions = append(conditions, "metric_type = ANY("+addArg(filter.MetricTypes)+")")
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "recorded_at >= "+addArg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "recorded_at < "+addArg(*filter.To))
+	}
+
+	direction, comparator := "ASC", ">"
+	if filter.Descending {
+		direction, comparator = "DESC", "<"
+	}
+
+	if filter.AfterRecordedAt != nil {
+		conditions = append(conditions, fmt.Sprintf("(recorded_at, id) %s (%s, %s)",
+			comparator, addArg(*filter.AfterRecordedAt), addArg(filter.AfterID)))
+	}
+
+	query := fmt.Sprintf(`SELECT id, user_id, metric_type, metric_value, source, external_id, recorded_at FROM health_metrics
+	WHERE %s
+	ORDER BY recorded_at %s, id %s
+	LIMIT %s`, strings.Join(conditions, " AND "), direction, direction, addArg(filter.Limit))
+
+	rows, err := h.db.Query(ctx, query, args...)
 	if err != nil {
-		return models.HealthMetrics{}, err
+		return nil, fmt.Errorf("get metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metrics []models.HealthMetrics
+	for rows.Next() {
+		var metric models.HealthMetrics
+		if err := rows.Scan(&metric.ID, &metric.UserId, &metric.MetricType, &metric.MetricValue,
+			&metric.Source, &metric.ExternalID, &metric.RecordedAt); err != nil {
+			return nil, fmt.Errorf("scan metric: %w", err)
+		}
+		metrics = append(metrics, metric)
 	}
-	return metrics, nil
+
+	return metrics, rows.Err()
 }
 
 func (h *health) CreateHealthMetric(ctx context.Context, req models.HealthMetrics) (int, error) {
@@ -40,14 +92,33 @@ func (h *health) CreateHealthMetric(ctx context.Context, req models.HealthMetric
 	return req.ID, nil
 }
 
-func (h *health) CreateHealthMetrics(ctx context.Context, req []models.HealthMetrics) error {
-	query := `INSERT INTO health_metrics (user_id, metric_type, metric_value) VALUES ($1, $2, $3)`
+// CreateHealthMetrics upserts on (user_id, source, external_id) so a client
+// can safely resend a batch. xmax is zero only for freshly inserted rows,
+// which tells inserts apart from rows that already existed. All statements are
+// pipelined in a single round trip.
+func (h *health) CreateHealthMetrics(ctx context.Context, req []models.HealthMetrics) ([]models.UpsertResult, error) {
+	query := `INSERT INTO health_metrics (user_id, metric_type, metric_value, recorded_at, source, external_id)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (user_id, source, external_id) DO UPDATE
+	SET metric_type = EXCLUDED.metric_type, metric_value = EXCLUDED.metric_value, recorded_at = EXCLUDED.recorded_at
+	RETURNING id, (xmax = 0) AS inserted`
+
+	batch := &pgx.Batch{}
 	for _, metric := range req {
-		_, err := h.db.Exec(ctx, query, metric.UserId, metric.MetricType, metric.MetricValue)
-		if err != nil {
-			return err
+		batch.Queue(query, metric.UserId, metric.MetricType, metric.MetricValue, metric.RecordedAt, metric.Source, metric.ExternalID)
+	}
+
+	br := h.db.SendBatch(ctx, batch)
+	defer br.Close()
+
+	results := make([]models.UpsertResult, 0, len(req))
+	for range req {
+		var result models.UpsertResult
+		if err := br.QueryRow().Scan(&result.ID, &result.Inserted); err != nil {
+			return nil, fmt.Errorf("upsert health metric: %w", err)
 		}
+		results = append(results, result)
 	}
-	return nil
 
+	return results, br.Close()
 }
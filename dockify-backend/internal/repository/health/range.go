@@ -0,0 +1,29 @@
+package health
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+// timeRangeQuery builds the WHERE/ORDER/LIMIT tail shared by the range queries
+// of the structured readings, filtering on column.
+func timeRangeQuery(column string, filter models.TimeRangeFilter) (string, []any) {
+	conditions := []string{"user_id = $1"}
+	args := []any{filter.UserId}
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", column, len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("%s < $%d", column, len(args)))
+	}
+
+	args = append(args, filter.Limit)
+	tail := fmt.Sprintf("WHERE %s ORDER BY %s DESC, id DESC LIMIT $%d", strings.Join(conditions, " AND "), column, len(args))
+
+	return tail, args
+}
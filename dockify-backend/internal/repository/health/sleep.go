@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+type SleepSessions interface {
+	CreateSleepSession(ctx context.Context, req models.SleepSession) (int, error)
+	GetSleepSession(ctx context.Context, userID, id int) (models.SleepSession, error)
+	GetSleepSessions(ctx context.Context, filter models.TimeRangeFilter) ([]models.SleepSession, error)
+	UpdateSleepSession(ctx context.Context, req models.SleepSession) error
+	DeleteSleepSession(ctx context.Context, userID, id int) error
+}
+
+const sleepSessionColumns = `id, user_id, started_at, ended_at, stages, avg_heart_rate, movements_per_hour,
+	snore_minutes, notes, source, created_at`
+
+func scanSleepSession(row interface{ Scan(dest ...any) error }) (models.SleepSession, error) {
+	var s models.SleepSession
+	err := row.Scan(&s.ID, &s.UserId, &s.StartedAt, &s.EndedAt, &s.Stages, &s.AvgHeartRate,
+		&s.MovementsPerHour, &s.SnoreMinutes, &s.Notes, &s.Source, &s.CreatedAt)
+	return s, err
+}
+
+func (h *health) CreateSleepSession(ctx context.Context, req models.SleepSession) (int, error) {
+	query := `INSERT INTO sleep_sessions (user_id, started_at, ended_at, stages, avg_heart_rate, movements_per_hour, snore_minutes, notes, source)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	err := h.db.QueryRow(ctx, query, req.UserId, req.StartedAt, req.EndedAt, req.Stages, req.AvgHeartRate,
+		req.MovementsPerHour, req.SnoreMinutes, req.Notes, req.Source).Scan(&req.ID)
+	if err != nil {
+		return 0, fmt.Errorf("create sleep session: %w", err)
+	}
+	return req.ID, nil
+}
+
+func (h *health) GetSleepSession(ctx context.Context, userID, id int) (models.SleepSession, error) {
+	query := `SELECT ` + sleepSessionColumns + ` FROM sleep_sessions WHERE id = $1 AND user_id = $2`
+	s, err := scanSleepSession(h.db.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		return models.SleepSession{}, fmt.Errorf("get sleep session: %w", err)
+	}
+	return s, nil
+}
+
+func (h *health) GetSleepSessions(ctx context.Context, filter models.TimeRangeFilter) ([]models.SleepSession, error) {
+	tail, args := timeRangeQuery("started_at", filter)
+	rows, err := h.db.Query(ctx, `SELECT `+sleepSessionColumns+` FROM sleep_sessions `+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get sleep sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.SleepSession
+	for rows.Next() {
+		s, err := scanSleepSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan sleep session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (h *health) UpdateSleepSession(ctx context.Context, req models.SleepSession) error {
+	query := `UPDATE sleep_sessions SET started_at = $3, ended_at = $4, stages = $5, avg_heart_rate = $6,
+	movements_per_hour = $7, snore_minutes = $8, notes = $9, source = $10
+	WHERE id = $1 AND user_id = $2 RETURNING id`
+	err := h.db.QueryRow(ctx, query, req.ID, req.UserId, req.StartedAt, req.EndedAt, req.Stages, req.AvgHeartRate,
+		req.MovementsPerHour, req.SnoreMinutes, req.Notes, req.Source).Scan(&req.ID)
+	if err != nil {
+		return fmt.Errorf("update sleep session: %w", err)
+	}
+	return nil
+}
+
+func (h *health) DeleteSleepSession(ctx context.Context, userID, id int) error {
+	query := `DELETE FROM sleep_sessions WHERE id = $1 AND user_id = $2 RETURNING id`
+	if err := h.db.QueryRow(ctx, query, id, userID).Scan(&id); err != nil {
+		return fmt.Errorf("delete sleep session: %w", err)
+	}
+	return nil
+}
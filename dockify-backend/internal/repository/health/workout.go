@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+type Workouts interface {
+	CreateWorkout(ctx context.Context, req models.Workout) (int, error)
+	GetWorkout(ctx context.Context, userID, id int) (models.Workout, error)
+	GetWorkouts(ctx context.Context, filter models.TimeRangeFilter) ([]models.Workout, error)
+	UpdateWorkout(ctx context.Context, req models.Workout) error
+	DeleteWorkout(ctx context.Context, userID, id int) error
+}
+
+const workoutColumns = `id, user_id, workout_type, started_at, ended_at,
+	EXTRACT(EPOCH FROM ended_at - started_at)::DOUBLE PRECISION / 60, calories_burned, avg_bpm, max_bpm, source, created_at`
+
+func scanWorkout(row interface{ Scan(dest ...any) error }) (models.Workout, error) {
+	var w models.Workout
+	err := row.Scan(&w.ID, &w.UserId, &w.WorkoutType, &w.StartedAt, &w.EndedAt, &w.DurationMinutes,
+		&w.CaloriesBurned, &w.AvgBpm, &w.MaxBpm, &w.Source, &w.CreatedAt)
+	return w, err
+}
+
+func (h *health) CreateWorkout(ctx context.Context, req models.Workout) (int, error) {
+	query := `INSERT INTO workouts (user_id, workout_type, started_at, ended_at, calories_burned, avg_bpm, max_bpm, source)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	err := h.db.QueryRow(ctx, query, req.UserId, req.WorkoutType, req.StartedAt, req.EndedAt,
+		req.CaloriesBurned, req.AvgBpm, req.MaxBpm, req.Source).Scan(&req.ID)
+	if err != nil {
+		return 0, fmt.Errorf("create workout: %w", err)
+	}
+	return req.ID, nil
+}
+
+func (h *health) GetWorkout(ctx context.Context, userID, id int) (models.Workout, error) {
+	query := `SELECT ` + workoutColumns + ` FROM workouts WHERE id = $1 AND user_id = $2`
+	w, err := scanWorkout(h.db.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		return models.Workout{}, fmt.Errorf("get workout: %w", err)
+	}
+	return w, nil
+}
+
+func (h *health) GetWorkouts(ctx context.Context, filter models.TimeRangeFilter) ([]models.Workout, error) {
+	tail, args := timeRangeQuery("started_at", filter)
+	rows, err := h.db.Query(ctx, `SELECT `+workoutColumns+` FROM workouts `+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get workouts: %w", err)
+	}
+	defer rows.Close()
+
+	var workouts []models.Workout
+	for rows.Next() {
+		w, err := scanWorkout(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan workout: %w", err)
+		}
+		workouts = append(workouts, w)
+	}
+
+	return workouts, rows.Err()
+}
+
+func (h *health) UpdateWorkout(ctx context.Context, req models.Workout) error {
+	query := `UPDATE workouts SET workout_type = $3, started_at = $4, ended_at = $5, calories_burned = $6,
+	avg_bpm = $7, max_bpm = $8, source = $9
+	WHERE id = $1 AND user_id = $2 RETURNING id`
+	err := h.db.QueryRow(ctx, query, req.ID, req.UserId, req.WorkoutType, req.StartedAt, req.EndedAt,
+		req.CaloriesBurned, req.AvgBpm, req.MaxBpm, req.Source).Scan(&req.ID)
+	if err != nil {
+		return fmt.Errorf("update workout: %w", err)
+	}
+	return nil
+}
+
+func (h *health) DeleteWorkout(ctx context.Context, userID, id int) error {
+	query := `DELETE FROM workouts WHERE id = $1 AND user_id = $2 RETURNING id`
+	if err := h.db.QueryRow(ctx, query, id, userID).Scan(&id); err != nil {
+		return fmt.Errorf("delete workout: %w", err)
+	}
+	return nil
+}
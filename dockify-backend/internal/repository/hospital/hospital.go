@@ -0,0 +1,131 @@
+package hospital
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/pkg/psql"
+)
+
+type Hospital interface {
+	GetNearestHospitals(ctx context.Context, filter models.HospitalFilter) ([]models.Hospital, error)
+	FindHospitalMatch(ctx context.Context, req models.Hospital, maxDistance float64) (models.Hospital, error)
+	CreateHospital(ctx context.Context, req models.Hospital) (int, error)
+	UpdateHospital(ctx context.Context, req models.Hospital) error
+}
+
+type hospital struct {
+	db psql.DB
+}
+
+func NewHospitalRepository(db psql.DB) Hospital {
+	return &hospital{db: db}
+}
+
+const hospitalColumns = `id, amenity, name, address, phone, specialties, emergency, opening_hours, timezone,
+	latitude, longitude, source, external_id`
+
+// haversine is the great-circle distance in metres between ($1, $2) and the row.
+const haversine = `2 * 6371000 * ASIN(SQRT(
+	      POWER(SIN(RADIANS($1 - latitude) / 2), 2) +
+	      COS(RADIANS($1)) * COS(RADIANS(latitude)) *
+	      POWER(SIN(RADIANS($2 - longitude) / 2), 2)
+	    ))`
+
+func scanHospital(row interface{ Scan(dest ...any) error }, extra ...any) (models.Hospital, error) {
+	var h models.Hospital
+	dest := []any{&h.ID, &h.Amenity, &h.Name, &h.Address, &h.Phone, &h.Specialties, &h.Emergency, &h.OpeningHours,
+		&h.Timezone, &h.Latitude, &h.Longitude, &h.Source, &h.ExternalID}
+	err := row.Scan(append(dest, extra...)...)
+	return h, err
+}
+
+// GetNearestHospitals returns the hospitals within filter.Radius metres of the
+// point, nearest first, with the haversine distance in Distance.
+func (h *hospital) GetNearestHospitals(ctx context.Context, filter models.HospitalFilter) ([]models.Hospital, error) {
+	conditions := []string{"distance <= $3"}
+	args := []any{filter.Latitude, filter.Longitude, filter.Radius}
+
+	if filter.Amenity != "" {
+		args = append(args, filter.Amenity)
+		conditions = append(conditions, fmt.Sprintf("amenity = $%d", len(args)))
+	}
+	if filter.Specialty != "" {
+		args = append(args, filter.Specialty)
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(specialties)", len(args)))
+	}
+	if filter.EmergencyOnly {
+		conditions = append(conditions, "emergency")
+	}
+
+	query := `SELECT ` + hospitalColumns + `, distance
+	FROM (
+	  SELECT ` + hospitalColumns + `,
+	    ` + haversine + ` AS distance
+	  FROM hospitals
+	) AS h
+	WHERE ` + strings.Join(conditions, " AND ") + `
+	ORDER BY distance ASC`
+
+	rows, err := h.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get nearest hospitals: %w", err)
+	}
+	defer rows.Close()
+
+	var hospitals []models.Hospital
+	for rows.Next() {
+		var distance float64
+		hp, err := scanHospital(rows, &distance)
+		if err != nil {
+			return nil, fmt.Errorf("scan hospital: %w", err)
+		}
+		hp.Distance = distance
+		hospitals = append(hospitals, hp)
+	}
+
+	return hospitals, rows.Err()
+}
+
+// FindHospitalMatch looks up the stored row req corresponds to: the row with
+// the same source and external id, or else one with the same name (ignoring
+// case) within maxDistance metres. It returns pgx.ErrNoRows when there is none.
+func (h *hospital) FindHospitalMatch(ctx context.Context, req models.Hospital, maxDistance float64) (models.Hospital, error) {
+	query := `SELECT ` + hospitalColumns + ` FROM hospitals
+	WHERE (source = $3 AND external_id = $4)
+	   OR (LOWER(name) = LOWER($5) AND ` + haversine + ` <= $6)
+	ORDER BY (source = $3 AND external_id IS NOT DISTINCT FROM $4) DESC, id ASC
+	LIMIT 1`
+
+	hp, err := scanHospital(h.db.QueryRow(ctx, query, req.Latitude, req.Longitude, req.Source, req.ExternalID, req.Name, maxDistance))
+	if err != nil {
+		return models.Hospital{}, fmt.Errorf("find hospital match: %w", err)
+	}
+	return hp, nil
+}
+
+func (h *hospital) CreateHospital(ctx context.Context, req models.Hospital) (int, error) {
+	query := `INSERT INTO hospitals (amenity, name, address, phone, specialties, emergency, opening_hours, timezone,
+		latitude, longitude, source, external_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
+	err := h.db.QueryRow(ctx, query, req.Amenity, req.Name, req.Address, req.Phone, req.Specialties, req.Emergency,
+		req.OpeningHours, req.Timezone, req.Latitude, req.Longitude, req.Source, req.ExternalID).Scan(&req.ID)
+	if err != nil {
+		return 0, fmt.Errorf("create hospital: %w", err)
+	}
+	return req.ID, nil
+}
+
+func (h *hospital) UpdateHospital(ctx context.Context, req models.Hospital) error {
+	query := `UPDATE hospitals SET amenity = $2, name = $3, address = $4, phone = $5, specialties = $6, emergency = $7,
+		opening_hours = $8, timezone = $9, latitude = $10, longitude = $11, source = $12, external_id = $13, updated_at = NOW()
+	WHERE id = $1 RETURNING id`
+	err := h.db.QueryRow(ctx, query, req.ID, req.Amenity, req.Name, req.Address, req.Phone, req.Specialties, req.Emergency,
+		req.OpeningHours, req.Timezone, req.Latitude, req.Longitude, req.Source, req.ExternalID).Scan(&req.ID)
+	if err != nil {
+		return fmt.Errorf("update hospital: %w", err)
+	}
+	return nil
+}
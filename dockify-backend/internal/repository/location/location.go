@@ -2,22 +2,32 @@ package location
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"strings"
 
 	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/pkg/geo"
 	"github.com/askaroe/dockify-backend/pkg/psql"
 )
 
 type Location interface {
 	Insert(ctx context.Context, req models.Location) error
-	GetNearestUsers(ctx context.Context, latitude, longitude float64, radius int) ([]models.Location, error)
+	GetNearestUsers(ctx context.Context, filter models.NearestUsersFilter) ([]models.Location, error)
+	GetLocationHistory(ctx context.Context, filter models.TimeRangeFilter) ([]models.Location, error)
+	StreamTrack(ctx context.Context, filter models.TrackFilter, fn func(models.TrackPoint) error) error
 }
 
 type location struct {
-	db *psql.Client
+	db      psql.DB
+	postGIS bool
 }
 
-func NewLocationRepository(db *psql.Client) Location {
-	return &location{db: db}
+// NewLocationRepository returns the location repository. With postGIS set,
+// searches use the geography index of migration 0011, otherwise a bounding box
+// on the (latitude, longitude) index.
+func NewLocationRepository(db psql.DB, postGIS bool) Location {
+	return &location{db: db, postGIS: postGIS}
 }
 
 func (l *location) Insert(ctx context.Context, req models.Location) error {
@@ -26,7 +36,42 @@ func (l *location) Insert(ctx context.Context, req models.Location) error {
 	return err
 }
 
-func (l *location) GetNearestUsers(ctx context.Context, latitude, longitude float64, radius int) ([]models.Location, error) {
+// GetNearestUsers returns, for every user with a location within filter.Radius
+// metres, the location nearest to the point. Locations older than
+// filter.MaxAge are ignored when it is set, and so are users whose account is
+// scheduled for deletion. The index pre-filter only narrows the rows the
+// haversine distance is computed for, so both modes return the same result.
+func (l *location) GetNearestUsers(ctx context.Context, filter models.NearestUsersFilter) ([]models.Location, error) {
+	args := []any{filter.Latitude, filter.Longitude, filter.Radius}
+	var prefilter string
+	if l.postGIS {
+		// with use_spheroid false PostGIS measures on a sphere with the mean
+		// WGS84 radius of 6371008.8 m, so its distances are about 1.4 ppm
+		// longer than the haversine's on geo.EarthRadius. The 0.1% + 1 m
+		// margin is far above that and any rounding, so every row the
+		// haversine accepts is kept.
+		prefilter = `WHERE ST_DWithin(geog, ST_SetSRID(ST_MakePoint($2, $1), 4326)::geography, $3 * 1.001 + 1, false)`
+	} else {
+		prefilter, args = boundingBox(filter.Latitude, filter.Longitude, filter.Radius, args)
+	}
+	return l.nearestUsers(ctx, filter, prefilter, args)
+}
+
+// nearestUsers runs the haversine search over the rows matching prefilter,
+// which is empty or a WHERE clause on the parameters in args after the first
+// three.
+func (l *location) nearestUsers(ctx context.Context, filter models.NearestUsersFilter, prefilter string, args []any) ([]models.Location, error) {
+	if filter.MaxAge > 0 {
+		args = append(args, filter.MaxAge.Seconds())
+		// recorded_at defaults to the database clock, so compare against it
+		staleness := fmt.Sprintf("recorded_at >= NOW() - make_interval(secs => $%d)", len(args))
+		if prefilter == "" {
+			prefilter = "WHERE " + staleness
+		} else {
+			prefilter += " AND " + staleness
+		}
+	}
+
 	query := `SELECT DISTINCT ON (user_id) user_id, latitude, longitude, recorded_at
 	FROM (
 	  SELECT user_id, latitude, longitude, recorded_at,
@@ -36,10 +81,12 @@ func (l *location) GetNearestUsers(ctx context.Context, latitude, longitude floa
 	      POWER(SIN(RADIANS($2 - longitude) / 2), 2)
 	    )) AS distance
 	  FROM locations
+	  ` + prefilter + `
 	) AS l
 	WHERE distance <= $3
+	  AND user_id IN (SELECT id FROM users WHERE deletion_scheduled_at IS NULL)
 	ORDER BY user_id, distance ASC`
-	rows, err := l.db.Query(ctx, query, latitude, longitude, radius)
+	rows, err := l.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -54,5 +101,111 @@ func (l *location) GetNearestUsers(ctx context.Context, latitude, longitude floa
 		locations = append(locations, loc)
 	}
 
-	return locations, nil
+	return locations, rows.Err()
+}
+
+// GetLocationHistory returns the user's locations in [From, To), oldest first.
+func (l *location) GetLocationHistory(ctx context.Context, filter models.TimeRangeFilter) ([]models.Location, error) {
+	conditions := []string{"user_id = $1"}
+	args := []any{filter.UserId}
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("recorded_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("recorded_at < $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`SELECT id, user_id, latitude, longitude, recorded_at FROM locations
+	WHERE %s
+	ORDER BY recorded_at ASC, id ASC
+	LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := l.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get location history: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []models.Location
+	for rows.Next() {
+		var loc models.Location
+		if err := rows.Scan(&loc.ID, &loc.UserId, &loc.Latitude, &loc.Longitude, &loc.RecordedAt); err != nil {
+			return nil, fmt.Errorf("scan location: %w", err)
+		}
+		locations = append(locations, loc)
+	}
+
+	return locations, rows.Err()
+}
+
+// StreamTrack calls fn for each of the user's locations in the range, oldest
+// first, without loading them all into memory. An error from fn stops the
+// iteration and is returned.
+func (l *location) StreamTrack(ctx context.Context, filter models.TrackFilter, fn func(models.TrackPoint) error) error {
+	heartRate := `NULL::DOUBLE PRECISION`
+	args := []any{filter.UserId, filter.From, filter.To}
+	if filter.HeartRateWindow > 0 {
+		args = append(args, filter.HeartRateWindow.Seconds())
+		heartRate = `(
+		SELECT m.metric_value FROM health_metrics m
+		WHERE m.user_id = l.user_id AND m.metric_type = 'heart_rate'
+		  AND m.recorded_at BETWEEN l.recorded_at - make_interval(secs => $4) AND l.recorded_at + make_interval(secs => $4)
+		ORDER BY ABS(EXTRACT(EPOCH FROM m.recorded_at - l.recorded_at))
+		LIMIT 1
+	  )`
+	}
+
+	query := `SELECT l.latitude, l.longitude, l.recorded_at, ` + heartRate + `
+	FROM locations l
+	WHERE l.user_id = $1 AND l.recorded_at >= $2 AND l.recorded_at < $3
+	ORDER BY l.recorded_at ASC, l.id ASC`
+
+	rows, err := l.db.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("stream track: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.TrackPoint
+		if err := rows.Scan(&p.Latitude, &p.Longitude, &p.RecordedAt, &p.HeartRate); err != nil {
+			return fmt.Errorf("scan track point: %w", err)
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// boundingBox returns the condition limiting rows to the smallest
+// latitude/longitude box containing the search circle. The longitude bound is
+// dropped when the circle reaches a pole or crosses the antimeridian.
+func boundingBox(latitude, longitude float64, radius int, args []any) (string, []any) {
+	// a small margin so rounding never excludes a row on the circle
+	angle := float64(radius)/geo.EarthRadius + 1e-9
+	latDelta := angle * 180 / math.Pi
+	if latDelta >= 180 {
+		return "", args
+	}
+
+	args = append(args, latitude-latDelta, latitude+latDelta)
+	condition := `WHERE latitude BETWEEN $4 AND $5`
+
+	cosLat := math.Cos(latitude * math.Pi / 180)
+	if math.Sin(angle) >= cosLat || angle >= math.Pi/2 {
+		return condition, args
+	}
+	lonDelta := math.Asin(math.Sin(angle)/cosLat) * 180 / math.Pi
+	if longitude-lonDelta < -180 || longitude+lonDelta > 180 {
+		return condition, args
+	}
+
+	args = append(args, longitude-lonDelta, longitude+lonDelta)
+	return condition + ` AND longitude BETWEEN $6 AND $7`, args
 }
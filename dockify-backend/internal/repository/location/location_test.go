@@ -0,0 +1,258 @@
+package location
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/pkg/geo"
+	"github.com/jackc/pgx/v5"
+)
+
+// postGISSphereRadius is the radius PostGIS measures geography distances on
+// when use_spheroid is false: the mean radius (2a + b) / 3 of WGS84.
+const postGISSphereRadius = (2*6378137 + 6356752.314245179) / 3
+
+type circle struct {
+	name     string
+	lat, lon float64
+	radius   int
+}
+
+var circles = []circle{
+	{"almaty", 43.238949, 76.889709, 2000},
+	{"null island", 0, 0, 500},
+	{"london", 51.5072, -0.1276, 50000},
+	{"north pole", 89.99, 10, 3000},
+	{"antimeridian", -33.9, 179.999, 5000},
+	{"south pole", -89.5, 0, 100000},
+	{"bering sea", 60, 179.5, 100000},
+}
+
+// destination returns the point distance metres from the start in the
+// direction of bearing, in radians clockwise from north.
+func destination(lat, lon, bearing, distance float64) (float64, float64) {
+	phi1, lambda1 := lat*math.Pi/180, lon*math.Pi/180
+	delta := distance / geo.EarthRadius
+
+	phi2 := math.Asin(math.Sin(phi1)*math.Cos(delta) + math.Cos(phi1)*math.Sin(delta)*math.Cos(bearing))
+	lambda2 := lambda1 + math.Atan2(math.Sin(bearing)*math.Sin(delta)*math.Cos(phi1), math.Cos(delta)-math.Sin(phi1)*math.Sin(phi2))
+
+	lon2 := math.Mod(lambda2*180/math.Pi+540, 360) - 180
+	return phi2 * 180 / math.Pi, lon2
+}
+
+// pointsAround returns n points spread over 1.2 times the circle, so about
+// two thirds are inside, and a few right on its edge.
+func pointsAround(rng *rand.Rand, c circle, n int) [][2]float64 {
+	points := make([][2]float64, 0, n+8)
+	for i := range n + 8 {
+		distance := float64(c.radius) * 1.2 * math.Sqrt(rng.Float64())
+		if i >= n {
+			distance = float64(c.radius) * 0.999999
+		}
+		lat, lon := destination(c.lat, c.lon, rng.Float64()*2*math.Pi, distance)
+		points = append(points, [2]float64{lat, lon})
+	}
+	return points
+}
+
+func TestBoundingBoxKeepsCircle(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, c := range circles {
+		condition, args := boundingBox(c.lat, c.lon, c.radius, []any{c.lat, c.lon, c.radius})
+		if c.name == "almaty" && len(args) != 7 {
+			t.Errorf("%s: box has no longitude bound: %q", c.name, condition)
+		}
+
+		for _, p := range pointsAround(rng, c, 2000) {
+			if geo.Distance(c.lat, c.lon, p[0], p[1]) > float64(c.radius) {
+				continue
+			}
+			if len(args) >= 5 && (p[0] < args[3].(float64) || p[0] > args[4].(float64)) {
+				t.Fatalf("%s: latitude of %v is outside of %v", c.name, p, args[3:5])
+			}
+			if len(args) == 7 && (p[1] < args[5].(float64) || p[1] > args[6].(float64)) {
+				t.Fatalf("%s: longitude of %v is outside of %v", c.name, p, args[5:7])
+			}
+		}
+	}
+}
+
+func TestPostGISMarginKeepsCircle(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	for _, c := range circles {
+		limit := float64(c.radius)*1.001 + 1
+		for _, p := range pointsAround(rng, c, 2000) {
+			distance := geo.Distance(c.lat, c.lon, p[0], p[1])
+			if distance > float64(c.radius) {
+				continue
+			}
+			if d := distance * postGISSphereRadius / geo.EarthRadius; d > limit {
+				t.Fatalf("%s: PostGIS distance %.3f of %v exceeds %.3f", c.name, d, p, limit)
+			}
+		}
+	}
+}
+
+// testDB returns a transaction on the database in DOCKIFY_TEST_DATABASE_URL,
+// which must have all migrations applied. It is rolled back when the test
+// ends.
+func testDB(tb testing.TB) pgx.Tx {
+	url := os.Getenv("DOCKIFY_TEST_DATABASE_URL")
+	if url == "" {
+		tb.Skip("DOCKIFY_TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		tb.Fatalf("connect: %v", err)
+	}
+	tb.Cleanup(func() { conn.Close(ctx) })
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		tb.Fatalf("begin: %v", err)
+	}
+	tb.Cleanup(func() { tx.Rollback(ctx) })
+	return tx
+}
+
+func hasPostGIS(tb testing.TB, tx pgx.Tx) bool {
+	query := `SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'locations' AND column_name = 'geog')`
+	var ok bool
+	if err := tx.QueryRow(context.Background(), query).Scan(&ok); err != nil {
+		tb.Fatalf("check postgis: %v", err)
+	}
+	return ok
+}
+
+// seedLocations creates users with a few locations each, half of them around
+// the test circles and half anywhere on earth.
+func seedLocations(tb testing.TB, tx pgx.Tx, rng *rand.Rand, users int) {
+	ctx := context.Background()
+	rows, err := tx.Query(ctx, `INSERT INTO users (username, first_name, last_name, email, password_hash)
+	SELECT 'nearest-test-' || n, 'Test', 'User', 'nearest-test-' || n || '@example.com', ''
+	FROM generate_series(1, $1) AS n
+	RETURNING id`, users)
+	if err != nil {
+		tb.Fatalf("create users: %v", err)
+	}
+	userIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		tb.Fatalf("create users: %v", err)
+	}
+
+	var locations [][]any
+	for i, userID := range userIDs {
+		for range 1 + rng.IntN(3) {
+			var lat, lon float64
+			if i%2 == 0 {
+				c := circles[rng.IntN(len(circles))]
+				p := pointsAround(rng, c, 8)[rng.IntN(16)]
+				lat, lon = p[0], p[1]
+			} else {
+				lat, lon = math.Asin(2*rng.Float64()-1)*180/math.Pi, rng.Float64()*360-180
+			}
+			locations = append(locations, []any{userID, lat, lon})
+		}
+	}
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"locations"}, []string{"user_id", "latitude", "longitude"}, pgx.CopyFromRows(locations))
+	if err != nil {
+		tb.Fatalf("create locations: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `ANALYZE locations`); err != nil {
+		tb.Fatalf("analyze: %v", err)
+	}
+}
+
+func locationKeys(locations []models.Location) []string {
+	keys := make([]string, 0, len(locations))
+	for _, loc := range locations {
+		keys = append(keys, fmt.Sprintf("%d %s %s", loc.UserId, loc.Latitude, loc.Longitude))
+	}
+	return keys
+}
+
+// TestNearestUsersQueriesAgree checks that the bounding box and PostGIS
+// pre-filters return exactly what the haversine over all rows returns.
+func TestNearestUsersQueriesAgree(t *testing.T) {
+	tx := testDB(t)
+	seedLocations(t, tx, rand.New(rand.NewPCG(5, 6)), 5000)
+	postGIS := hasPostGIS(t, tx)
+	ctx := context.Background()
+
+	for _, c := range circles {
+		for _, maxAge := range []time.Duration{0, time.Hour} {
+			filter := models.NearestUsersFilter{Latitude: c.lat, Longitude: c.lon, Radius: c.radius, MaxAge: maxAge}
+
+			haversine, err := (&location{db: tx}).nearestUsers(ctx, filter, "", []any{c.lat, c.lon, c.radius})
+			if err != nil {
+				t.Fatalf("%s: haversine: %v", c.name, err)
+			}
+			if len(haversine) == 0 {
+				t.Fatalf("%s: no users found", c.name)
+			}
+			want := locationKeys(haversine)
+
+			box, err := (&location{db: tx}).GetNearestUsers(ctx, filter)
+			if err != nil {
+				t.Fatalf("%s: bounding box: %v", c.name, err)
+			}
+			if got := locationKeys(box); !slices.Equal(got, want) {
+				t.Errorf("%s: bounding box returned %d users, haversine %d", c.name, len(got), len(want))
+			}
+
+			if !postGIS {
+				continue
+			}
+			geog, err := (&location{db: tx, postGIS: true}).GetNearestUsers(ctx, filter)
+			if err != nil {
+				t.Fatalf("%s: postgis: %v", c.name, err)
+			}
+			if got := locationKeys(geog); !slices.Equal(got, want) {
+				t.Errorf("%s: postgis returned %d users, haversine %d", c.name, len(got), len(want))
+			}
+		}
+	}
+}
+
+func BenchmarkNearestUsers(b *testing.B) {
+	tx := testDB(b)
+	seedLocations(b, tx, rand.New(rand.NewPCG(7, 8)), 50000)
+	ctx := context.Background()
+	c := circles[0]
+	filter := models.NearestUsersFilter{Latitude: c.lat, Longitude: c.lon, Radius: c.radius, MaxAge: 30 * time.Minute}
+
+	b.Run("haversine", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := (&location{db: tx}).nearestUsers(ctx, filter, "", []any{c.lat, c.lon, c.radius}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("bounding_box", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := (&location{db: tx}).GetNearestUsers(ctx, filter); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("postgis", func(b *testing.B) {
+		if !hasPostGIS(b, tx) {
+			b.Skip("locations has no geog column")
+		}
+		for b.Loop() {
+			if _, err := (&location{db: tx, postGIS: true}).GetNearestUsers(ctx, filter); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package prediction
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/pkg/psql"
+)
+
+type Prediction interface {
+	CreatePrediction(ctx context.Context, req models.Prediction) (models.Prediction, error)
+	GetPredictions(ctx context.Context, filter models.PredictionFilter) ([]models.Prediction, error)
+}
+
+type prediction struct {
+	db psql.DB
+}
+
+func NewPredictionRepository(db psql.DB) Prediction {
+	return &prediction{db: db}
+}
+
+func (p *prediction) CreatePrediction(ctx context.Context, req models.Prediction) (models.Prediction, error) {
+	query := `INSERT INTO predictions (user_id, kind, model_version, input, output) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	err := p.db.QueryRow(ctx, query, req.UserId, req.Kind, req.ModelVersion, req.Input, req.Output).Scan(&req.ID, &req.CreatedAt)
+	if err != nil {
+		return models.Prediction{}, fmt.Errorf("create prediction: %w", err)
+	}
+	return req, nil
+}
+
+func (p *prediction) GetPredictions(ctx context.Context, filter models.PredictionFilter) ([]models.Prediction, error) {
+	conditions := []string{"user_id = $1"}
+	args := []any{filter.UserId}
+
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		conditions = append(conditions, fmt.Sprintf("kind = $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`SELECT id, user_id, kind, model_version, input, output, created_at FROM predictions
+	WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get predictions: %w", err)
+	}
+	defer rows.Close()
+
+	var predictions []models.Prediction
+	for rows.Next() {
+		var pr models.Prediction
+		if err := rows.Scan(&pr.ID, &pr.UserId, &pr.Kind, &pr.ModelVersion, &pr.Input, &pr.Output, &pr.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan prediction: %w", err)
+		}
+		predictions = append(predictions, pr)
+	}
+
+	return predictions, rows.Err()
+}
@@ -0,0 +1,130 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/pkg/psql"
+	"github.com/jackc/pgx/v5"
+)
+
+type Recommendation interface {
+	UpsertRecommendations(ctx context.Context, req []models.Recommendation) error
+	ResolveRecommendations(ctx context.Context, userID int, activeRules []string) error
+	GetDismissedRules(ctx context.Context, userID int, since time.Time) ([]string, error)
+	GetRecommendations(ctx context.Context, filter models.RecommendationFilter) ([]models.Recommendation, error)
+	MarkRecommendationRead(ctx context.Context, userID, id int) error
+	DismissRecommendation(ctx context.Context, userID, id int) error
+}
+
+type recommendation struct {
+	db psql.DB
+}
+
+func NewRecommendationRepository(db psql.DB) Recommendation {
+	return &recommendation{db: db}
+}
+
+// UpsertRecommendations creates or refreshes the open recommendation of each
+// rule. Refreshing keeps read_at, so content that is merely re-evaluated does
+// not show up as unread again.
+func (r *recommendation) UpsertRecommendations(ctx context.Context, req []models.Recommendation) error {
+	query := `INSERT INTO recommendations (user_id, rule, category, priority, title, message, reason, source_metrics)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (user_id, rule) WHERE dismissed_at IS NULL AND resolved_at IS NULL DO UPDATE
+	SET category = EXCLUDED.category, priority = EXCLUDED.priority, title = EXCLUDED.title, message = EXCLUDED.message,
+		reason = EXCLUDED.reason, source_metrics = EXCLUDED.source_metrics, updated_at = NOW()`
+
+	batch := &pgx.Batch{}
+	for _, rec := range req {
+		batch.Queue(query, rec.UserId, rec.Rule, rec.Category, rec.Priority, rec.Title, rec.Message, rec.Reason, rec.SourceMetrics)
+	}
+
+	br := r.db.SendBatch(ctx, batch)
+	defer br.Close()
+
+	for range req {
+		if _, err := br.Exec(); err != nil {
+			return fmt.Errorf("upsert recommendation: %w", err)
+		}
+	}
+
+	return br.Close()
+}
+
+// ResolveRecommendations closes the user's open recommendations whose rule no
+// longer fires.
+func (r *recommendation) ResolveRecommendations(ctx context.Context, userID int, activeRules []string) error {
+	query := `UPDATE recommendations SET resolved_at = NOW()
+	WHERE user_id = $1 AND dismissed_at IS NULL AND resolved_at IS NULL AND rule <> ALL($2)`
+	if _, err := r.db.Exec(ctx, query, userID, activeRules); err != nil {
+		return fmt.Errorf("resolve recommendations: %w", err)
+	}
+	return nil
+}
+
+func (r *recommendation) GetDismissedRules(ctx context.Context, userID int, since time.Time) ([]string, error) {
+	query := `SELECT DISTINCT rule FROM recommendations WHERE user_id = $1 AND dismissed_at >= $2`
+	rows, err := r.db.Query(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("get dismissed rules: %w", err)
+	}
+
+	rules, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("scan dismissed rule: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *recommendation) GetRecommendations(ctx context.Context, filter models.RecommendationFilter) ([]models.Recommendation, error) {
+	query := `SELECT id, user_id, rule, category, priority, title, message, reason, source_metrics, read_at, dismissed_at, created_at, updated_at
+	FROM recommendations
+	WHERE user_id = $1 AND dismissed_at IS NULL AND resolved_at IS NULL`
+	args := []any{filter.UserId}
+
+	if filter.AfterPriority != nil {
+		args = append(args, *filter.AfterPriority, filter.AfterID)
+		query += ` AND (priority, id) < ($2, $3)`
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(` ORDER BY priority DESC, id DESC LIMIT $%d`, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get recommendations: %w", err)
+	}
+	defer rows.Close()
+
+	var recommendations []models.Recommendation
+	for rows.Next() {
+		var rec models.Recommendation
+		err := rows.Scan(&rec.ID, &rec.UserId, &rec.Rule, &rec.Category, &rec.Priority, &rec.Title, &rec.Message,
+			&rec.Reason, &rec.SourceMetrics, &rec.ReadAt, &rec.DismissedAt, &rec.CreatedAt, &rec.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan recommendation: %w", err)
+		}
+		recommendations = append(recommendations, rec)
+	}
+
+	return recommendations, rows.Err()
+}
+
+func (r *recommendation) MarkRecommendationRead(ctx context.Context, userID, id int) error {
+	query := `UPDATE recommendations SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2 RETURNING id`
+	if err := r.db.QueryRow(ctx, query, id, userID).Scan(&id); err != nil {
+		return fmt.Errorf("mark recommendation read: %w", err)
+	}
+	return nil
+}
+
+func (r *recommendation) DismissRecommendation(ctx context.Context, userID, id int) error {
+	query := `UPDATE recommendations SET dismissed_at = COALESCE(dismissed_at, NOW()) WHERE id = $1 AND user_id = $2 RETURNING id`
+	if err := r.db.QueryRow(ctx, query, id, userID).Scan(&id); err != nil {
+		return fmt.Errorf("dismiss recommendation: %w", err)
+	}
+	return nil
+}
@@ -1,22 +1,70 @@
 package repository
 
 import (
+	"context"
+
+	"github.com/askaroe/dockify-backend/internal/repository/care"
+	"github.com/askaroe/dockify-backend/internal/repository/friend"
 	"github.com/askaroe/dockify-backend/internal/repository/health"
+	"github.com/askaroe/dockify-backend/internal/repository/hospital"
 	"github.com/askaroe/dockify-backend/internal/repository/location"
+	"github.com/askaroe/dockify-backend/internal/repository/prediction"
+	"github.com/askaroe/dockify-backend/internal/repository/recommendation"
+	"github.com/askaroe/dockify-backend/internal/repository/sos"
 	"github.com/askaroe/dockify-backend/internal/repository/user"
 	"github.com/askaroe/dockify-backend/pkg/psql"
+	"github.com/jackc/pgx/v5"
 )
 
 type Repository struct {
 	health.Health
 	user.User
 	location.Location
+	hospital.Hospital
+	prediction.Prediction
+	recommendation.Recommendation
+	friend.Friend
+	sos.SOS
+	care.Care
+	user.LoginFailures
+
+	// client is nil for repositories bound to a transaction.
+	client  *psql.Client
+	postGIS bool
+}
+
+func NewRepository(client *psql.Client, postGIS bool) *Repository {
+	repo := newRepository(client, postGIS)
+	repo.client = client
+	return repo
 }
 
-func NewRepository(client *psql.Client) *Repository {
+func newRepository(db psql.DB, postGIS bool) *Repository {
 	return &Repository{
-		Health:   health.NewHealthRepository(client),
-		User:     user.NewUserRepository(client),
-		Location: location.NewLocationRepository(client),
+		Health:         health.NewHealthRepository(db),
+		User:           user.NewUserRepository(db),
+		Location:       location.NewLocationRepository(db, postGIS),
+		Hospital:       hospital.NewHospitalRepository(db),
+		Prediction:     prediction.NewPredictionRepository(db),
+		Recommendation: recommendation.NewRecommendationRepository(db),
+		Friend:         friend.NewFriendRepository(db),
+		SOS:            sos.NewSOSRepository(db),
+		Care:           care.NewCareRepository(db),
+		LoginFailures:  user.NewLoginFailureRepository(db),
+		postGIS:        postGIS,
 	}
 }
+
+// WithTx is the unit of work for multi-table writes: every repository call made
+// through tx runs in one transaction that is committed when fn returns nil and
+// rolled back otherwise. Calling WithTx on a transactional repository joins the
+// outer transaction.
+func (r *Repository) WithTx(ctx context.Context, fn func(tx *Repository) error) error {
+	if r.client == nil {
+		return fn(r)
+	}
+
+	return pgx.BeginFunc(ctx, r.client, func(tx pgx.Tx) error {
+		return fn(newRepository(tx, r.postGIS))
+	})
+}
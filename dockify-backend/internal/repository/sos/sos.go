@@ -0,0 +1,214 @@
+package sos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/pkg/psql"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	StatusOpen     = "open"
+	StatusResolved = "resolved"
+
+	EventCreated      = "created"
+	EventNotified     = "notified"
+	EventNotifyFailed = "notify_failed"
+	EventAcknowledged = "acknowledged"
+	EventResolved     = "resolved"
+)
+
+type SOS interface {
+	CreateIncident(ctx context.Context, incident models.Incident) (models.Incident, error)
+	GetIncident(ctx context.Context, id int) (models.Incident, error)
+	GetIncidents(ctx context.Context, userID, limit int) ([]models.Incident, error)
+	ResolveIncident(ctx context.Context, id int) (models.Incident, error)
+
+	AddResponders(ctx context.Context, incidentID int, responders []models.IncidentResponder) error
+	GetResponder(ctx context.Context, incidentID, userID int) (models.IncidentResponder, error)
+	GetResponders(ctx context.Context, incidentID int) ([]models.IncidentResponder, error)
+	MarkResponderNotified(ctx context.Context, incidentID, userID int) error
+	AcknowledgeIncident(ctx context.Context, incidentID, userID int) error
+
+	AddIncidentEvent(ctx context.Context, event models.IncidentEvent) error
+	GetIncidentEvents(ctx context.Context, incidentID int) ([]models.IncidentEvent, error)
+}
+
+type sos struct {
+	db psql.DB
+}
+
+func NewSOSRepository(db psql.DB) SOS {
+	return &sos{db: db}
+}
+
+const incidentColumns = `id, user_id, latitude, longitude, message, status, created_at, resolved_at`
+
+func scanIncident(row pgx.Row) (models.Incident, error) {
+	var i models.Incident
+	err := row.Scan(&i.ID, &i.UserId, &i.Latitude, &i.Longitude, &i.Message, &i.Status, &i.CreatedAt, &i.ResolvedAt)
+	return i, err
+}
+
+func (s *sos) CreateIncident(ctx context.Context, incident models.Incident) (models.Incident, error) {
+	query := `INSERT INTO sos_incidents (user_id, latitude, longitude, message, status)
+	VALUES ($1, $2, $3, $4, $5) RETURNING ` + incidentColumns
+	created, err := scanIncident(s.db.QueryRow(ctx, query,
+		incident.UserId, incident.Latitude, incident.Longitude, incident.Message, StatusOpen))
+	if err != nil {
+		return models.Incident{}, fmt.Errorf("create incident: %w", err)
+	}
+	return created, nil
+}
+
+func (s *sos) GetIncident(ctx context.Context, id int) (models.Incident, error) {
+	query := `SELECT ` + incidentColumns + ` FROM sos_incidents WHERE id = $1`
+	incident, err := scanIncident(s.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return models.Incident{}, fmt.Errorf("get incident: %w", err)
+	}
+	return incident, nil
+}
+
+// GetIncidents returns the incidents the user raised or was alerted about,
+// newest first.
+func (s *sos) GetIncidents(ctx context.Context, userID, limit int) ([]models.Incident, error) {
+	query := `SELECT ` + incidentColumns + ` FROM sos_incidents
+	WHERE user_id = $1 OR id IN (SELECT incident_id FROM sos_responders WHERE user_id = $1)
+	ORDER BY created_at DESC, id DESC
+	LIMIT $2`
+	rows, err := s.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var incidents []models.Incident
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan incident: %w", err)
+		}
+		incidents = append(incidents, incident)
+	}
+	return incidents, rows.Err()
+}
+
+// ResolveIncident closes an open incident and returns it.
+func (s *sos) ResolveIncident(ctx context.Context, id int) (models.Incident, error) {
+	query := `UPDATE sos_incidents SET status = $2, resolved_at = NOW()
+	WHERE id = $1 AND status = $3 RETURNING ` + incidentColumns
+	incident, err := scanIncident(s.db.QueryRow(ctx, query, id, StatusResolved, StatusOpen))
+	if err != nil {
+		return models.Incident{}, fmt.Errorf("resolve incident: %w", err)
+	}
+	return incident, nil
+}
+
+func (s *sos) AddResponders(ctx context.Context, incidentID int, responders []models.IncidentResponder) error {
+	if len(responders) == 0 {
+		return nil
+	}
+
+	userIDs := make([]int, 0, len(responders))
+	distances := make([]float64, 0, len(responders))
+	for _, r := range responders {
+		userIDs = append(userIDs, r.UserId)
+		distances = append(distances, r.Distance)
+	}
+
+	query := `INSERT INTO sos_responders (incident_id, user_id, distance)
+	SELECT $1::INT, * FROM unnest($2::INT[], $3::DOUBLE PRECISION[])`
+	if _, err := s.db.Exec(ctx, query, incidentID, userIDs, distances); err != nil {
+		return fmt.Errorf("add responders: %w", err)
+	}
+	return nil
+}
+
+const responderColumns = `incident_id, user_id, distance, notified_at, acknowledged_at`
+
+func scanResponder(row pgx.Row) (models.IncidentResponder, error) {
+	var r models.IncidentResponder
+	err := row.Scan(&r.IncidentId, &r.UserId, &r.Distance, &r.NotifiedAt, &r.AcknowledgedAt)
+	return r, err
+}
+
+func (s *sos) GetResponder(ctx context.Context, incidentID, userID int) (models.IncidentResponder, error) {
+	query := `SELECT ` + responderColumns + ` FROM sos_responders WHERE incident_id = $1 AND user_id = $2`
+	responder, err := scanResponder(s.db.QueryRow(ctx, query, incidentID, userID))
+	if err != nil {
+		return models.IncidentResponder{}, fmt.Errorf("get responder: %w", err)
+	}
+	return responder, nil
+}
+
+// GetResponders returns the users alerted about the incident, nearest first.
+func (s *sos) GetResponders(ctx context.Context, incidentID int) ([]models.IncidentResponder, error) {
+	query := `SELECT ` + responderColumns + ` FROM sos_responders WHERE incident_id = $1 ORDER BY distance ASC, user_id ASC`
+	rows, err := s.db.Query(ctx, query, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("get responders: %w", err)
+	}
+	defer rows.Close()
+
+	var responders []models.IncidentResponder
+	for rows.Next() {
+		responder, err := scanResponder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan responder: %w", err)
+		}
+		responders = append(responders, responder)
+	}
+	return responders, rows.Err()
+}
+
+func (s *sos) MarkResponderNotified(ctx context.Context, incidentID, userID int) error {
+	query := `UPDATE sos_responders SET notified_at = NOW() WHERE incident_id = $1 AND user_id = $2 RETURNING user_id`
+	if err := s.db.QueryRow(ctx, query, incidentID, userID).Scan(&userID); err != nil {
+		return fmt.Errorf("mark responder notified: %w", err)
+	}
+	return nil
+}
+
+// AcknowledgeIncident records that the responder is on their way. It fails
+// with pgx.ErrNoRows when the responder already acknowledged the incident.
+func (s *sos) AcknowledgeIncident(ctx context.Context, incidentID, userID int) error {
+	query := `UPDATE sos_responders SET acknowledged_at = NOW()
+	WHERE incident_id = $1 AND user_id = $2 AND acknowledged_at IS NULL RETURNING user_id`
+	if err := s.db.QueryRow(ctx, query, incidentID, userID).Scan(&userID); err != nil {
+		return fmt.Errorf("acknowledge incident: %w", err)
+	}
+	return nil
+}
+
+func (s *sos) AddIncidentEvent(ctx context.Context, event models.IncidentEvent) error {
+	query := `INSERT INTO sos_events (incident_id, user_id, event, details) VALUES ($1, $2, $3, $4)`
+	if _, err := s.db.Exec(ctx, query, event.IncidentId, event.UserId, event.Event, event.Details); err != nil {
+		return fmt.Errorf("add incident event: %w", err)
+	}
+	return nil
+}
+
+// GetIncidentEvents returns the timeline of the incident in the order the
+// events happened.
+func (s *sos) GetIncidentEvents(ctx context.Context, incidentID int) ([]models.IncidentEvent, error) {
+	query := `SELECT id, incident_id, user_id, event, details, created_at FROM sos_events
+	WHERE incident_id = $1 ORDER BY id ASC`
+	rows, err := s.db.Query(ctx, query, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("get incident events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.IncidentEvent
+	for rows.Next() {
+		var e models.IncidentEvent
+		if err := rows.Scan(&e.ID, &e.IncidentId, &e.UserId, &e.Event, &e.Details, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan incident event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,205 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/askaroe/dockify-backend/pkg/psql"
+	"github.com/jackc/pgx/v5"
+)
+
+// LoginFailures counts consecutive failed logins per key, e.g. an account or a
+// client address, and blocks keys until a given time. A count starts over once
+// no failure was recorded for the window passed to ReserveLoginAttempt.
+//
+// An attempt is counted as failed before the credentials are checked, so
+// concurrent attempts can not all get past a block; a successful one is
+// taken back with ReleaseLoginAttempt.
+type LoginFailures interface {
+	// ReserveLoginAttempt counts an attempt for the key unless it is blocked
+	// and blocks it for delays[n-1] after the nth failure, or the last delay
+	// beyond them. It returns the new count, or 0 while the key is blocked.
+	ReserveLoginAttempt(ctx context.Context, key string, window time.Duration, delays []time.Duration) (int, error)
+	// ReleaseLoginAttempt takes back the attempt that brought the count to
+	// failures, lifting its block when no attempt was counted after it.
+	ReleaseLoginAttempt(ctx context.Context, key string, failures int) error
+	// LoginBlockedFor returns how long the key stays blocked, 0 when it is not.
+	LoginBlockedFor(ctx context.Context, key string) (time.Duration, error)
+	ClearLoginFailures(ctx context.Context, key string) error
+	PurgeLoginFailures(ctx context.Context, window time.Duration) (int64, error)
+}
+
+type loginFailures struct {
+	db psql.DB
+}
+
+// NewLoginFailureRepository keeps the counts in Postgres, shared by every
+// instance of the server.
+func NewLoginFailureRepository(db psql.DB) LoginFailures {
+	return &loginFailures{db: db}
+}
+
+func (l *loginFailures) ReserveLoginAttempt(ctx context.Context, key string, window time.Duration, delays []time.Duration) (int, error) {
+	seconds := make([]float64, len(delays))
+	for i, d := range delays {
+		seconds[i] = d.Seconds()
+	}
+
+	// the conflict update runs with the row locked, so concurrent attempts
+	// get consecutive counts and see the block set by the one before
+	query := `INSERT INTO login_failures (key, failures, last_failed_at, locked_until)
+	VALUES ($1, 1, NOW(), NOW() + make_interval(secs => ($3::float8[])[1]))
+	ON CONFLICT (key) DO UPDATE SET
+		failures = CASE WHEN login_failures.last_failed_at < NOW() - make_interval(secs => $2) THEN 1
+			ELSE login_failures.failures + 1 END,
+		last_failed_at = NOW(),
+		locked_until = NOW() + make_interval(secs => ($3::float8[])[LEAST(
+			CASE WHEN login_failures.last_failed_at < NOW() - make_interval(secs => $2) THEN 1
+				ELSE login_failures.failures + 1 END,
+			cardinality($3::float8[]))])
+	WHERE login_failures.locked_until IS NULL OR login_failures.locked_until <= NOW()
+	RETURNING failures`
+	var failures int
+	err := l.db.QueryRow(ctx, query, key, window.Seconds(), seconds).Scan(&failures)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reserve login attempt: %w", err)
+	}
+	return failures, nil
+}
+
+func (l *loginFailures) ReleaseLoginAttempt(ctx context.Context, key string, failures int) error {
+	query := `UPDATE login_failures SET failures = failures - 1,
+		locked_until = CASE WHEN failures = $2 THEN NULL ELSE locked_until END
+	WHERE key = $1 AND failures > 0`
+	if _, err := l.db.Exec(ctx, query, key, failures); err != nil {
+		return fmt.Errorf("release login attempt: %w", err)
+	}
+	return nil
+}
+
+func (l *loginFailures) LoginBlockedFor(ctx context.Context, key string) (time.Duration, error) {
+	query := `SELECT EXTRACT(EPOCH FROM locked_until - NOW())::float8 FROM login_failures
+	WHERE key = $1 AND locked_until > NOW()`
+	var seconds float64
+	err := l.db.QueryRow(ctx, query, key).Scan(&seconds)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get login block: %w", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+func (l *loginFailures) ClearLoginFailures(ctx context.Context, key string) error {
+	if _, err := l.db.Exec(ctx, `DELETE FROM login_failures WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("clear login failures: %w", err)
+	}
+	return nil
+}
+
+// PurgeLoginFailures deletes the counts that would start over anyway and are
+// no longer blocked.
+func (l *loginFailures) PurgeLoginFailures(ctx context.Context, window time.Duration) (int64, error) {
+	query := `DELETE FROM login_failures
+	WHERE last_failed_at < NOW() - make_interval(secs => $1) AND (locked_until IS NULL OR locked_until <= NOW())`
+	tag, err := l.db.Exec(ctx, query, window.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("purge login failures: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+type memoryLoginFailure struct {
+	failures     int
+	lastFailedAt time.Time
+	lockedUntil  time.Time
+}
+
+type memoryLoginFailures struct {
+	mu   sync.Mutex
+	keys map[string]memoryLoginFailure
+}
+
+// NewMemoryLoginFailures keeps the counts in process memory. They are lost on
+// restart and every instance counts on its own, so it only suits a single
+// instance deployment.
+func NewMemoryLoginFailures() LoginFailures {
+	return &memoryLoginFailures{keys: make(map[string]memoryLoginFailure)}
+}
+
+func (m *memoryLoginFailures) ReserveLoginAttempt(_ context.Context, key string, window time.Duration, delays []time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	f := m.keys[key]
+	if now.Before(f.lockedUntil) {
+		return 0, nil
+	}
+	if now.Sub(f.lastFailedAt) > window {
+		f.failures = 0
+	}
+	f.failures++
+	f.lastFailedAt = now
+	if len(delays) > 0 {
+		f.lockedUntil = now.Add(delays[min(f.failures, len(delays))-1])
+	}
+	m.keys[key] = f
+	return f.failures, nil
+}
+
+func (m *memoryLoginFailures) ReleaseLoginAttempt(_ context.Context, key string, failures int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.keys[key]
+	if !ok || f.failures == 0 {
+		return nil
+	}
+	if f.failures == failures {
+		f.lockedUntil = time.Time{}
+	}
+	f.failures--
+	m.keys[key] = f
+	return nil
+}
+
+func (m *memoryLoginFailures) LoginBlockedFor(_ context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if d := time.Until(m.keys[key].lockedUntil); d > 0 {
+		return d, nil
+	}
+	return 0, nil
+}
+
+func (m *memoryLoginFailures) ClearLoginFailures(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.keys, key)
+	return nil
+}
+
+func (m *memoryLoginFailures) PurgeLoginFailures(_ context.Context, window time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for key, f := range m.keys {
+		if now.Sub(f.lastFailedAt) > window && !now.Before(f.lockedUntil) {
+			delete(m.keys, key)
+			n++
+		}
+	}
+	return n, nil
+}
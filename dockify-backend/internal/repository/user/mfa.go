@@ -0,0 +1,109 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+func (u *user) GetMFA(ctx context.Context, userID int) (models.MFA, error) {
+	query := `SELECT user_id, secret, enabled_at, last_used_step, created_at FROM user_mfa WHERE user_id = $1`
+	var mfa models.MFA
+	err := u.db.QueryRow(ctx, query, userID).Scan(&mfa.UserId, &mfa.Secret, &mfa.EnabledAt, &mfa.LastUsedStep, &mfa.CreatedAt)
+	if err != nil {
+		return models.MFA{}, fmt.Errorf("get mfa: %w", err)
+	}
+	return mfa, nil
+}
+
+// SetupMFA stores a new secret awaiting confirmation, replacing an earlier
+// unconfirmed one. It returns pgx.ErrNoRows when the user already has MFA
+// enabled.
+func (u *user) SetupMFA(ctx context.Context, userID int, secret string) error {
+	query := `INSERT INTO user_mfa (user_id, secret) VALUES ($1, $2)
+	ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+	WHERE user_mfa.enabled_at IS NULL
+	RETURNING user_id`
+	if err := u.db.QueryRow(ctx, query, userID, secret).Scan(&userID); err != nil {
+		return fmt.Errorf("setup mfa: %w", err)
+	}
+	return nil
+}
+
+func (u *user) EnableMFA(ctx context.Context, userID int) error {
+	query := `UPDATE user_mfa SET enabled_at = NOW() WHERE user_id = $1 AND enabled_at IS NULL RETURNING user_id`
+	if err := u.db.QueryRow(ctx, query, userID).Scan(&userID); err != nil {
+		return fmt.Errorf("enable mfa: %w", err)
+	}
+	return nil
+}
+
+// UseMFAStep records that the code of step was accepted. It reports false
+// when a code of that step or a later one was accepted before, so a code can
+// not be replayed.
+func (u *user) UseMFAStep(ctx context.Context, userID int, step int64) (bool, error) {
+	query := `UPDATE user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2 RETURNING user_id`
+	err := u.db.QueryRow(ctx, query, userID, step).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("use mfa step: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteMFA removes the second factor and its recovery codes.
+func (u *user) DeleteMFA(ctx context.Context, userID int) error {
+	if _, err := u.db.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete recovery codes: %w", err)
+	}
+	if _, err := u.db.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete mfa: %w", err)
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes drops the user's recovery codes and stores the hashes
+// of new ones.
+func (u *user) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	if _, err := u.db.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete recovery codes: %w", err)
+	}
+
+	query := `INSERT INTO mfa_recovery_codes (user_id, code_hash) SELECT $1, unnest($2::text[])`
+	if _, err := u.db.Exec(ctx, query, userID, codeHashes); err != nil {
+		return fmt.Errorf("create recovery codes: %w", err)
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code of the user as used and
+// reports whether there was one.
+func (u *user) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	query := `UPDATE mfa_recovery_codes SET used_at = NOW()
+	WHERE id = (SELECT id FROM mfa_recovery_codes WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL LIMIT 1)
+		AND used_at IS NULL
+	RETURNING id`
+	var id int
+	err := u.db.QueryRow(ctx, query, userID, codeHash).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("use recovery code: %w", err)
+	}
+	return true, nil
+}
+
+func (u *user) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	query := `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	var n int
+	if err := u.db.QueryRow(ctx, query, userID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count recovery codes: %w", err)
+	}
+	return n, nil
+}
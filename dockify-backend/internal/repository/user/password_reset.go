@@ -0,0 +1,41 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+func (u *user) CreatePasswordResetToken(ctx context.Context, req models.PasswordResetToken, ttl time.Duration) error {
+	query := `INSERT INTO password_reset_tokens (user_id, token_hash, ip_address, expires_at)
+	VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))`
+	if _, err := u.db.Exec(ctx, query, req.UserId, req.TokenHash, req.IPAddress, ttl.Seconds()); err != nil {
+		return fmt.Errorf("create password reset token: %w", err)
+	}
+	return nil
+}
+
+// UsePasswordResetToken marks the token as used and returns its user. It
+// returns pgx.ErrNoRows when the token is unknown, expired or already used, so
+// a token works only once even under concurrent requests.
+func (u *user) UsePasswordResetToken(ctx context.Context, tokenHash string) (int, error) {
+	query := `UPDATE password_reset_tokens SET used_at = NOW()
+	WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+	RETURNING user_id`
+	var userID int
+	if err := u.db.QueryRow(ctx, query, tokenHash).Scan(&userID); err != nil {
+		return 0, fmt.Errorf("use password reset token: %w", err)
+	}
+	return userID, nil
+}
+
+// InvalidatePasswordResetTokens uses up every outstanding token of the user.
+func (u *user) InvalidatePasswordResetTokens(ctx context.Context, userID int) error {
+	query := `UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`
+	if _, err := u.db.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("invalidate password reset tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+func (u *user) CreateSession(ctx context.Context, req models.Session) (int, error) {
+	query := `INSERT INTO sessions (user_id, refresh_token_hash, device_name, platform, ip_address) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := u.db.QueryRow(ctx, query, req.UserId, req.RefreshTokenHash, req.DeviceName, req.Platform, req.IPAddress).Scan(&req.ID)
+	if err != nil {
+		return 0, fmt.Errorf("create session: %w", err)
+	}
+
+	return req.ID, nil
+}
+
+func (u *user) GetSessionByID(ctx context.Context, id int) (models.Session, error) {
+	var session models.Session
+	query := `SELECT id, user_id, refresh_token_hash, device_name, platform, ip_address, created_at, last_seen_at, revoked_at FROM sessions WHERE id = $1`
+	err := u.db.QueryRow(ctx, query, id).Scan(&session.ID, &session.UserId, &session.RefreshTokenHash, &session.DeviceName,
+		&session.Platform, &session.IPAddress, &session.CreatedAt, &session.LastSeenAt, &session.RevokedAt)
+	if err != nil {
+		return models.Session{}, fmt.Errorf("get session by id: %w", err)
+	}
+	return session, nil
+}
+
+func (u *user) GetActiveSessions(ctx context.Context, userID int) ([]models.Session, error) {
+	query := `SELECT id, user_id, device_name, platform, ip_address, created_at, last_seen_at
+	FROM sessions
+	WHERE user_id = $1 AND revoked_at IS NULL
+	ORDER BY last_seen_at DESC`
+	rows, err := u.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get active sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.UserId, &session.DeviceName, &session.Platform,
+			&session.IPAddress, &session.CreatedAt, &session.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// RotateSessionToken swaps the stored refresh token hash only if it still
+// matches currentHash, so two concurrent refreshes with the same token cannot
+// both succeed. It reports whether the swap happened.
+func (u *user) RotateSessionToken(ctx context.Context, id int, currentHash, newHash, ipAddress string) (bool, error) {
+	query := `UPDATE sessions SET refresh_token_hash = $3, ip_address = $4, last_seen_at = NOW()
+	WHERE id = $1 AND refresh_token_hash = $2 AND revoked_at IS NULL`
+	tag, err := u.db.Exec(ctx, query, id, currentHash, newHash, ipAddress)
+	if err != nil {
+		return false, fmt.Errorf("rotate session token: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (u *user) RevokeSession(ctx context.Context, userID, id int) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL RETURNING id`
+	err := u.db.QueryRow(ctx, query, id, userID).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+func (u *user) RevokeUserSessions(ctx context.Context, userID int) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := u.db.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("revoke user sessions: %w", err)
+	}
+	return nil
+}
+
+// RevokeOtherSessions revokes every session of the user except keepID.
+func (u *user) RevokeOtherSessions(ctx context.Context, userID, keepID int) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`
+	_, err := u.db.Exec(ctx, query, userID, keepID)
+	if err != nil {
+		return fmt.Errorf("revoke other sessions: %w", err)
+	}
+	return nil
+}
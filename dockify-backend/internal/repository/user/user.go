@@ -3,28 +3,62 @@ package user
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/askaroe/dockify-backend/internal/models"
 	"github.com/askaroe/dockify-backend/pkg/psql"
+	"github.com/jackc/pgx/v5"
 )
 
 type User interface {
 	CreateUser(ctx context.Context, req models.User) (int, error)
 	GetUserByEmail(ctx context.Context, email string) (models.User, error)
 	GetUserByID(ctx context.Context, id int) (models.User, error)
+	UpdateProfile(ctx context.Context, req models.User) (models.User, error)
+	SetPasswordHash(ctx context.Context, userID int, hash string) error
+	MarkEmailVerified(ctx context.Context, userID int, email string) error
+	ScheduleDeletion(ctx context.Context, userID, graceDays int) (time.Time, error)
+	CancelDeletion(ctx context.Context, userID int) error
+	PurgeDeletedUsers(ctx context.Context) (int64, error)
+	GetLocationSharing(ctx context.Context, userIDs []int) (map[int]string, error)
+	SetLocationSharing(ctx context.Context, userID int, mode string) error
+	GetSOSResponder(ctx context.Context, userID int) (bool, error)
+	SetSOSResponder(ctx context.Context, userID int, enabled bool) error
+	GetSOSResponderIDs(ctx context.Context, userIDs []int) ([]int, error)
+
+	CreateSession(ctx context.Context, req models.Session) (int, error)
+	GetSessionByID(ctx context.Context, id int) (models.Session, error)
+	GetActiveSessions(ctx context.Context, userID int) ([]models.Session, error)
+	RotateSessionToken(ctx context.Context, id int, currentHash, newHash, ipAddress string) (bool, error)
+	RevokeSession(ctx context.Context, userID, id int) error
+	RevokeUserSessions(ctx context.Context, userID int) error
+	RevokeOtherSessions(ctx context.Context, userID, keepID int) error
+
+	CreatePasswordResetToken(ctx context.Context, req models.PasswordResetToken, ttl time.Duration) error
+	UsePasswordResetToken(ctx context.Context, tokenHash string) (int, error)
+	InvalidatePasswordResetTokens(ctx context.Context, userID int) error
+
+	GetMFA(ctx context.Context, userID int) (models.MFA, error)
+	SetupMFA(ctx context.Context, userID int, secret string) error
+	EnableMFA(ctx context.Context, userID int) error
+	UseMFAStep(ctx context.Context, userID int, step int64) (bool, error)
+	DeleteMFA(ctx context.Context, userID int) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
 }
 
 type user struct {
-	db *psql.Client
+	db psql.DB
 }
 
-func NewUserRepository(db *psql.Client) User {
+func NewUserRepository(db psql.DB) User {
 	return &user{db: db}
 }
 
 func (u *user) CreateUser(ctx context.Context, req models.User) (int, error) {
-	query := `INSERT INTO users (username, first_name, last_name, email, password_hash) VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	err := u.db.QueryRow(ctx, query, req.Username, req.FirstName, req.LastName, req.Email, req.PasswordHash).Scan(&req.ID)
+	query := `INSERT INTO users (username, first_name, last_name, email, password_hash, birth_date) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err := u.db.QueryRow(ctx, query, req.Username, req.FirstName, req.LastName, req.Email, req.PasswordHash, req.BirthDate).Scan(&req.ID)
 	if err != nil {
 		return 0, fmt.Errorf("create user: %w", err)
 	}
@@ -32,10 +66,19 @@ func (u *user) CreateUser(ctx context.Context, req models.User) (int, error) {
 	return req.ID, nil
 }
 
-func (u *user) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+const userColumns = `id, username, first_name, last_name, email, password_hash, birth_date, created_at,
+	email_verified_at, deletion_scheduled_at`
+
+func scanUser(row pgx.Row) (models.User, error) {
 	var user models.User
-	query := `SELECT id, username, first_name, last_name, email, password_hash, created_at FROM users WHERE email = $1`
-	err := u.db.QueryRow(ctx, query, email).Scan(&user.ID, &user.Username, &user.FirstName, &user.LastName, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.FirstName, &user.LastName, &user.Email, &user.PasswordHash,
+		&user.BirthDate, &user.CreatedAt, &user.EmailVerifiedAt, &user.DeletionScheduledAt)
+	return user, err
+}
+
+func (u *user) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(email) = LOWER($1)`
+	user, err := scanUser(u.db.QueryRow(ctx, query, email))
 	if err != nil {
 		return models.User{}, fmt.Errorf("get user by email: %w", err)
 	}
@@ -43,11 +86,142 @@ func (u *user) GetUserByEmail(ctx context.Context, email string) (models.User, e
 }
 
 func (u *user) GetUserByID(ctx context.Context, id int) (models.User, error) {
-	var user models.User
-	query := `SELECT id, username, first_name, last_name, email, password_hash, created_at FROM users WHERE id = $1`
-	err := u.db.QueryRow(ctx, query, id).Scan(&user.ID, &user.Username, &user.FirstName, &user.LastName, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	user, err := scanUser(u.db.QueryRow(ctx, query, id))
 	if err != nil {
 		return models.User{}, fmt.Errorf("get user by id: %w", err)
 	}
 	return user, nil
 }
+
+// UpdateProfile saves the profile fields of req. Changing the email clears
+// email_verified_at, the new address has to be verified again.
+func (u *user) UpdateProfile(ctx context.Context, req models.User) (models.User, error) {
+	query := `UPDATE users SET username = $2, first_name = $3, last_name = $4, email = $5, birth_date = $6,
+	email_verified_at = CASE WHEN email = $5 THEN email_verified_at END
+	WHERE id = $1
+	RETURNING ` + userColumns
+	user, err := scanUser(u.db.QueryRow(ctx, query, req.ID, req.Username, req.FirstName, req.LastName, req.Email, req.BirthDate))
+	if err != nil {
+		return models.User{}, fmt.Errorf("update profile: %w", err)
+	}
+	return user, nil
+}
+
+func (u *user) SetPasswordHash(ctx context.Context, userID int, hash string) error {
+	query := `UPDATE users SET password_hash = $2 WHERE id = $1 RETURNING id`
+	if err := u.db.QueryRow(ctx, query, userID, hash).Scan(&userID); err != nil {
+		return fmt.Errorf("set password hash: %w", err)
+	}
+	return nil
+}
+
+// MarkEmailVerified verifies the user's email if it still is email. It
+// returns pgx.ErrNoRows when the email has changed since.
+func (u *user) MarkEmailVerified(ctx context.Context, userID int, email string) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $1 AND LOWER(email) = LOWER($2) RETURNING id`
+	if err := u.db.QueryRow(ctx, query, userID, email).Scan(&userID); err != nil {
+		return fmt.Errorf("mark email verified: %w", err)
+	}
+	return nil
+}
+
+// ScheduleDeletion marks the account for deletion after graceDays and returns
+// when it will be purged. Scheduling again keeps the earlier date.
+func (u *user) ScheduleDeletion(ctx context.Context, userID, graceDays int) (time.Time, error) {
+	query := `UPDATE users SET deletion_scheduled_at = COALESCE(deletion_scheduled_at, NOW() + make_interval(days => $2))
+	WHERE id = $1
+	RETURNING deletion_scheduled_at`
+	var at time.Time
+	if err := u.db.QueryRow(ctx, query, userID, graceDays).Scan(&at); err != nil {
+		return time.Time{}, fmt.Errorf("schedule deletion: %w", err)
+	}
+	return at, nil
+}
+
+func (u *user) CancelDeletion(ctx context.Context, userID int) error {
+	query := `UPDATE users SET deletion_scheduled_at = NULL WHERE id = $1`
+	if _, err := u.db.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("cancel deletion: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeletedUsers deletes the accounts whose grace period has passed. Their
+// data goes with them through ON DELETE CASCADE.
+func (u *user) PurgeDeletedUsers(ctx context.Context) (int64, error) {
+	query := `DELETE FROM users WHERE deletion_scheduled_at <= NOW()`
+	tag, err := u.db.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("purge deleted users: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetLocationSharing returns the location sharing mode of each of the users
+// that exists.
+func (u *user) GetLocationSharing(ctx context.Context, userIDs []int) (map[int]string, error) {
+	query := `SELECT id, location_sharing FROM users WHERE id = ANY($1)`
+	rows, err := u.db.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get location sharing: %w", err)
+	}
+	defer rows.Close()
+
+	modes := make(map[int]string, len(userIDs))
+	for rows.Next() {
+		var id int
+		var mode string
+		if err := rows.Scan(&id, &mode); err != nil {
+			return nil, fmt.Errorf("scan location sharing: %w", err)
+		}
+		modes[id] = mode
+	}
+	return modes, rows.Err()
+}
+
+func (u *user) SetLocationSharing(ctx context.Context, userID int, mode string) error {
+	query := `UPDATE users SET location_sharing = $2 WHERE id = $1 RETURNING id`
+	if err := u.db.QueryRow(ctx, query, userID, mode).Scan(&userID); err != nil {
+		return fmt.Errorf("set location sharing: %w", err)
+	}
+	return nil
+}
+
+func (u *user) GetSOSResponder(ctx context.Context, userID int) (bool, error) {
+	var enabled bool
+	query := `SELECT sos_responder FROM users WHERE id = $1`
+	if err := u.db.QueryRow(ctx, query, userID).Scan(&enabled); err != nil {
+		return false, fmt.Errorf("get sos responder: %w", err)
+	}
+	return enabled, nil
+}
+
+func (u *user) SetSOSResponder(ctx context.Context, userID int, enabled bool) error {
+	query := `UPDATE users SET sos_responder = $2 WHERE id = $1 RETURNING id`
+	if err := u.db.QueryRow(ctx, query, userID, enabled).Scan(&userID); err != nil {
+		return fmt.Errorf("set sos responder: %w", err)
+	}
+	return nil
+}
+
+// GetSOSResponderIDs returns those of the users who opted in to SOS alerts and
+// are not scheduled for deletion.
+func (u *user) GetSOSResponderIDs(ctx context.Context, userIDs []int) ([]int, error) {
+	query := `SELECT id FROM users WHERE id = ANY($1) AND sos_responder AND deletion_scheduled_at IS NULL`
+	rows, err := u.db.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get sos responder ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan sos responder id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
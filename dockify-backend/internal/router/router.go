@@ -1,15 +1,34 @@
 package router
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
 	"github.com/askaroe/dockify-backend/internal/handlers"
+	"github.com/askaroe/dockify-backend/pkg/token"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func NewRouter(handler *handlers.Handler) *gin.Engine {
+// Sessions tells whether the session an access token was issued for is still
+// signed in.
+type Sessions interface {
+	CheckSession(ctx context.Context, userID, sessionID int) error
+}
+
+// NewRouter takes the client address from X-Forwarded-For only on requests
+// coming from one of trustedProxies.
+func NewRouter(handler *handlers.Handler, tokens *token.Manager, sessions Sessions, trustedProxies []string) (*gin.Engine, error) {
 	r := gin.New()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("set trusted proxies: %w", err)
+	}
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 	r.Use(cors.New(cors.Config{
@@ -25,25 +44,154 @@ func NewRouter(handler *handlers.Handler) *gin.Engine {
 	{
 		api.POST("/register", handler.Register)
 		api.POST("/login", handler.Login)
-		api.POST("/metrics", handler.Health.CreateHealthMetrics)
-		api.GET("/metrics", handler.Health.GetHealthMetrics)
-		api.GET("/recommendation", handlers.GetRecommendation)
+		api.POST("/login/mfa", handler.LoginMFA)
+		api.POST("/token/refresh", handler.RefreshToken)
+		api.POST("/email/verify", handler.VerifyEmail)
+		api.POST("/password/forgot", handler.ForgotPassword)
+		api.POST("/password/reset", handler.ResetPassword)
 
-		location := api.Group("/location")
+		authorized := api.Group("", AuthMiddleware(tokens, sessions))
 		{
-			location.POST("/nearest", handler.Location.GetNearestUsers)
-		}
+			authorized.POST("/logout", handler.Logout)
+			authorized.POST("/logout/all", handler.LogoutAll)
+			authorized.GET("/sessions", handler.GetSessions)
+			authorized.DELETE("/sessions/:id", handler.DeleteSession)
 
-		hospitals := api.Group("/hospitals")
-		{
-			hospitals.POST("/nearest", handlers.GetNearestHospitals)
+			authorized.GET("/me", handler.GetProfile)
+			authorized.PATCH("/me", handler.UpdateProfile)
+			authorized.DELETE("/me", handler.DeleteAccount)
+			authorized.POST("/me/password", handler.ChangePassword)
+			authorized.POST("/me/email/verification", handler.SendEmailVerification)
+			authorized.GET("/me/mfa", handler.GetMFAStatus)
+			authorized.POST("/me/mfa/setup", handler.SetupMFA)
+			authorized.POST("/me/mfa/enable", handler.EnableMFA)
+			authorized.POST("/me/mfa/disable", handler.DisableMFA)
+			authorized.POST("/me/mfa/recovery-codes", handler.RegenerateRecoveryCodes)
+
+			authorized.POST("/metrics", handler.Health.CreateHealthMetrics)
+			authorized.GET("/metrics", handler.Health.GetHealthMetrics)
+			authorized.GET("/metrics/aggregate", handler.Health.AggregateHealthMetrics)
+			authorized.GET("/metrics/types", handler.Health.GetMetricTypes)
+
+			bloodPressure := authorized.Group("/metrics/blood-pressure")
+			{
+				bloodPressure.POST("", handler.Health.CreateBloodPressure)
+				bloodPressure.GET("", handler.Health.GetBloodPressureReadings)
+				bloodPressure.GET("/:id", handler.Health.GetBloodPressure)
+				bloodPressure.PUT("/:id", handler.Health.UpdateBloodPressure)
+				bloodPressure.DELETE("/:id", handler.Health.DeleteBloodPressure)
+			}
+
+			workouts := authorized.Group("/workouts")
+			{
+				workouts.POST("", handler.Health.CreateWorkout)
+				workouts.GET("", handler.Health.GetWorkouts)
+				workouts.GET("/:id", handler.Health.GetWorkout)
+				workouts.PUT("/:id", handler.Health.UpdateWorkout)
+				workouts.DELETE("/:id", handler.Health.DeleteWorkout)
+			}
+
+			sleepSessions := authorized.Group("/sleep-sessions")
+			{
+				sleepSessions.POST("", handler.Health.CreateSleepSession)
+				sleepSessions.GET("", handler.Health.GetSleepSessions)
+				sleepSessions.GET("/:id", handler.Health.GetSleepSession)
+				sleepSessions.PUT("/:id", handler.Health.UpdateSleepSession)
+				sleepSessions.DELETE("/:id", handler.Health.DeleteSleepSession)
+			}
+
+			predict := authorized.Group("/predict")
+			{
+				predict.POST("/sleep", handler.Prediction.PredictSleep)
+				predict.POST("/sleep/me", handler.Prediction.PredictSleepFromData)
+				predict.POST("/lifestyle", handler.Prediction.PredictLifestyle)
+				predict.POST("/lifestyle/me", handler.Prediction.PredictLifestyleFromData)
+			}
+			authorized.GET("/predictions", handler.Prediction.GetPredictions)
+
+			recommendations := authorized.Group("/recommendation")
+			{
+				recommendations.GET("", handler.Recommendation.GetRecommendations)
+				recommendations.POST("/:id/read", handler.Recommendation.MarkRecommendationRead)
+				recommendations.POST("/:id/dismiss", handler.Recommendation.DismissRecommendation)
+			}
+
+			location := authorized.Group("/location")
+			{
+				location.POST("/nearest", handler.Location.GetNearestUsers)
+				location.POST("/hospitals", handler.Hospital.GetNearestHospitals) // path used by the mobile client
+				location.GET("/sharing", handler.Location.GetLocationSharing)
+				location.PUT("/sharing", handler.Location.UpdateLocationSharing)
+				location.GET("/history", handler.Location.GetLocationHistory)
+				location.GET("/history/visits", handler.Location.GetVisits)
+				location.GET("/history/trips", handler.Location.GetTrips)
+				location.GET("/history/export", handler.Location.ExportLocationHistory)
+			}
+
+			friends := authorized.Group("/friends")
+			{
+				friends.GET("", handler.Friend.GetFriends)
+				friends.POST("/:id", handler.Friend.SendFriendRequest)
+				friends.POST("/:id/accept", handler.Friend.AcceptFriendRequest)
+				friends.DELETE("/:id", handler.Friend.RemoveFriend)
+			}
+
+			blocks := authorized.Group("/blocks")
+			{
+				blocks.GET("", handler.Friend.GetBlockedUsers)
+				blocks.POST("/:id", handler.Friend.BlockUser)
+				blocks.DELETE("/:id", handler.Friend.UnblockUser)
+			}
+
+			alerts := authorized.Group("/alerts")
+			{
+				alerts.GET("", handler.Health.GetAlerts)
+				alerts.POST("/rules", handler.Health.CreateAlertRule)
+				alerts.GET("/rules", handler.Health.GetAlertRules)
+				alerts.GET("/rules/:id", handler.Health.GetAlertRule)
+				alerts.PUT("/rules/:id", handler.Health.UpdateAlertRule)
+				alerts.DELETE("/rules/:id", handler.Health.DeleteAlertRule)
+			}
+
+			sos := authorized.Group("/sos")
+			{
+				sos.POST("", handler.SOS.CreateIncident)
+				sos.GET("", handler.SOS.GetIncidents)
+				sos.GET("/settings", handler.SOS.GetSOSSettings)
+				sos.PUT("/settings", handler.SOS.UpdateSOSSettings)
+				sos.GET("/:id", handler.SOS.GetIncident)
+				sos.GET("/:id/timeline", handler.SOS.GetIncidentTimeline)
+				sos.POST("/:id/acknowledge", handler.SOS.AcknowledgeIncident)
+				sos.POST("/:id/resolve", handler.SOS.ResolveIncident)
+			}
+
+			care := authorized.Group("/care")
+			{
+				care.POST("/invitations", handler.Care.CreateInvitation)
+				care.GET("/invitations", handler.Care.GetInvitations)
+				care.POST("/invitations/:id/accept", handler.Care.AcceptInvitation)
+				care.POST("/invitations/:id/decline", handler.Care.DeclineInvitation)
+				care.DELETE("/invitations/:id", handler.Care.CancelInvitation)
+				care.GET("/grants", handler.Care.GetGrants)
+				care.PUT("/grants/:id", handler.Care.UpdateGrant)
+				care.DELETE("/grants/:id", handler.Care.RevokeGrant)
+				care.GET("/access-log", handler.Care.GetAccessLog)
+				care.GET("/patients/:id/metrics", handler.Health.GetPatientMetrics)
+				care.GET("/patients/:id/metrics/aggregate", handler.Health.AggregatePatientMetrics)
+				care.GET("/patients/:id/location/history", handler.Location.GetPatientLocationHistory)
+			}
+
+			hospitals := authorized.Group("/hospitals")
+			{
+				hospitals.POST("/nearest", handler.Hospital.GetNearestHospitals)
+			}
 		}
 	}
 
 	r.GET("/health", handlers.HealthCheck)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	return r
+	return r, nil
 }
 
 func SetJSONContentType() gin.HandlerFunc {
@@ -52,3 +200,37 @@ func SetJSONContentType() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AuthMiddleware validates the bearer access token, rejects it once its session
+// was signed out, and stores the caller's user and session ids in the gin
+// context.
+func AuthMiddleware(tokens *token.Manager, sessions Sessions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || accessToken == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, entity.ErrorMessage{Message: "missing bearer token"})
+			return
+		}
+
+		claims, err := tokens.Parse(accessToken, token.TypeAccess)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, entity.ErrorMessage{Message: "invalid token"})
+			return
+		}
+
+		err = sessions.CheckSession(c.Request.Context(), claims.UserID, claims.SessionID)
+		if errors.Is(err, entity.ErrSessionClosed) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, entity.ErrorMessage{Message: "session is closed"})
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, entity.ErrorMessage{Message: "failed to check session"})
+			return
+		}
+
+		c.Set(entity.ContextKeyUserID, claims.UserID)
+		c.Set(entity.ContextKeySessionID, claims.SessionID)
+		c.Next()
+	}
+}
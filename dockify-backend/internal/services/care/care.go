@@ -0,0 +1,584 @@
+package care
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/notifier"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	carerepo "github.com/askaroe/dockify-backend/internal/repository/care"
+	"github.com/askaroe/dockify-backend/internal/services/health"
+	"github.com/askaroe/dockify-backend/internal/services/location"
+	"github.com/askaroe/dockify-backend/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	invitationTTL    = 7 * 24 * time.Hour
+	invitationsLimit = 100
+
+	defaultAccessLogLimit = 50
+	maxAccessLogLimit     = 200
+
+	// invitationExpired is reported for pending invitations past expires_at;
+	// it is never stored.
+	invitationExpired = "expired"
+)
+
+// Care lets a user share read access to their data with caregivers. Every
+// read of another user's data goes through this service, which checks the
+// active grant between the two and records the access.
+type Care interface {
+	CreateInvitation(ctx context.Context, request entity.CareInvitationRequest) (models.CareInvitation, error)
+	GetInvitations(ctx context.Context, userID int) ([]models.CareInvitation, error)
+	AcceptInvitation(ctx context.Context, userID, invitationID int) (models.Grant, error)
+	DeclineInvitation(ctx context.Context, userID, invitationID int) error
+	CancelInvitation(ctx context.Context, userID, invitationID int) error
+
+	GetGrants(ctx context.Context, userID int) ([]models.Grant, error)
+	UpdateGrant(ctx context.Context, userID, grantID int, request entity.GrantScopeRequest) (models.Grant, error)
+	RevokeGrant(ctx context.Context, userID, grantID int) error
+	GetAccessLog(ctx context.Context, userID, limit int) ([]models.GrantAccess, error)
+
+	GetPatientMetrics(ctx context.Context, granteeID int, query entity.HealthMetricsQuery) (entity.HealthMetricsPage, error)
+	AggregatePatientMetrics(ctx context.Context, granteeID int, query entity.MetricAggregateQuery) (entity.MetricAggregateResponse, error)
+	GetPatientLocationHistory(ctx context.Context, granteeID int, query entity.TimeRangeQuery) ([]entity.LocationPoint, error)
+}
+
+type care struct {
+	repo     *repository.Repository
+	health   health.Health
+	location location.Location
+	notifier notifier.Notifier
+	logger   *utils.Logger
+}
+
+func NewCareService(repo *repository.Repository, healthService health.Health, locationService location.Location, n notifier.Notifier, logger *utils.Logger) Care {
+	return &care{repo: repo, health: healthService, location: locationService, notifier: n, logger: logger}
+}
+
+// CreateInvitation invites the owner of an email address to read the caller's
+// data within the requested scope. The invitee is told in the app when they
+// already have an account, and by email.
+func (c *care) CreateInvitation(ctx context.Context, request entity.CareInvitationRequest) (models.CareInvitation, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(request.Email))
+	if err != nil {
+		return models.CareInvitation{}, fmt.Errorf("%w: invalid email address", entity.ErrInvalidParam)
+	}
+	email := strings.ToLower(addr.Address)
+
+	metricTypes, err := grantScope(request.MetricTypes, request.Location)
+	if err != nil {
+		return models.CareInvitation{}, err
+	}
+
+	owner, err := c.repo.User.GetUserByID(ctx, request.UserId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.CareInvitation{}, entity.ErrNotFound
+	}
+	if err != nil {
+		return models.CareInvitation{}, fmt.Errorf("create invitation: %w", err)
+	}
+	if strings.EqualFold(owner.Email, email) {
+		return models.CareInvitation{}, fmt.Errorf("%w: you cannot invite yourself", entity.ErrInvalidParam)
+	}
+
+	invitee, err := c.repo.User.GetUserByEmail(ctx, email)
+	registered := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.CareInvitation{}, fmt.Errorf("create invitation: %w", err)
+	}
+	if registered {
+		_, err := c.repo.Care.GetActiveGrant(ctx, owner.ID, invitee.ID)
+		if err == nil {
+			return models.CareInvitation{}, fmt.Errorf("%w: this user already has access, update the grant instead", entity.ErrInvalidParam)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return models.CareInvitation{}, fmt.Errorf("create invitation: %w", err)
+		}
+	}
+
+	pending, err := c.repo.Care.HasPendingInvitation(ctx, owner.ID, email)
+	if err != nil {
+		return models.CareInvitation{}, fmt.Errorf("create invitation: %w", err)
+	}
+	if pending {
+		return models.CareInvitation{}, fmt.Errorf("%w: an invitation to this email is already pending", entity.ErrInvalidParam)
+	}
+
+	invitation, err := c.repo.Care.CreateInvitation(ctx, models.CareInvitation{
+		OwnerId:     owner.ID,
+		Email:       email,
+		MetricTypes: metricTypes,
+		Location:    request.Location,
+		ExpiresAt:   time.Now().UTC().Add(invitationTTL),
+	})
+	if err != nil {
+		return models.CareInvitation{}, fmt.Errorf("create invitation: %w", err)
+	}
+
+	msg := notifier.Message{
+		Subject: "Care team invitation",
+		Body: fmt.Sprintf("%s invited you to their care team on Dockify. Open the app to accept the invitation before %s UTC.",
+			displayName(owner), invitation.ExpiresAt.Format("2006-01-02 15:04")),
+		Data:    map[string]string{"invitation_id": strconv.Itoa(invitation.ID)},
+		Channel: notifier.ChannelEmail,
+		To:      email,
+	}
+	messages := []notifier.Message{msg}
+	if registered {
+		msg.UserID, msg.Channel, msg.To = invitee.ID, "", ""
+		messages = append(messages, msg)
+	}
+	go c.notifyAll(context.WithoutCancel(ctx), messages)
+
+	return invitation, nil
+}
+
+// GetInvitations returns the invitations the user sent or received, newest
+// first. Received ones are left out until the user verified their email.
+func (c *care) GetInvitations(ctx context.Context, userID int) ([]models.CareInvitation, error) {
+	user, err := c.repo.User.GetUserByID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get invitations: %w", err)
+	}
+
+	// received invitations only show up once the email is verified
+	email := user.Email
+	if user.EmailVerifiedAt == nil {
+		email = ""
+	}
+	invitations, err := c.repo.Care.GetInvitations(ctx, userID, email, invitationsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("get invitations: %w", err)
+	}
+
+	now := time.Now()
+	for i := range invitations {
+		if expired(invitations[i], now) {
+			invitations[i].Status = invitationExpired
+		}
+	}
+	if invitations == nil {
+		invitations = []models.CareInvitation{}
+	}
+	return invitations, nil
+}
+
+// AcceptInvitation turns the invitation into a grant from its owner to the
+// caller, replacing the scope of an active grant between the two.
+func (c *care) AcceptInvitation(ctx context.Context, userID, invitationID int) (models.Grant, error) {
+	invitation, owner, err := c.invitation(ctx, userID, invitationID)
+	if err != nil {
+		return models.Grant{}, err
+	}
+	if owner {
+		return models.Grant{}, fmt.Errorf("%w: only the invitee can accept an invitation", entity.ErrForbidden)
+	}
+	if err := pending(invitation); err != nil {
+		return models.Grant{}, err
+	}
+
+	var grant models.Grant
+	err = c.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		// checks the invitee's email again so a change since loading the
+		// invitation can not slip through
+		if _, err := tx.Care.AcceptInvitation(ctx, invitationID, userID); err != nil {
+			return err
+		}
+		grant, err = tx.Care.UpsertGrant(ctx, models.Grant{
+			OwnerId:     invitation.OwnerId,
+			GranteeId:   userID,
+			MetricTypes: invitation.MetricTypes,
+			Location:    invitation.Location,
+		})
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Grant{}, fmt.Errorf("%w: invitation is no longer pending", entity.ErrInvalidParam)
+	}
+	if err != nil {
+		return models.Grant{}, fmt.Errorf("accept invitation: %w", err)
+	}
+
+	go c.notifyAll(context.WithoutCancel(ctx), []notifier.Message{{
+		UserID:  invitation.OwnerId,
+		Subject: "Care team invitation accepted",
+		Body:    fmt.Sprintf("%s accepted your invitation and can now see the data you shared.", invitation.Email),
+		Data:    map[string]string{"invitation_id": strconv.Itoa(invitationID), "grant_id": strconv.Itoa(grant.ID)},
+	}})
+	return grant, nil
+}
+
+func (c *care) DeclineInvitation(ctx context.Context, userID, invitationID int) error {
+	invitation, owner, err := c.invitation(ctx, userID, invitationID)
+	if err != nil {
+		return err
+	}
+	if owner {
+		return fmt.Errorf("%w: only the invitee can decline an invitation, cancel it instead", entity.ErrForbidden)
+	}
+	if err := pending(invitation); err != nil {
+		return err
+	}
+
+	_, err = c.repo.Care.RespondInvitation(ctx, invitationID, carerepo.InvitationDeclined, nil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: invitation is no longer pending", entity.ErrInvalidParam)
+	}
+	if err != nil {
+		return fmt.Errorf("decline invitation: %w", err)
+	}
+
+	go c.notifyAll(context.WithoutCancel(ctx), []notifier.Message{{
+		UserID:  invitation.OwnerId,
+		Subject: "Care team invitation declined",
+		Body:    fmt.Sprintf("%s declined your invitation.", invitation.Email),
+		Data:    map[string]string{"invitation_id": strconv.Itoa(invitationID)},
+	}})
+	return nil
+}
+
+// CancelInvitation withdraws a pending invitation. Only the user who sent it
+// can cancel it.
+func (c *care) CancelInvitation(ctx context.Context, userID, invitationID int) error {
+	invitation, owner, err := c.invitation(ctx, userID, invitationID)
+	if err != nil {
+		return err
+	}
+	if !owner {
+		return fmt.Errorf("%w: only the user who sent an invitation can cancel it", entity.ErrForbidden)
+	}
+	if invitation.Status != carerepo.InvitationPending {
+		return fmt.Errorf("%w: invitation is already %s", entity.ErrInvalidParam, invitation.Status)
+	}
+
+	_, err = c.repo.Care.RespondInvitation(ctx, invitationID, carerepo.InvitationCancelled, nil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: invitation is no longer pending", entity.ErrInvalidParam)
+	}
+	if err != nil {
+		return fmt.Errorf("cancel invitation: %w", err)
+	}
+	return nil
+}
+
+// GetGrants returns the active grants the user gave and received.
+func (c *care) GetGrants(ctx context.Context, userID int) ([]models.Grant, error) {
+	grants, err := c.repo.Care.GetGrants(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get grants: %w", err)
+	}
+	if grants == nil {
+		grants = []models.Grant{}
+	}
+	return grants, nil
+}
+
+// UpdateGrant replaces the scope of a grant. Only the user whose data is
+// shared can change it; an empty scope is rejected, revoke the grant instead.
+func (c *care) UpdateGrant(ctx context.Context, userID, grantID int, request entity.GrantScopeRequest) (models.Grant, error) {
+	grant, err := c.grant(ctx, userID, grantID)
+	if err != nil {
+		return models.Grant{}, err
+	}
+	if grant.OwnerId != userID {
+		return models.Grant{}, fmt.Errorf("%w: only the user whose data is shared can change a grant", entity.ErrForbidden)
+	}
+
+	grant.MetricTypes, err = grantScope(request.MetricTypes, request.Location)
+	if err != nil {
+		return models.Grant{}, err
+	}
+	grant.Location = request.Location
+
+	updated, err := c.repo.Care.UpdateGrant(ctx, grant)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Grant{}, entity.ErrNotFound
+	}
+	if err != nil {
+		return models.Grant{}, fmt.Errorf("update grant: %w", err)
+	}
+	return updated, nil
+}
+
+// RevokeGrant ends a grant. Either side can revoke it, and the other side is
+// told about it.
+func (c *care) RevokeGrant(ctx context.Context, userID, grantID int) error {
+	grant, err := c.grant(ctx, userID, grantID)
+	if err != nil {
+		return err
+	}
+
+	err = c.repo.Care.RevokeGrant(ctx, grantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("revoke grant: %w", err)
+	}
+
+	msg := notifier.Message{
+		UserID:  grant.GranteeId,
+		Subject: "Care team access revoked",
+		Body:    "Your access to a care team member's data has been revoked.",
+		Data:    map[string]string{"grant_id": strconv.Itoa(grantID)},
+	}
+	if userID == grant.GranteeId {
+		msg.UserID, msg.Body = grant.OwnerId, "A caregiver gave up access to your data."
+	}
+	go c.notifyAll(context.WithoutCancel(ctx), []notifier.Message{msg})
+	return nil
+}
+
+// GetAccessLog returns the reads made through grants of the user's data and
+// the reads the user made of others' data, newest first.
+func (c *care) GetAccessLog(ctx context.Context, userID, limit int) ([]models.GrantAccess, error) {
+	if limit <= 0 {
+		limit = defaultAccessLogLimit
+	}
+
+	accesses, err := c.repo.Care.GetGrantAccessLog(ctx, userID, min(limit, maxAccessLogLimit))
+	if err != nil {
+		return nil, fmt.Errorf("get access log: %w", err)
+	}
+	if accesses == nil {
+		accesses = []models.GrantAccess{}
+	}
+	return accesses, nil
+}
+
+// GetPatientMetrics reads the metrics of query.UserId on behalf of the
+// grantee. Without metric types in the query all shared types are read.
+func (c *care) GetPatientMetrics(ctx context.Context, granteeID int, query entity.HealthMetricsQuery) (entity.HealthMetricsPage, error) {
+	grant, err := c.authorize(ctx, granteeID, query.UserId)
+	if err != nil {
+		return entity.HealthMetricsPage{}, err
+	}
+
+	if len(query.MetricTypes) == 0 {
+		if len(grant.MetricTypes) == 0 {
+			return entity.HealthMetricsPage{}, fmt.Errorf("%w: no metric types are shared with you", entity.ErrForbidden)
+		}
+		query.MetricTypes = grant.MetricTypes
+	}
+	for i, metricType := range query.MetricTypes {
+		if query.MetricTypes[i], err = sharedMetricType(grant, metricType); err != nil {
+			return entity.HealthMetricsPage{}, err
+		}
+	}
+
+	page, err := c.health.GetMetrics(ctx, query)
+	if err != nil {
+		return entity.HealthMetricsPage{}, err
+	}
+
+	details := "metric_types=" + strings.Join(query.MetricTypes, ",") + describeRange(query.From, query.To)
+	if err := c.logAccess(ctx, grant, carerepo.ResourceMetrics, details); err != nil {
+		return entity.HealthMetricsPage{}, err
+	}
+	return page, nil
+}
+
+func (c *care) AggregatePatientMetrics(ctx context.Context, granteeID int, query entity.MetricAggregateQuery) (entity.MetricAggregateResponse, error) {
+	grant, err := c.authorize(ctx, granteeID, query.UserId)
+	if err != nil {
+		return entity.MetricAggregateResponse{}, err
+	}
+	if query.MetricType == "" {
+		return entity.MetricAggregateResponse{}, fmt.Errorf("%w: metric_type is required", entity.ErrInvalidParam)
+	}
+	if query.MetricType, err = sharedMetricType(grant, query.MetricType); err != nil {
+		return entity.MetricAggregateResponse{}, err
+	}
+
+	response, err := c.health.AggregateMetrics(ctx, query)
+	if err != nil {
+		return entity.MetricAggregateResponse{}, err
+	}
+
+	details := "metric_type=" + query.MetricType + " bucket=" + query.Bucket + describeRange(query.From, query.To)
+	if err := c.logAccess(ctx, grant, carerepo.ResourceMetricsAggregate, details); err != nil {
+		return entity.MetricAggregateResponse{}, err
+	}
+	return response, nil
+}
+
+func (c *care) GetPatientLocationHistory(ctx context.Context, granteeID int, query entity.TimeRangeQuery) ([]entity.LocationPoint, error) {
+	grant, err := c.authorize(ctx, granteeID, query.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if !grant.Location {
+		return nil, fmt.Errorf("%w: location is not shared with you", entity.ErrForbidden)
+	}
+
+	points, err := c.location.GetLocationHistory(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	details := fmt.Sprintf("points=%d", len(points)) + describeRange(query.From, query.To)
+	if err := c.logAccess(ctx, grant, carerepo.ResourceLocationHistory, details); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// authorize returns the active grant from ownerID to granteeID. Without one
+// the owner's data does not exist for the grantee.
+func (c *care) authorize(ctx context.Context, granteeID, ownerID int) (models.Grant, error) {
+	grant, err := c.repo.Care.GetActiveGrant(ctx, ownerID, granteeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Grant{}, entity.ErrNotFound
+	}
+	if err != nil {
+		return models.Grant{}, fmt.Errorf("authorize grant: %w", err)
+	}
+	return grant, nil
+}
+
+// logAccess records a read made through the grant. The data is only returned
+// once the access is recorded.
+func (c *care) logAccess(ctx context.Context, grant models.Grant, resource, details string) error {
+	err := c.repo.Care.LogGrantAccess(ctx, models.GrantAccess{
+		GrantId:   grant.ID,
+		OwnerId:   grant.OwnerId,
+		GranteeId: grant.GranteeId,
+		Resource:  resource,
+		Details:   details,
+	})
+	if err != nil {
+		return fmt.Errorf("record access: %w", err)
+	}
+	return nil
+}
+
+// invitation loads the invitation for the user who sent it or the user whose
+// account email it was sent to; owner reports which of the two the user is.
+// Anyone else gets entity.ErrNotFound. The invitee has to have verified the
+// email, otherwise anyone could claim an invitation by registering with the
+// address.
+func (c *care) invitation(ctx context.Context, userID, invitationID int) (invitation models.CareInvitation, owner bool, err error) {
+	invitation, err = c.repo.Care.GetInvitation(ctx, invitationID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.CareInvitation{}, false, entity.ErrNotFound
+	}
+	if err != nil {
+		return models.CareInvitation{}, false, fmt.Errorf("get invitation: %w", err)
+	}
+	if invitation.OwnerId == userID {
+		return invitation, true, nil
+	}
+
+	user, err := c.repo.User.GetUserByID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.CareInvitation{}, false, entity.ErrNotFound
+	}
+	if err != nil {
+		return models.CareInvitation{}, false, fmt.Errorf("get invitation: %w", err)
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return models.CareInvitation{}, false, entity.ErrNotFound
+	}
+	if user.EmailVerifiedAt == nil {
+		return models.CareInvitation{}, false, fmt.Errorf("%w: verify your email address first to respond to invitations", entity.ErrForbidden)
+	}
+	return invitation, false, nil
+}
+
+// grant loads an active grant the user gave or received. Anyone else gets
+// entity.ErrNotFound.
+func (c *care) grant(ctx context.Context, userID, grantID int) (models.Grant, error) {
+	grant, err := c.repo.Care.GetGrant(ctx, grantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Grant{}, entity.ErrNotFound
+	}
+	if err != nil {
+		return models.Grant{}, fmt.Errorf("get grant: %w", err)
+	}
+	if grant.RevokedAt != nil || (grant.OwnerId != userID && grant.GranteeId != userID) {
+		return models.Grant{}, entity.ErrNotFound
+	}
+	return grant, nil
+}
+
+func (c *care) notifyAll(ctx context.Context, messages []notifier.Message) {
+	for _, msg := range messages {
+		err := c.notifier.Notify(ctx, msg)
+		// email is optional in deployments without SMTP
+		if err != nil && !errors.Is(err, notifier.ErrUnsupportedChannel) {
+			c.logger.Errorf("notify user %d: %s: %v", msg.UserID, msg.Subject, err)
+		}
+	}
+}
+
+// grantScope validates a scope and returns its metric types in canonical form
+// without duplicates.
+func grantScope(metricTypes []string, location bool) ([]string, error) {
+	canonical := make([]string, 0, len(metricTypes))
+	for _, metricType := range metricTypes {
+		t, ok := health.CanonicalMetricType(metricType)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown metric type %q", entity.ErrInvalidParam, metricType)
+		}
+		if !slices.Contains(canonical, t) {
+			canonical = append(canonical, t)
+		}
+	}
+	if len(canonical) == 0 && !location {
+		return nil, fmt.Errorf("%w: share at least one metric type or the location", entity.ErrInvalidParam)
+	}
+	return canonical, nil
+}
+
+func sharedMetricType(grant models.Grant, metricType string) (string, error) {
+	t, _ := health.CanonicalMetricType(metricType)
+	if !slices.Contains(grant.MetricTypes, t) {
+		return "", fmt.Errorf("%w: metric type %q is not shared with you", entity.ErrForbidden, metricType)
+	}
+	return t, nil
+}
+
+func pending(invitation models.CareInvitation) error {
+	if invitation.Status != carerepo.InvitationPending {
+		return fmt.Errorf("%w: invitation is already %s", entity.ErrInvalidParam, invitation.Status)
+	}
+	if expired(invitation, time.Now()) {
+		return fmt.Errorf("%w: invitation has expired", entity.ErrInvalidParam)
+	}
+	return nil
+}
+
+func expired(invitation models.CareInvitation, now time.Time) bool {
+	return invitation.Status == carerepo.InvitationPending && !now.Before(invitation.ExpiresAt)
+}
+
+func describeRange(from, to *time.Time) string {
+	var s string
+	if from != nil {
+		s += " from=" + from.UTC().Format(time.RFC3339)
+	}
+	if to != nil {
+		s += " to=" + to.UTC().Format(time.RFC3339)
+	}
+	return s
+}
+
+func displayName(user models.User) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return user.Username
+}
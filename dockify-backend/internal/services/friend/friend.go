@@ -0,0 +1,188 @@
+package friend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	friendrepo "github.com/askaroe/dockify-backend/internal/repository/friend"
+	"github.com/jackc/pgx/v5"
+)
+
+type Friend interface {
+	SendFriendRequest(ctx context.Context, userID, otherID int) (entity.FriendResponse, error)
+	AcceptFriendRequest(ctx context.Context, userID, otherID int) error
+	RemoveFriend(ctx context.Context, userID, otherID int) error
+	GetFriends(ctx context.Context, userID int) ([]entity.FriendResponse, error)
+
+	BlockUser(ctx context.Context, userID, otherID int) error
+	UnblockUser(ctx context.Context, userID, otherID int) error
+	GetBlockedUsers(ctx context.Context, userID int) ([]entity.BlockedUserResponse, error)
+}
+
+type friend struct {
+	repo *repository.Repository
+}
+
+func NewFriendService(repo *repository.Repository) Friend {
+	return &friend{repo: repo}
+}
+
+// SendFriendRequest asks otherID to become a friend. A pending request from
+// otherID is accepted instead, and an existing friendship is returned as is.
+func (f *friend) SendFriendRequest(ctx context.Context, userID, otherID int) (entity.FriendResponse, error) {
+	if err := f.checkOther(ctx, userID, otherID); err != nil {
+		return entity.FriendResponse{}, err
+	}
+
+	// users who blocked each other cannot become friends; answer as if the
+	// user did not exist so the block stays private
+	blocked, err := f.repo.Friend.GetBlockedUserIDs(ctx, userID)
+	if err != nil {
+		return entity.FriendResponse{}, fmt.Errorf("send friend request: %w", err)
+	}
+	if slices.Contains(blocked, otherID) {
+		return entity.FriendResponse{}, entity.ErrNotFound
+	}
+
+	var friendship models.Friendship
+	err = f.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		existing, err := tx.Friend.GetFriendship(ctx, userID, otherID)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			if err := tx.Friend.CreateFriendRequest(ctx, userID, otherID); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case existing.Status == friendrepo.StatusPending && existing.RequesterId == otherID:
+			if err := tx.Friend.AcceptFriendRequest(ctx, otherID, userID); err != nil {
+				return err
+			}
+		}
+
+		friendship, err = tx.Friend.GetFriendship(ctx, userID, otherID)
+		return err
+	})
+	if err != nil {
+		return entity.FriendResponse{}, fmt.Errorf("send friend request: %w", err)
+	}
+
+	return friendResponse(userID, friendship), nil
+}
+
+func (f *friend) AcceptFriendRequest(ctx context.Context, userID, otherID int) error {
+	err := f.repo.Friend.AcceptFriendRequest(ctx, otherID, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("accept friend request: %w", err)
+	}
+	return nil
+}
+
+// RemoveFriend ends a friendship, or withdraws or declines a pending request.
+func (f *friend) RemoveFriend(ctx context.Context, userID, otherID int) error {
+	err := f.repo.Friend.DeleteFriendship(ctx, userID, otherID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("remove friend: %w", err)
+	}
+	return nil
+}
+
+func (f *friend) GetFriends(ctx context.Context, userID int) ([]entity.FriendResponse, error) {
+	friendships, err := f.repo.Friend.GetFriendships(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get friends: %w", err)
+	}
+
+	response := make([]entity.FriendResponse, 0, len(friendships))
+	for _, friendship := range friendships {
+		response = append(response, friendResponse(userID, friendship))
+	}
+	return response, nil
+}
+
+// BlockUser hides the users from each other and ends their friendship.
+func (f *friend) BlockUser(ctx context.Context, userID, otherID int) error {
+	if err := f.checkOther(ctx, userID, otherID); err != nil {
+		return err
+	}
+
+	err := f.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		if err := tx.Friend.BlockUser(ctx, userID, otherID); err != nil {
+			return err
+		}
+		if err := tx.Friend.DeleteFriendship(ctx, userID, otherID); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("block user: %w", err)
+	}
+	return nil
+}
+
+func (f *friend) UnblockUser(ctx context.Context, userID, otherID int) error {
+	err := f.repo.Friend.UnblockUser(ctx, userID, otherID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("unblock user: %w", err)
+	}
+	return nil
+}
+
+func (f *friend) GetBlockedUsers(ctx context.Context, userID int) ([]entity.BlockedUserResponse, error) {
+	blocks, err := f.repo.Friend.GetBlocks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get blocked users: %w", err)
+	}
+
+	response := make([]entity.BlockedUserResponse, 0, len(blocks))
+	for _, b := range blocks {
+		response = append(response, entity.BlockedUserResponse{UserID: b.BlockedId, CreatedAt: b.CreatedAt})
+	}
+	return response, nil
+}
+
+// checkOther validates the target of a friend request or block.
+func (f *friend) checkOther(ctx context.Context, userID, otherID int) error {
+	if userID == otherID {
+		return fmt.Errorf("%w: cannot befriend or block yourself", entity.ErrInvalidParam)
+	}
+
+	_, err := f.repo.User.GetUserByID(ctx, otherID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	return nil
+}
+
+func friendResponse(userID int, f models.Friendship) entity.FriendResponse {
+	other := f.AddresseeId
+	if other == userID {
+		other = f.RequesterId
+	}
+
+	return entity.FriendResponse{
+		UserID:     other,
+		Status:     f.Status,
+		Incoming:   f.AddresseeId == userID,
+		CreatedAt:  f.CreatedAt,
+		AcceptedAt: f.AcceptedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+type bucketSpec struct {
+	unit          string        // date_trunc field
+	defaultWindow time.Duration // range used when from is omitted
+}
+
+var bucketSpecs = map[string]bucketSpec{
+	"1h": {unit: "hour", defaultWindow: 7 * 24 * time.Hour},
+	"1d": {unit: "day", defaultWindow: 30 * 24 * time.Hour},
+	"1w": {unit: "week", defaultWindow: 26 * 7 * 24 * time.Hour},
+	"1M": {unit: "month", defaultWindow: 365 * 24 * time.Hour},
+}
+
+var aggregateFunctions = []string{"avg", "min", "max", "sum", "count", "stddev", "p50", "p75", "p90", "p95", "p99"}
+
+var defaultAggregateFunctions = []string{"avg", "min", "max", "count"}
+
+func (h *health) AggregateMetrics(ctx context.Context, query entity.MetricAggregateQuery) (entity.MetricAggregateResponse, error) {
+	if query.MetricType == "" {
+		return entity.MetricAggregateResponse{}, fmt.Errorf("%w: metric_type is required", entity.ErrInvalidParam)
+	}
+	metricType := canonicalMetricType(query.MetricType)
+
+	spec, ok := bucketSpecs[query.Bucket]
+	if !ok {
+		return entity.MetricAggregateResponse{}, fmt.Errorf("%w: bucket must be one of 1h, 1d, 1w, 1M", entity.ErrInvalidParam)
+	}
+
+	functions := query.Functions
+	if len(functions) == 0 {
+		functions = defaultAggregateFunctions
+	}
+	for _, fn := range functions {
+		if !slices.Contains(aggregateFunctions, fn) {
+			return entity.MetricAggregateResponse{}, fmt.Errorf("%w: unsupported aggregate function %q", entity.ErrInvalidParam, fn)
+		}
+	}
+
+	timezone := query.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	// "Local" is the server's zone to Go and unknown to Postgres
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "Local" {
+		return entity.MetricAggregateResponse{}, fmt.Errorf("%w: unknown timezone %q", entity.ErrInvalidParam, timezone)
+	}
+
+	to := time.Now().UTC()
+	if query.To != nil {
+		to = query.To.UTC()
+	}
+	from := to.Add(-spec.defaultWindow)
+	if query.From != nil {
+		from = query.From.UTC()
+	}
+	if !from.Before(to) {
+		return entity.MetricAggregateResponse{}, fmt.Errorf("%w: from must be before to", entity.ErrInvalidParam)
+	}
+
+	aggregates, err := h.repo.Health.AggregateMetrics(ctx, models.MetricAggregateFilter{
+		UserId:     query.UserId,
+		MetricType: metricType,
+		Unit:       spec.unit,
+		Timezone:   loc.String(),
+		Functions:  functions,
+		From:       from,
+		To:         to,
+	})
+	if err != nil {
+		return entity.MetricAggregateResponse{}, fmt.Errorf("aggregate health metrics: %w", err)
+	}
+
+	buckets := make([]entity.MetricBucket, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		// the database returns the local wall-clock bucket start, attach the zone to it
+		b := aggregate.Bucket
+		start := time.Date(b.Year(), b.Month(), b.Day(), b.Hour(), b.Minute(), b.Second(), b.Nanosecond(), loc)
+		buckets = append(buckets, entity.MetricBucket{Start: start, Values: aggregate.Values})
+	}
+
+	return entity.MetricAggregateResponse{
+		MetricType: metricType,
+		Bucket:     query.Bucket,
+		Timezone:   loc.String(),
+		From:       from.In(loc),
+		To:         to.In(loc),
+		Functions:  functions,
+		Buckets:    buckets,
+	}, nil
+}
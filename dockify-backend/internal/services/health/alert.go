@@ -0,0 +1,405 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/notifier"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	healthrepo "github.com/askaroe/dockify-backend/internal/repository/health"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	maxAlertRules        = 50
+	maxAlertChannels     = 5
+	maxAlertRuleName     = 100
+	maxAlertDuration     = 24 * 60     // minutes
+	maxAlertCooldown     = 7 * 24 * 60 // minutes
+	defaultAlertCooldown = 60          // minutes
+	defaultAlertsLimit   = 50
+	maxAlertsLimit       = 200
+
+	// readings older than this when uploaded do not fire rules, so syncing a
+	// device's history does not alert about the past
+	maxAlertReadingAge = 24 * time.Hour
+
+	deliveryChannelApp = "app"
+	deliveryPending    = "pending"
+	deliverySent       = "sent"
+	deliveryFailed     = "failed"
+)
+
+var comparatorText = map[string]string{
+	healthrepo.ComparatorGreater:        "above",
+	healthrepo.ComparatorGreaterOrEqual: "at or above",
+	healthrepo.ComparatorLess:           "below",
+	healthrepo.ComparatorLessOrEqual:    "at or below",
+}
+
+type AlertRules interface {
+	CreateAlertRule(ctx context.Context, userID int, req entity.AlertRuleRequest) (models.AlertRule, error)
+	GetAlertRule(ctx context.Context, userID, id int) (models.AlertRule, error)
+	GetAlertRules(ctx context.Context, userID int) ([]models.AlertRule, error)
+	UpdateAlertRule(ctx context.Context, userID, id int, req entity.AlertRuleRequest) (models.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, userID, id int) error
+	GetAlerts(ctx context.Context, userID, limit int) ([]models.Alert, error)
+}
+
+func (h *health) CreateAlertRule(ctx context.Context, userID int, req entity.AlertRuleRequest) (models.AlertRule, error) {
+	rule, err := toAlertRuleModel(userID, req)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	if err := h.checkEmailChannels(ctx, userID, rule.Channels); err != nil {
+		return models.AlertRule{}, err
+	}
+
+	count, err := h.repo.Health.CountAlertRules(ctx, userID)
+	if err != nil {
+		return models.AlertRule{}, fmt.Errorf("create alert rule: %w", err)
+	}
+	if count >= maxAlertRules {
+		return models.AlertRule{}, fmt.Errorf("%w: at most %d alert rules are allowed", entity.ErrInvalidParam, maxAlertRules)
+	}
+
+	rule, err = h.repo.Health.CreateAlertRule(ctx, rule)
+	if err != nil {
+		return models.AlertRule{}, fmt.Errorf("create alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (h *health) GetAlertRule(ctx context.Context, userID, id int) (models.AlertRule, error) {
+	rule, err := h.repo.Health.GetAlertRule(ctx, userID, id)
+	return rule, notFound(err)
+}
+
+func (h *health) GetAlertRules(ctx context.Context, userID int) ([]models.AlertRule, error) {
+	rules, err := h.repo.Health.GetAlertRules(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get alert rules: %w", err)
+	}
+
+	if rules == nil {
+		rules = []models.AlertRule{}
+	}
+	return rules, nil
+}
+
+// UpdateAlertRule replaces the rule. The cooldown keeps counting from the
+// last alert of the rule.
+func (h *health) UpdateAlertRule(ctx context.Context, userID, id int, req entity.AlertRuleRequest) (models.AlertRule, error) {
+	rule, err := toAlertRuleModel(userID, req)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	if err := h.checkEmailChannels(ctx, userID, rule.Channels); err != nil {
+		return models.AlertRule{}, err
+	}
+	rule.ID = id
+
+	rule, err = h.repo.Health.UpdateAlertRule(ctx, rule)
+	if err != nil {
+		return models.AlertRule{}, notFound(err)
+	}
+	return rule, nil
+}
+
+func (h *health) DeleteAlertRule(ctx context.Context, userID, id int) error {
+	return notFound(h.repo.Health.DeleteAlertRule(ctx, userID, id))
+}
+
+func (h *health) GetAlerts(ctx context.Context, userID, limit int) ([]models.Alert, error) {
+	if limit <= 0 {
+		limit = defaultAlertsLimit
+	}
+
+	alerts, err := h.repo.Health.GetAlerts(ctx, userID, min(limit, maxAlertsLimit))
+	if err != nil {
+		return nil, fmt.Errorf("get alerts: %w", err)
+	}
+
+	if alerts == nil {
+		alerts = []models.Alert{}
+	}
+	return alerts, nil
+}
+
+func toAlertRuleModel(userID int, req entity.AlertRuleRequest) (models.AlertRule, error) {
+	def, ok := lookupMetric(req.MetricType)
+	if !ok {
+		return models.AlertRule{}, fmt.Errorf("%w: unknown metric type %q", entity.ErrInvalidParam, req.MetricType)
+	}
+	if _, ok := comparatorText[req.Comparator]; !ok {
+		return models.AlertRule{}, fmt.Errorf("%w: comparator must be gt, gte, lt or lte", entity.ErrInvalidParam)
+	}
+	threshold, err := def.normalize(req.Threshold, req.Unit)
+	if err != nil {
+		return models.AlertRule{}, fmt.Errorf("%w: threshold: %v", entity.ErrInvalidParam, err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if len([]rune(name)) > maxAlertRuleName {
+		return models.AlertRule{}, fmt.Errorf("%w: name must not exceed %d characters", entity.ErrInvalidParam, maxAlertRuleName)
+	}
+	if req.DurationMinutes < 0 || req.DurationMinutes > maxAlertDuration {
+		return models.AlertRule{}, fmt.Errorf("%w: duration_minutes must be between 0 and %d", entity.ErrInvalidParam, maxAlertDuration)
+	}
+
+	cooldown := defaultAlertCooldown
+	if req.CooldownMinutes != nil {
+		cooldown = *req.CooldownMinutes
+	}
+	if cooldown < 0 || cooldown > maxAlertCooldown {
+		return models.AlertRule{}, fmt.Errorf("%w: cooldown_minutes must be between 0 and %d", entity.ErrInvalidParam, maxAlertCooldown)
+	}
+
+	channels, err := toAlertChannels(req.Channels)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	return models.AlertRule{
+		UserId:          userID,
+		Name:            name,
+		MetricType:      def.Type,
+		Comparator:      req.Comparator,
+		Threshold:       threshold,
+		DurationMinutes: req.DurationMinutes,
+		CooldownMinutes: cooldown,
+		Channels:        channels,
+		Enabled:         enabled,
+	}, nil
+}
+
+func toAlertChannels(req []models.AlertChannel) ([]models.AlertChannel, error) {
+	if len(req) > maxAlertChannels {
+		return nil, fmt.Errorf("%w: at most %d channels are allowed", entity.ErrInvalidParam, maxAlertChannels)
+	}
+
+	channels := make([]models.AlertChannel, 0, len(req))
+	for _, c := range req {
+		target := strings.TrimSpace(c.Target)
+		switch c.Type {
+		case notifier.ChannelEmail:
+			// an empty target is filled in with the account's email
+			if target == "" {
+				break
+			}
+			addr, err := mail.ParseAddress(target)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid email address %q", entity.ErrInvalidParam, target)
+			}
+			target = addr.Address
+		case notifier.ChannelWebhook:
+			if err := notifier.ValidateWebhookURL(target); err != nil {
+				return nil, fmt.Errorf("%w: %v", entity.ErrInvalidParam, err)
+			}
+		case notifier.ChannelLog:
+			target = ""
+		default:
+			return nil, fmt.Errorf("%w: channel type must be email, webhook or log", entity.ErrInvalidParam)
+		}
+		channels = append(channels, models.AlertChannel{Type: c.Type, Target: target})
+	}
+	return channels, nil
+}
+
+// checkEmailChannels only lets email alerts go to the account's own verified
+// address, so rules can not be used to send mail to strangers. Caregivers get
+// access through care grants instead.
+func (h *health) checkEmailChannels(ctx context.Context, userID int, channels []models.AlertChannel) error {
+	var userModel *models.User
+	for i, c := range channels {
+		if c.Type != notifier.ChannelEmail {
+			continue
+		}
+		if userModel == nil {
+			u, err := h.repo.User.GetUserByID(ctx, userID)
+			if err != nil {
+				return fmt.Errorf("check alert channels: %w", notFound(err))
+			}
+			userModel = &u
+		}
+		if userModel.EmailVerifiedAt == nil {
+			return fmt.Errorf("%w: verify your email address before adding an email channel", entity.ErrInvalidParam)
+		}
+		if c.Target == "" {
+			channels[i].Target = userModel.Email
+			continue
+		}
+		if !strings.EqualFold(c.Target, userModel.Email) {
+			return fmt.Errorf("%w: email alerts can only be sent to your verified email address", entity.ErrInvalidParam)
+		}
+	}
+	return nil
+}
+
+// evaluateAlerts checks the user's enabled rules against newly stored
+// metrics. A rule is evaluated at the newest reading of its metric type and
+// fires when the condition has held since at least DurationMinutes before it
+// and the rule is not cooling down. Alerts are delivered in the background;
+// failures are logged and never fail the upload.
+func (h *health) evaluateAlerts(ctx context.Context, userID int, metrics []models.HealthMetrics) {
+	now := time.Now().UTC()
+	latest := make(map[string]models.HealthMetrics)
+	for _, m := range metrics {
+		if m.RecordedAt == nil || now.Sub(*m.RecordedAt) > maxAlertReadingAge {
+			continue
+		}
+		if cur, ok := latest[m.MetricType]; !ok || m.RecordedAt.After(*cur.RecordedAt) {
+			latest[m.MetricType] = m
+		}
+	}
+	if len(latest) == 0 {
+		return
+	}
+
+	metricTypes := make([]string, 0, len(latest))
+	for metricType := range latest {
+		metricTypes = append(metricTypes, metricType)
+	}
+	rules, err := h.repo.Health.GetEnabledAlertRules(ctx, userID, metricTypes)
+	if err != nil {
+		h.logger.Errorf("evaluate alerts of user %d: %v", userID, err)
+		return
+	}
+
+	for _, rule := range rules {
+		reading := latest[rule.MetricType]
+		alert, fired, err := h.evaluateRule(ctx, rule, reading)
+		if err != nil {
+			h.logger.Errorf("evaluate alert rule %d: %v", rule.ID, err)
+			continue
+		}
+		if fired {
+			go h.deliverAlert(ctx, rule, alert)
+		}
+	}
+}
+
+func (h *health) evaluateRule(ctx context.Context, rule models.AlertRule, reading models.HealthMetrics) (models.Alert, bool, error) {
+	at := *reading.RecordedAt
+	window := time.Duration(rule.DurationMinutes) * time.Minute
+
+	// looking back twice the window is enough to tell whether the condition
+	// held for all of it
+	start, err := h.repo.Health.GetBreachStart(ctx, rule, at, at.Add(-2*window))
+	if err != nil || start == nil || at.Sub(*start) < window {
+		return models.Alert{}, false, err
+	}
+
+	alert := models.Alert{
+		RuleId:     &rule.ID,
+		UserId:     rule.UserId,
+		RuleName:   rule.Name,
+		MetricType: rule.MetricType,
+		Comparator: rule.Comparator,
+		Threshold:  rule.Threshold,
+		Value:      reading.MetricValue,
+		RecordedAt: at,
+		Deliveries: pendingDeliveries(rule),
+	}
+	err = h.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		if err := tx.Health.ClaimAlertRule(ctx, rule.ID); err != nil {
+			return err
+		}
+		alert.ID, err = tx.Health.CreateAlert(ctx, alert)
+		return err
+	})
+	// the rule is cooling down from an earlier alert
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Alert{}, false, nil
+	}
+	if err != nil {
+		return models.Alert{}, false, err
+	}
+	return alert, true, nil
+}
+
+func pendingDeliveries(rule models.AlertRule) []models.AlertDelivery {
+	deliveries := []models.AlertDelivery{{Channel: deliveryChannelApp, Status: deliveryPending}}
+	for _, c := range rule.Channels {
+		deliveries = append(deliveries, models.AlertDelivery{Channel: c.Type, Target: c.Target, Status: deliveryPending})
+	}
+	return deliveries
+}
+
+// deliverAlert sends the alert to the user in the app and to every channel of
+// the rule, then records the outcome of each delivery on the alert.
+func (h *health) deliverAlert(ctx context.Context, rule models.AlertRule, alert models.Alert) {
+	def, _ := lookupMetric(rule.MetricType)
+	name := rule.Name
+	if name == "" {
+		name = def.Label
+	}
+
+	body := fmt.Sprintf("%s was %s %s at %s UTC, %s the threshold of %s %s",
+		def.Label, formatAlertValue(alert.Value), def.Unit, alert.RecordedAt.Format("2006-01-02 15:04"),
+		comparatorText[rule.Comparator], formatAlertValue(rule.Threshold), def.Unit)
+	if rule.DurationMinutes > 0 {
+		body += fmt.Sprintf(" for at least %d minutes", rule.DurationMinutes)
+	}
+
+	msg := notifier.Message{
+		UserID:  rule.UserId,
+		Subject: "Health alert: " + name,
+		Body:    body + ".",
+		Data: map[string]string{
+			"alert_id":    strconv.Itoa(alert.ID),
+			"rule_id":     strconv.Itoa(rule.ID),
+			"metric_type": rule.MetricType,
+			"value":       formatAlertValue(alert.Value),
+		},
+	}
+
+	var wg sync.WaitGroup
+	for i := range alert.Deliveries {
+		d := &alert.Deliveries[i]
+		m := msg
+		if d.Channel != deliveryChannelApp {
+			m.Channel, m.To = d.Channel, d.Target
+		}
+		// the rule may predate the check or the user changed their email since
+		if d.Channel == notifier.ChannelEmail {
+			if err := h.checkEmailChannels(ctx, rule.UserId, []models.AlertChannel{{Type: d.Channel, Target: d.Target}}); err != nil {
+				d.Status, d.Error = deliveryFailed, err.Error()
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := h.notifier.Notify(ctx, m); err != nil {
+				d.Status, d.Error = deliveryFailed, err.Error()
+				return
+			}
+			d.Status = deliverySent
+		}()
+	}
+	wg.Wait()
+
+	if err := h.repo.Health.SetAlertDeliveries(ctx, alert.ID, alert.Deliveries); err != nil {
+		h.logger.Errorf("record deliveries of alert %d: %v", alert.ID, err)
+	}
+}
+
+func formatAlertValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
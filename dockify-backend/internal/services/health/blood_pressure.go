@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+type BloodPressure interface {
+	CreateBloodPressure(ctx context.Context, userID int, req entity.BloodPressureRequest) (models.BloodPressureReading, error)
+	GetBloodPressure(ctx context.Context, userID, id int) (models.BloodPressureReading, error)
+	GetBloodPressureReadings(ctx context.Context, query entity.TimeRangeQuery) ([]models.BloodPressureReading, error)
+	UpdateBloodPressure(ctx context.Context, userID, id int, req entity.BloodPressureRequest) (models.BloodPressureReading, error)
+	DeleteBloodPressure(ctx context.Context, userID, id int) error
+}
+
+func (h *health) CreateBloodPressure(ctx context.Context, userID int, req entity.BloodPressureRequest) (models.BloodPressureReading, error) {
+	reading, err := toBloodPressureModel(userID, req)
+	if err != nil {
+		return models.BloodPressureReading{}, err
+	}
+
+	reading.ID, err = h.repo.Health.CreateBloodPressure(ctx, reading)
+	if err != nil {
+		return models.BloodPressureReading{}, fmt.Errorf("create blood pressure: %w", err)
+	}
+
+	return reading, nil
+}
+
+func (h *health) GetBloodPressure(ctx context.Context, userID, id int) (models.BloodPressureReading, error) {
+	reading, err := h.repo.Health.GetBloodPressure(ctx, userID, id)
+	return reading, notFound(err)
+}
+
+func (h *health) GetBloodPressureReadings(ctx context.Context, query entity.TimeRangeQuery) ([]models.BloodPressureReading, error) {
+	readings, err := h.repo.Health.GetBloodPressureReadings(ctx, toTimeRangeFilter(query))
+	if err != nil {
+		return nil, fmt.Errorf("get blood pressure readings: %w", err)
+	}
+
+	if readings == nil {
+		readings = []models.BloodPressureReading{}
+	}
+	return readings, nil
+}
+
+func (h *health) UpdateBloodPressure(ctx context.Context, userID, id int, req entity.BloodPressureRequest) (models.BloodPressureReading, error) {
+	reading, err := toBloodPressureModel(userID, req)
+	if err != nil {
+		return models.BloodPressureReading{}, err
+	}
+	reading.ID = id
+
+	if err := h.repo.Health.UpdateBloodPressure(ctx, reading); err != nil {
+		return models.BloodPressureReading{}, notFound(err)
+	}
+
+	return reading, nil
+}
+
+func (h *health) DeleteBloodPressure(ctx context.Context, userID, id int) error {
+	return notFound(h.repo.Health.DeleteBloodPressure(ctx, userID, id))
+}
+
+func toBloodPressureModel(userID int, req entity.BloodPressureRequest) (models.BloodPressureReading, error) {
+	if err := checkRange("blood_pressure_systolic", float64(req.Systolic)); err != nil {
+		return models.BloodPressureReading{}, err
+	}
+	if err := checkRange("blood_pressure_diastolic", float64(req.Diastolic)); err != nil {
+		return models.BloodPressureReading{}, err
+	}
+	if req.Diastolic >= req.Systolic {
+		return models.BloodPressureReading{}, fmt.Errorf("%w: diastolic must be lower than systolic", entity.ErrInvalidParam)
+	}
+	if req.Pulse != nil {
+		if err := checkRange("heart_rate", float64(*req.Pulse)); err != nil {
+			return models.BloodPressureReading{}, err
+		}
+	}
+
+	recordedAt := time.Now().UTC()
+	if req.RecordedAt != nil {
+		recordedAt = req.RecordedAt.UTC()
+	}
+
+	return models.BloodPressureReading{
+		UserId:     userID,
+		Systolic:   req.Systolic,
+		Diastolic:  req.Diastolic,
+		Pulse:      req.Pulse,
+		Source:     req.Source,
+		RecordedAt: &recordedAt,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package health
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+)
+
+// metricDefinition describes a metric type accepted by the API. Values are
+// always stored in Unit; conversions map other accepted units onto it.
+type metricDefinition struct {
+	Type        string
+	Label       string
+	Unit        string
+	Min         float64
+	Max         float64
+	Conversions map[string]func(float64) float64
+}
+
+func scale(factor float64) func(float64) float64 {
+	return func(v float64) float64 { return v * factor }
+}
+
+var metricCatalog = []metricDefinition{
+	{Type: "heart_rate", Label: "Heart Rate", Unit: "bpm", Min: 20, Max: 250},
+	{Type: "resting_heart_rate", Label: "Resting Heart Rate", Unit: "bpm", Min: 20, Max: 200},
+	{Type: "steps", Label: "Steps", Unit: "count", Min: 0, Max: 100000},
+	{Type: "distance", Label: "Distance", Unit: "km", Min: 0, Max: 500, Conversions: map[string]func(float64) float64{
+		"m":  scale(0.001),
+		"mi": scale(1.609344),
+	}},
+	{Type: "weight", Label: "Weight", Unit: "kg", Min: 1, Max: 500, Conversions: map[string]func(float64) float64{
+		"lb": scale(0.45359237),
+	}},
+	{Type: "height", Label: "Height", Unit: "cm", Min: 30, Max: 280, Conversions: map[string]func(float64) float64{
+		"m":  scale(100),
+		"in": scale(2.54),
+	}},
+	{Type: "body_fat", Label: "Body Fat", Unit: "%", Min: 2, Max: 75},
+	{Type: "blood_glucose", Label: "Blood Glucose", Unit: "mg/dL", Min: 10, Max: 1000, Conversions: map[string]func(float64) float64{
+		"mmol/L": scale(18.0182),
+	}},
+	{Type: "oxygen_saturation", Label: "Blood Oxygen (SpO2)", Unit: "%", Min: 50, Max: 100},
+	{Type: "body_temperature", Label: "Body Temperature", Unit: "C", Min: 30, Max: 45, Conversions: map[string]func(float64) float64{
+		"F": func(v float64) float64 { return (v - 32) * 5 / 9 },
+	}},
+	{Type: "blood_pressure_systolic", Label: "Systolic Blood Pressure", Unit: "mmHg", Min: 50, Max: 260},
+	{Type: "blood_pressure_diastolic", Label: "Diastolic Blood Pressure", Unit: "mmHg", Min: 30, Max: 160},
+	{Type: "respiratory_rate", Label: "Respiratory Rate", Unit: "breaths/min", Min: 4, Max: 60},
+	{Type: "sleep_duration", Label: "Sleep Duration", Unit: "h", Min: 0, Max: 24, Conversions: map[string]func(float64) float64{
+		"min": scale(1.0 / 60),
+	}},
+	{Type: "calories_burned", Label: "Calories Burned", Unit: "kcal", Min: 0, Max: 20000},
+	{Type: "water_intake", Label: "Water Intake", Unit: "L", Min: 0, Max: 20, Conversions: map[string]func(float64) float64{
+		"mL": scale(0.001),
+	}},
+}
+
+// metricAliases maps names used by older clients onto catalog types.
+var metricAliases = map[string]string{
+	"blood_oxygen": "oxygen_saturation",
+	"spo2":         "oxygen_saturation",
+	"glucose":      "blood_glucose",
+}
+
+var metricsByType = func() map[string]metricDefinition {
+	m := make(map[string]metricDefinition, len(metricCatalog))
+	for _, def := range metricCatalog {
+		m[def.Type] = def
+	}
+	return m
+}()
+
+// canonicalMetricType resolves case and aliases. Unknown types are returned
+// lower-cased so that filters on them simply match nothing.
+func canonicalMetricType(metricType string) string {
+	t := strings.ToLower(strings.TrimSpace(metricType))
+	if alias, ok := metricAliases[t]; ok {
+		return alias
+	}
+	return t
+}
+
+// CanonicalMetricType returns the catalog name of metricType, resolving case
+// and aliases, and whether the catalog has it.
+func CanonicalMetricType(metricType string) (string, bool) {
+	def, ok := lookupMetric(metricType)
+	return def.Type, ok
+}
+
+func lookupMetric(metricType string) (metricDefinition, bool) {
+	def, ok := metricsByType[canonicalMetricType(metricType)]
+	return def, ok
+}
+
+// normalize converts value from unit into the canonical unit and checks the
+// plausible range. An empty unit means the value already is canonical.
+func (d metricDefinition) normalize(value float64, unit string) (float64, error) {
+	if unit != "" && !strings.EqualFold(unit, d.Unit) {
+		convert, ok := d.conversion(unit)
+		if !ok {
+			return 0, fmt.Errorf("unsupported unit %q for %s", unit, d.Type)
+		}
+		value = convert(value)
+	}
+
+	if value < d.Min || value > d.Max {
+		return 0, fmt.Errorf("%s must be between %g and %g %s", d.Type, d.Min, d.Max, d.Unit)
+	}
+
+	return value, nil
+}
+
+func (d metricDefinition) conversion(unit string) (func(float64) float64, bool) {
+	for u, convert := range d.Conversions {
+		if strings.EqualFold(u, unit) {
+			return convert, true
+		}
+	}
+	return nil, false
+}
+
+func (h *health) GetMetricTypes() []entity.MetricTypeInfo {
+	types := make([]entity.MetricTypeInfo, 0, len(metricCatalog))
+	for _, def := range metricCatalog {
+		units := []string{def.Unit}
+		for u := range def.Conversions {
+			units = append(units, u)
+		}
+		slices.Sort(units[1:])
+
+		types = append(types, entity.MetricTypeInfo{
+			Type:  def.Type,
+			Label: def.Label,
+			Unit:  def.Unit,
+			Units: units,
+			Min:   def.Min,
+			Max:   def.Max,
+		})
+	}
+	return types
+}
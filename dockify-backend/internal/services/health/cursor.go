@@ -0,0 +1,43 @@
+package health
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+)
+
+// The cursor is the (recorded_at, id) keyset of the last row of a page. It is
+// base64 encoded so clients treat it as opaque.
+
+func encodeCursor(recordedAt time.Time, id int) string {
+	raw := recordedAt.UTC().Format(time.RFC3339Nano) + "," + strconv.Itoa(id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("%w: %v", entity.ErrInvalidCursor, err)
+	}
+
+	recordedAtPart, idPart, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return time.Time{}, 0, entity.ErrInvalidCursor
+	}
+
+	recordedAt, err := time.Parse(time.RFC3339Nano, recordedAtPart)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("%w: %v", entity.ErrInvalidCursor, err)
+	}
+
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("%w: %v", entity.ErrInvalidCursor, err)
+	}
+
+	return recordedAt, id, nil
+}
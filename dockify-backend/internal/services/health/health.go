@@ -2,45 +2,221 @@ package health
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/askaroe/dockify-backend/internal/entity"
 	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/notifier"
 	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/askaroe/dockify-backend/pkg/utils"
+)
+
+const (
+	defaultMetricsLimit = 100
+	maxMetricsLimit     = 1000
+
+	// maxClockSkew is how far in the future a device clock may be before recorded_at is rejected.
+	maxClockSkew = 5 * time.Minute
 )
 
 type Health interface {
-	GetMetricsByUserId(ctx context.Context, id int) (models.HealthMetrics, error)
-	CreateHealthMetric(ctx context.Context, req entity.HealthMetricsRequest) error
+	GetMetrics(ctx context.Context, query entity.HealthMetricsQuery) (entity.HealthMetricsPage, error)
+	AggregateMetrics(ctx context.Context, query entity.MetricAggregateQuery) (entity.MetricAggregateResponse, error)
+	GetMetricTypes() []entity.MetricTypeInfo
+	CreateHealthMetric(ctx context.Context, req entity.HealthMetricsRequest) (entity.MetricsIngestResponse, error)
+
+	BloodPressure
+	Workouts
+	SleepSessions
+	AlertRules
 }
 
 type health struct {
-	repo *repository.Repository
+	repo     *repository.Repository
+	notifier notifier.Notifier
+	logger   *utils.Logger
 }
 
-func NewHealthService(repo *repository.Repository) Health {
-	return &health{repo: repo}
+func NewHealthService(repo *repository.Repository, n notifier.Notifier, logger *utils.Logger) Health {
+	return &health{repo: repo, notifier: n, logger: logger}
 }
 
-func (h *health) GetMetricsByUserId(ctx context.Context, id int) (models.HealthMetrics, error) {
-	return h.repo.Health.GetMetricsByUserId(ctx, id)
+func (h *health) GetMetrics(ctx context.Context, query entity.HealthMetricsQuery) (entity.HealthMetricsPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultMetricsLimit
+	}
+	limit = min(limit, maxMetricsLimit)
+
+	metricTypes := make([]string, 0, len(query.MetricTypes))
+	for _, metricType := range query.MetricTypes {
+		metricTypes = append(metricTypes, canonicalMetricType(metricType))
+	}
+
+	filter := models.HealthMetricsFilter{
+		UserId:      query.UserId,
+		MetricTypes: metricTypes,
+		Descending:  query.Order != entity.OrderAsc,
+		// fetch one extra row to find out whether another page exists
+		Limit: limit + 1,
+	}
+
+	if query.From != nil {
+		from := query.From.UTC()
+		filter.From = &from
+	}
+	if query.To != nil {
+		to := query.To.UTC()
+		filter.To = &to
+	}
+
+	if query.Cursor != "" {
+		recordedAt, id, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return entity.HealthMetricsPage{}, err
+		}
+		filter.AfterRecordedAt = &recordedAt
+		filter.AfterID = id
+	}
+
+	metrics, err := h.repo.Health.GetMetrics(ctx, filter)
+	if err != nil {
+		return entity.HealthMetricsPage{}, fmt.Errorf("get health metrics: %w", err)
+	}
+
+	page := entity.HealthMetricsPage{
+		Metrics: metrics,
+		Limit:   limit,
+	}
+
+	if len(metrics) > limit {
+		page.Metrics = metrics[:limit]
+		page.HasMore = true
+
+		last := page.Metrics[limit-1]
+		if last.RecordedAt != nil {
+			page.NextCursor = encodeCursor(*last.RecordedAt, last.ID)
+		}
+	}
+
+	if page.Metrics == nil {
+		page.Metrics = []models.HealthMetrics{}
+	}
+
+	return page, nil
 }
 
-func (h *health) CreateHealthMetric(ctx context.Context, req entity.HealthMetricsRequest) error {
+// CreateHealthMetric validates every metric on its own: invalid items are
+// reported as rejected while the rest of the batch is stored together with
+// the upload location in one transaction. Newly inserted metrics are then
+// checked against the user's alert rules.
+func (h *health) CreateHealthMetric(ctx context.Context, req entity.HealthMetricsRequest) (entity.MetricsIngestResponse, error) {
+	response := entity.MetricsIngestResponse{Results: make([]entity.MetricResult, len(req.Metrics))}
+
+	now := time.Now().UTC()
 	var metricsModel []models.HealthMetrics
+	var indexes []int
+
+	for i, metric := range req.Metrics {
+		response.Results[i] = entity.MetricResult{Index: i, MetricType: metric.MetricType}
 
-	for _, metric := range req.Metrics {
-		metricsModel = append(metricsModel, models.HealthMetrics{
-			UserId:      req.UserId,
-			MetricType:  metric.MetricType,
-			MetricValue: metric.MetricValue,
+		model, err := toMetricModel(req.UserId, metric, now)
+		if err != nil {
+			response.Results[i].Status = entity.MetricStatusRejected
+			response.Results[i].Error = err.Error()
+			response.Rejected++
+			continue
+		}
+
+		metricsModel = append(metricsModel, model)
+		indexes = append(indexes, i)
+	}
+
+	var upserted []models.UpsertResult
+	err := h.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		if len(metricsModel) > 0 {
+			var err error
+			upserted, err = tx.Health.CreateHealthMetrics(ctx, metricsModel)
+			if err != nil {
+				return fmt.Errorf("create health metrics: %w", err)
+			}
+		}
+
+		err := tx.Location.Insert(ctx, models.Location{
+			UserId:    req.UserId,
+			Latitude:  req.Location.Latitude,
+			Longitude: req.Location.Longitude,
 		})
+		if err != nil {
+			return fmt.Errorf("create location: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entity.MetricsIngestResponse{}, err
+	}
+
+	var inserted []models.HealthMetrics
+	for j, result := range upserted {
+		item := &response.Results[indexes[j]]
+		item.ID = result.ID
+		if result.Inserted {
+			item.Status = entity.MetricStatusInserted
+			response.Inserted++
+			inserted = append(inserted, metricsModel[j])
+		} else {
+			item.Status = entity.MetricStatusDuplicate
+			response.Duplicate++
+		}
+	}
+
+	// the metrics are stored, so alerts go out even when the client disconnects
+	h.evaluateAlerts(context.WithoutCancel(ctx), req.UserId, inserted)
+
+	return response, nil
+}
+
+func toMetricModel(userID int, metric entity.HealthMetric, now time.Time) (models.HealthMetrics, error) {
+	value, err := normalizeMetric(metric)
+	if err != nil {
+		return models.HealthMetrics{}, err
+	}
+
+	recordedAt := now
+	if metric.RecordedAt != nil {
+		recordedAt = metric.RecordedAt.UTC()
+		if recordedAt.After(now.Add(maxClockSkew)) {
+			return models.HealthMetrics{}, errors.New("recorded_at is in the future")
+		}
+	}
+
+	model := models.HealthMetrics{
+		UserId:      userID,
+		MetricType:  canonicalMetricType(metric.MetricType),
+		MetricValue: value,
+		Source:      metric.Source,
+		RecordedAt:  &recordedAt,
+	}
+	if metric.ExternalID != "" {
+		model.ExternalID = &metric.ExternalID
+	}
+
+	return model, nil
+}
+
+func normalizeMetric(metric entity.HealthMetric) (float64, error) {
+	def, ok := lookupMetric(metric.MetricType)
+	if !ok {
+		return 0, fmt.Errorf("unknown metric type %q", metric.MetricType)
 	}
 
-	err := h.repo.Health.CreateHealthMetrics(ctx, metricsModel)
+	value, err := metric.MetricValue.Float64()
 	if err != nil {
-		return fmt.Errorf("create health metrics: %w", err)
+		return 0, errors.New("metric_value must be a number")
 	}
 
-	return nil
+	return def.normalize(value, metric.Unit)
 }
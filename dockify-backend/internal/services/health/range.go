@@ -0,0 +1,45 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+func toTimeRangeFilter(query entity.TimeRangeQuery) models.TimeRangeFilter {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultMetricsLimit
+	}
+
+	filter := models.TimeRangeFilter{UserId: query.UserId, Limit: min(limit, maxMetricsLimit)}
+	if query.From != nil {
+		from := query.From.UTC()
+		filter.From = &from
+	}
+	if query.To != nil {
+		to := query.To.UTC()
+		filter.To = &to
+	}
+	return filter
+}
+
+// notFound maps a missing row to entity.ErrNotFound for the handlers.
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrNotFound
+	}
+	return err
+}
+
+// checkRange validates a value against the catalog range of metricType.
+func checkRange(metricType string, value float64) error {
+	def, _ := lookupMetric(metricType)
+	if _, err := def.normalize(value, ""); err != nil {
+		return fmt.Errorf("%w: %v", entity.ErrInvalidParam, err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+const sleepStageAwake = "awake"
+
+var (
+	sleepStages = []string{sleepStageAwake, "light", "deep", "rem"}
+	// sleepNotes are the lifestyle notes understood by the MindSpore sleep model.
+	sleepNotes = []string{"coffee", "tea", "workout", "stress", "ate_late"}
+)
+
+type SleepSessions interface {
+	CreateSleepSession(ctx context.Context, userID int, req entity.SleepSessionRequest) (models.SleepSession, error)
+	GetSleepSession(ctx context.Context, userID, id int) (models.SleepSession, error)
+	GetSleepSessions(ctx context.Context, query entity.TimeRangeQuery) ([]models.SleepSession, error)
+	UpdateSleepSession(ctx context.Context, userID, id int, req entity.SleepSessionRequest) (models.SleepSession, error)
+	DeleteSleepSession(ctx context.Context, userID, id int) error
+}
+
+func (h *health) CreateSleepSession(ctx context.Context, userID int, req entity.SleepSessionRequest) (models.SleepSession, error) {
+	session, err := toSleepSessionModel(userID, req)
+	if err != nil {
+		return models.SleepSession{}, err
+	}
+
+	session.ID, err = h.repo.Health.CreateSleepSession(ctx, session)
+	if err != nil {
+		return models.SleepSession{}, fmt.Errorf("create sleep session: %w", err)
+	}
+
+	summarizeSleep(&session)
+	return session, nil
+}
+
+func (h *health) GetSleepSession(ctx context.Context, userID, id int) (models.SleepSession, error) {
+	session, err := h.repo.Health.GetSleepSession(ctx, userID, id)
+	if err != nil {
+		return models.SleepSession{}, notFound(err)
+	}
+
+	summarizeSleep(&session)
+	return session, nil
+}
+
+func (h *health) GetSleepSessions(ctx context.Context, query entity.TimeRangeQuery) ([]models.SleepSession, error) {
+	sessions, err := h.repo.Health.GetSleepSessions(ctx, toTimeRangeFilter(query))
+	if err != nil {
+		return nil, fmt.Errorf("get sleep sessions: %w", err)
+	}
+
+	for i := range sessions {
+		summarizeSleep(&sessions[i])
+	}
+
+	if sessions == nil {
+		sessions = []models.SleepSession{}
+	}
+	return sessions, nil
+}
+
+func (h *health) UpdateSleepSession(ctx context.Context, userID, id int, req entity.SleepSessionRequest) (models.SleepSession, error) {
+	session, err := toSleepSessionModel(userID, req)
+	if err != nil {
+		return models.SleepSession{}, err
+	}
+	session.ID = id
+
+	if err := h.repo.Health.UpdateSleepSession(ctx, session); err != nil {
+		return models.SleepSession{}, notFound(err)
+	}
+
+	summarizeSleep(&session)
+	return session, nil
+}
+
+func (h *health) DeleteSleepSession(ctx context.Context, userID, id int) error {
+	return notFound(h.repo.Health.DeleteSleepSession(ctx, userID, id))
+}
+
+// summarizeSleep fills the derived fields. Without stage data the whole time
+// in bed is counted as sleep. Devices may report overlapping stages, so time
+// covered by several of them is only counted once.
+func summarizeSleep(session *models.SleepSession) {
+	inBed := session.EndedAt.Sub(session.StartedAt)
+	asleep := inBed
+
+	if len(session.Stages) > 0 {
+		var stages []models.SleepStage
+		for _, stage := range session.Stages {
+			if stage.Stage != sleepStageAwake {
+				stages = append(stages, stage)
+			}
+		}
+		slices.SortFunc(stages, func(a, b models.SleepStage) int {
+			return a.StartedAt.Compare(b.StartedAt)
+		})
+
+		asleep = 0
+		var coveredUntil time.Time
+		for _, stage := range stages {
+			start := stage.StartedAt
+			if start.Before(coveredUntil) {
+				start = coveredUntil
+			}
+			if stage.EndedAt.After(start) {
+				asleep += stage.EndedAt.Sub(start)
+				coveredUntil = stage.EndedAt
+			}
+		}
+	}
+
+	session.TimeInBedHours = inBed.Hours()
+	session.SleepDurationHours = asleep.Hours()
+	if inBed > 0 {
+		session.SleepEfficiency = asleep.Seconds() / inBed.Seconds()
+	}
+}
+
+func toSleepSessionModel(userID int, req entity.SleepSessionRequest) (models.SleepSession, error) {
+	duration := req.EndedAt.Sub(req.StartedAt)
+	if duration <= 0 || duration > maxSessionDuration {
+		return models.SleepSession{}, fmt.Errorf("%w: ended_at must be after started_at and within 24 hours", entity.ErrInvalidParam)
+	}
+
+	stages := make([]models.SleepStage, 0, len(req.Stages))
+	for _, stage := range req.Stages {
+		name := strings.ToLower(stage.Stage)
+		if !slices.Contains(sleepStages, name) {
+			return models.SleepSession{}, fmt.Errorf("%w: unknown sleep stage %q", entity.ErrInvalidParam, stage.Stage)
+		}
+		if !stage.EndedAt.After(stage.StartedAt) || stage.StartedAt.Before(req.StartedAt) || stage.EndedAt.After(req.EndedAt) {
+			return models.SleepSession{}, fmt.Errorf("%w: sleep stages must lie within the session", entity.ErrInvalidParam)
+		}
+		stages = append(stages, models.SleepStage{Stage: name, StartedAt: stage.StartedAt.UTC(), EndedAt: stage.EndedAt.UTC()})
+	}
+
+	notes := make([]string, 0, len(req.Notes))
+	for _, note := range req.Notes {
+		note = strings.ToLower(note)
+		if !slices.Contains(sleepNotes, note) {
+			return models.SleepSession{}, fmt.Errorf("%w: unknown sleep note %q", entity.ErrInvalidParam, note)
+		}
+		if !slices.Contains(notes, note) {
+			notes = append(notes, note)
+		}
+	}
+
+	if req.AvgHeartRate != nil {
+		if err := checkRange("heart_rate", float64(*req.AvgHeartRate)); err != nil {
+			return models.SleepSession{}, err
+		}
+	}
+	if req.MovementsPerHour != nil && *req.MovementsPerHour < 0 {
+		return models.SleepSession{}, fmt.Errorf("%w: movements_per_hour must not be negative", entity.ErrInvalidParam)
+	}
+	if req.SnoreMinutes != nil && (*req.SnoreMinutes < 0 || float64(*req.SnoreMinutes) > duration.Minutes()) {
+		return models.SleepSession{}, fmt.Errorf("%w: snore_minutes must be within the session", entity.ErrInvalidParam)
+	}
+
+	return models.SleepSession{
+		UserId:           userID,
+		StartedAt:        req.StartedAt.UTC(),
+		EndedAt:          req.EndedAt.UTC(),
+		Stages:           stages,
+		AvgHeartRate:     req.AvgHeartRate,
+		MovementsPerHour: req.MovementsPerHour,
+		SnoreMinutes:     req.SnoreMinutes,
+		Notes:            notes,
+		Source:           req.Source,
+	}, nil
+}
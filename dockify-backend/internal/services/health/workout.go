@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+const maxSessionDuration = 24 * time.Hour
+
+type Workouts interface {
+	CreateWorkout(ctx context.Context, userID int, req entity.WorkoutRequest) (models.Workout, error)
+	GetWorkout(ctx context.Context, userID, id int) (models.Workout, error)
+	GetWorkouts(ctx context.Context, query entity.TimeRangeQuery) ([]models.Workout, error)
+	UpdateWorkout(ctx context.Context, userID, id int, req entity.WorkoutRequest) (models.Workout, error)
+	DeleteWorkout(ctx context.Context, userID, id int) error
+}
+
+func (h *health) CreateWorkout(ctx context.Context, userID int, req entity.WorkoutRequest) (models.Workout, error) {
+	workout, err := toWorkoutModel(userID, req)
+	if err != nil {
+		return models.Workout{}, err
+	}
+
+	workout.ID, err = h.repo.Health.CreateWorkout(ctx, workout)
+	if err != nil {
+		return models.Workout{}, fmt.Errorf("create workout: %w", err)
+	}
+
+	return workout, nil
+}
+
+func (h *health) GetWorkout(ctx context.Context, userID, id int) (models.Workout, error) {
+	workout, err := h.repo.Health.GetWorkout(ctx, userID, id)
+	return workout, notFound(err)
+}
+
+func (h *health) GetWorkouts(ctx context.Context, query entity.TimeRangeQuery) ([]models.Workout, error) {
+	workouts, err := h.repo.Health.GetWorkouts(ctx, toTimeRangeFilter(query))
+	if err != nil {
+		return nil, fmt.Errorf("get workouts: %w", err)
+	}
+
+	if workouts == nil {
+		workouts = []models.Workout{}
+	}
+	return workouts, nil
+}
+
+func (h *health) UpdateWorkout(ctx context.Context, userID, id int, req entity.WorkoutRequest) (models.Workout, error) {
+	workout, err := toWorkoutModel(userID, req)
+	if err != nil {
+		return models.Workout{}, err
+	}
+	workout.ID = id
+
+	if err := h.repo.Health.UpdateWorkout(ctx, workout); err != nil {
+		return models.Workout{}, notFound(err)
+	}
+
+	return workout, nil
+}
+
+func (h *health) DeleteWorkout(ctx context.Context, userID, id int) error {
+	return notFound(h.repo.Health.DeleteWorkout(ctx, userID, id))
+}
+
+func toWorkoutModel(userID int, req entity.WorkoutRequest) (models.Workout, error) {
+	workoutType := strings.ToLower(strings.TrimSpace(req.WorkoutType))
+	if workoutType == "" || len(workoutType) > 50 {
+		return models.Workout{}, fmt.Errorf("%w: workout_type must be 1-50 characters", entity.ErrInvalidParam)
+	}
+
+	duration := req.EndedAt.Sub(req.StartedAt)
+	if duration <= 0 || duration > maxSessionDuration {
+		return models.Workout{}, fmt.Errorf("%w: ended_at must be after started_at and within 24 hours", entity.ErrInvalidParam)
+	}
+
+	if req.CaloriesBurned != nil {
+		if err := checkRange("calories_burned", *req.CaloriesBurned); err != nil {
+			return models.Workout{}, err
+		}
+	}
+	for _, bpm := range []*int{req.AvgBpm, req.MaxBpm} {
+		if bpm == nil {
+			continue
+		}
+		if err := checkRange("heart_rate", float64(*bpm)); err != nil {
+			return models.Workout{}, err
+		}
+	}
+	if req.AvgBpm != nil && req.MaxBpm != nil && *req.AvgBpm > *req.MaxBpm {
+		return models.Workout{}, fmt.Errorf("%w: avg_bpm must not exceed max_bpm", entity.ErrInvalidParam)
+	}
+
+	return models.Workout{
+		UserId:          userID,
+		WorkoutType:     workoutType,
+		StartedAt:       req.StartedAt.UTC(),
+		EndedAt:         req.EndedAt.UTC(),
+		DurationMinutes: duration.Minutes(),
+		CaloriesBurned:  req.CaloriesBurned,
+		AvgBpm:          req.AvgBpm,
+		MaxBpm:          req.MaxBpm,
+		Source:          req.Source,
+	}, nil
+}
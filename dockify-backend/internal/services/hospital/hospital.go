@@ -0,0 +1,110 @@
+package hospital
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/askaroe/dockify-backend/pkg/openinghours"
+)
+
+const (
+	defaultRadius = 5000
+	maxRadius     = 50000
+	maxHospitals  = 100
+)
+
+type Hospital interface {
+	GetNearestHospitals(ctx context.Context, request entity.NearestHospitalsRequest) ([]entity.HospitalResponse, error)
+	ImportHospitals(ctx context.Context, hospitals []models.Hospital) (entity.HospitalImportReport, error)
+}
+
+type hospital struct {
+	repo *repository.Repository
+}
+
+func NewHospitalService(repo *repository.Repository) Hospital {
+	return &hospital{repo: repo}
+}
+
+func (h *hospital) GetNearestHospitals(ctx context.Context, request entity.NearestHospitalsRequest) ([]entity.HospitalResponse, error) {
+	if request.Latitude < -90 || request.Latitude > 90 || request.Longitude < -180 || request.Longitude > 180 {
+		return nil, fmt.Errorf("%w: latitude or longitude out of range", entity.ErrInvalidParam)
+	}
+
+	radius := request.Radius
+	if radius <= 0 {
+		radius = defaultRadius
+	}
+
+	hospitals, err := h.repo.Hospital.GetNearestHospitals(ctx, models.HospitalFilter{
+		Latitude:      request.Latitude,
+		Longitude:     request.Longitude,
+		Radius:        min(radius, maxRadius),
+		Amenity:       strings.ToLower(strings.TrimSpace(request.Amenity)),
+		Specialty:     strings.ToLower(strings.TrimSpace(request.Specialty)),
+		EmergencyOnly: request.EmergencyOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get nearest hospitals: %w", err)
+	}
+
+	now := time.Now()
+	response := make([]entity.HospitalResponse, 0, len(hospitals))
+	for _, hp := range hospitals {
+		openNow := isOpen(hp, now)
+		if request.OpenNow && (openNow == nil || !*openNow) {
+			continue
+		}
+
+		response = append(response, entity.HospitalResponse{
+			ID:             hp.ID,
+			Amenity:        hp.Amenity,
+			Name:           hp.Name,
+			Address:        hp.Address,
+			Phone:          hp.Phone,
+			Specialties:    hp.Specialties,
+			Emergency:      hp.Emergency,
+			OpeningHours:   hp.OpeningHours,
+			OpenNow:        openNow,
+			Latitude:       hp.Latitude,
+			Longitude:      hp.Longitude,
+			DistanceMeters: hp.Distance,
+		})
+		if len(response) == maxHospitals {
+			break
+		}
+	}
+
+	return response, nil
+}
+
+// isOpen evaluates the hospital's opening hours at now in its own timezone. It
+// returns nil when the hours are unknown or use syntax the parser does not
+// support. Emergency departments without opening hours are taken as always open.
+func isOpen(hp models.Hospital, now time.Time) *bool {
+	if hp.OpeningHours == "" {
+		if hp.Emergency {
+			open := true
+			return &open
+		}
+		return nil
+	}
+
+	schedule, err := openinghours.Parse(hp.OpeningHours)
+	if err != nil {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(hp.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	open := schedule.IsOpen(now.In(loc))
+	return &open
+}
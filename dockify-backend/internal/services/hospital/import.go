@@ -0,0 +1,174 @@
+package hospital
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/askaroe/dockify-backend/pkg/geo"
+)
+
+const (
+	// entries with the same name closer than this are the same place
+	duplicateDistance = 100 // metres
+
+	skipDuplicate = "duplicate"
+)
+
+// ImportHospitals upserts the hospitals in one transaction. Entries of the
+// batch describing the same place are merged first; each remaining entry then
+// updates the stored row with the same source id, or the same name nearby, and
+// is inserted otherwise. Running an import twice leaves the table unchanged.
+func (h *hospital) ImportHospitals(ctx context.Context, hospitals []models.Hospital) (entity.HospitalImportReport, error) {
+	report := entity.HospitalImportReport{Read: len(hospitals), Skipped: make(map[string]int)}
+
+	unique := deduplicate(hospitals)
+	if duplicates := len(hospitals) - len(unique); duplicates > 0 {
+		report.Skipped[skipDuplicate] = duplicates
+	}
+
+	err := h.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		for _, hp := range unique {
+			existing, err := tx.Hospital.FindHospitalMatch(ctx, hp, duplicateDistance)
+			if errors.Is(err, pgx.ErrNoRows) {
+				if _, err := tx.Hospital.CreateHospital(ctx, hp); err != nil {
+					return err
+				}
+				report.Inserted++
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			updated := mergeImported(existing, hp)
+			if sameHospital(updated, existing) {
+				report.Unchanged++
+				continue
+			}
+			if err := tx.Hospital.UpdateHospital(ctx, updated); err != nil {
+				return err
+			}
+			report.Updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.HospitalImportReport{}, fmt.Errorf("import hospitals: %w", err)
+	}
+
+	return report, nil
+}
+
+// deduplicate merges entries with the same normalized name within
+// duplicateDistance of each other into the first of them.
+func deduplicate(hospitals []models.Hospital) []models.Hospital {
+	unique := make([]models.Hospital, 0, len(hospitals))
+	byName := make(map[string][]int)
+
+	for _, hp := range hospitals {
+		key := nameKey(hp.Name)
+		merged := false
+		for _, i := range byName[key] {
+			if geo.Distance(unique[i].Latitude, unique[i].Longitude, hp.Latitude, hp.Longitude) <= duplicateDistance {
+				unique[i] = fillMissing(unique[i], hp)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			byName[key] = append(byName[key], len(unique))
+			unique = append(unique, hp)
+		}
+	}
+
+	return unique
+}
+
+// fillMissing completes a with the details only b has.
+func fillMissing(a, b models.Hospital) models.Hospital {
+	if a.Address == "" {
+		a.Address = b.Address
+	}
+	if a.Phone == "" {
+		a.Phone = b.Phone
+	}
+	if a.OpeningHours == "" {
+		a.OpeningHours = b.OpeningHours
+	}
+	if a.ExternalID == nil {
+		a.Source, a.ExternalID = b.Source, b.ExternalID
+	}
+	for _, s := range b.Specialties {
+		if !slices.Contains(a.Specialties, s) {
+			a.Specialties = append(a.Specialties, s)
+		}
+	}
+	a.Emergency = a.Emergency || b.Emergency
+	return a
+}
+
+// mergeImported applies an imported entry to the stored row: the import wins
+// for every detail it has, so corrections in the source data come through,
+// while details it lacks are kept. A row imported from another source keeps
+// that source's id and details and only has its gaps filled, otherwise
+// importing both sources would flip the row back and forth.
+func mergeImported(existing, imported models.Hospital) models.Hospital {
+	if existing.ExternalID != nil && existing.Source != imported.Source {
+		return fillMissing(existing, imported)
+	}
+
+	updated := imported
+	updated.ID = existing.ID
+	if updated.Address == "" {
+		updated.Address = existing.Address
+	}
+	if updated.Phone == "" {
+		updated.Phone = existing.Phone
+	}
+	if updated.OpeningHours == "" {
+		updated.OpeningHours = existing.OpeningHours
+	}
+	if len(updated.Specialties) == 0 {
+		updated.Specialties = existing.Specialties
+	}
+	if updated.ExternalID == nil {
+		updated.Source, updated.ExternalID = existing.Source, existing.ExternalID
+	}
+	// a matching entry of another source must not clear the flag
+	if existing.Source != imported.Source {
+		updated.Emergency = updated.Emergency || existing.Emergency
+	}
+	return updated
+}
+
+func sameHospital(a, b models.Hospital) bool {
+	return a.Amenity == b.Amenity && a.Name == b.Name && a.Address == b.Address && a.Phone == b.Phone &&
+		slices.Equal(a.Specialties, b.Specialties) && a.Emergency == b.Emergency &&
+		a.OpeningHours == b.OpeningHours && a.Timezone == b.Timezone &&
+		math.Abs(a.Latitude-b.Latitude) < 1e-7 && math.Abs(a.Longitude-b.Longitude) < 1e-7 &&
+		a.Source == b.Source && equalID(a.ExternalID, b.ExternalID)
+}
+
+func equalID(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// nameKey ignores case, punctuation and spacing differences between names.
+func nameKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
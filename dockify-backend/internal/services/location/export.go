@@ -0,0 +1,224 @@
+package location
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+const (
+	ExportGeoJSON = "geojson"
+	ExportGPX     = "gpx"
+
+	defaultExportWindow = 30 * 24 * time.Hour
+	maxExportWindow     = 366 * 24 * time.Hour
+	// a heart rate measured further than this from a location is not attached
+	heartRateWindow = 2 * time.Minute
+	// longer gaps between locations start a new track segment
+	trackGap = time.Hour
+)
+
+// ExportLocationHistory writes the user's locations in the query range to w
+// as GeoJSON or GPX 1.1. Points are streamed from the database, so memory use
+// does not grow with the history; invalid queries fail before anything is
+// written.
+func (l *location) ExportLocationHistory(ctx context.Context, query entity.LocationExportQuery, w io.Writer) error {
+	if query.Format != ExportGeoJSON && query.Format != ExportGPX {
+		return fmt.Errorf("%w: format must be geojson or gpx", entity.ErrInvalidParam)
+	}
+
+	to := time.Now().UTC()
+	if query.To != nil {
+		to = query.To.UTC()
+	}
+	from := to.Add(-defaultExportWindow)
+	if query.From != nil {
+		from = query.From.UTC()
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("%w: from must be before to", entity.ErrInvalidParam)
+	}
+	if to.Sub(from) > maxExportWindow {
+		return fmt.Errorf("%w: range must not exceed 366 days", entity.ErrInvalidParam)
+	}
+
+	filter := models.TrackFilter{UserId: query.UserId, From: from, To: to}
+	if query.HeartRate {
+		filter.HeartRateWindow = heartRateWindow
+	}
+
+	ew := &exportWriter{w: bufio.NewWriter(w)}
+	var err error
+	if query.Format == ExportGPX {
+		err = l.exportGPX(ctx, filter, ew)
+	} else {
+		err = l.exportGeoJSON(ctx, filter, ew)
+	}
+	if err != nil {
+		return fmt.Errorf("export location history: %w", err)
+	}
+
+	if err := ew.flush(); err != nil {
+		return fmt.Errorf("export location history: %w", err)
+	}
+	return nil
+}
+
+// exportGPX writes a single track with a segment per stretch of locations
+// without gaps, attaching heart rates as Garmin TrackPointExtension elements.
+func (l *location) exportGPX(ctx context.Context, filter models.TrackFilter, w *exportWriter) error {
+	w.print(xml.Header)
+	w.print(`<gpx version="1.1" creator="Dockify" xmlns="http://www.topografix.com/GPX/1/1"` +
+		` xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">` + "\n")
+	w.printf("  <metadata>\n    <name>Dockify location history</name>\n    <time>%s</time>\n  </metadata>\n",
+		time.Now().UTC().Format(time.RFC3339))
+	w.print("  <trk>\n    <name>Location history</name>\n")
+
+	var last time.Time
+	inSegment := false
+	err := l.repo.Location.StreamTrack(ctx, filter, func(p models.TrackPoint) error {
+		if inSegment && p.RecordedAt.Sub(last) > trackGap {
+			w.print("    </trkseg>\n")
+			inSegment = false
+		}
+		if !inSegment {
+			w.print("    <trkseg>\n")
+			inSegment = true
+		}
+
+		w.printf(`      <trkpt lat="%s" lon="%s"><time>%s</time>`,
+			formatCoordinate(p.Latitude), formatCoordinate(p.Longitude), p.RecordedAt.UTC().Format(time.RFC3339))
+		if p.HeartRate != nil {
+			w.printf("<extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>%d</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>",
+				int(math.Round(*p.HeartRate)))
+		}
+		w.print("</trkpt>\n")
+
+		last = p.RecordedAt
+		return w.err
+	})
+	if err != nil {
+		return err
+	}
+
+	if inSegment {
+		w.print("    </trkseg>\n")
+	}
+	w.print("  </trk>\n</gpx>\n")
+	return w.err
+}
+
+// exportGeoJSON writes a FeatureCollection with a LineString per stretch of
+// locations without gaps, followed by a Point per location. The tracks come
+// from a first pass over the history and the points from a second, so neither
+// has to be held in memory.
+func (l *location) exportGeoJSON(ctx context.Context, filter models.TrackFilter, w *exportWriter) error {
+	w.print(`{"type":"FeatureCollection","features":[`)
+	first := true
+	separator := func() {
+		if !first {
+			w.print(",")
+		}
+		first = false
+		w.print("\n")
+	}
+
+	// a track is only opened on its second point since a LineString needs two
+	var pending, last models.TrackPoint
+	var start time.Time
+	points := 0
+	closeTrack := func() {
+		if points >= 2 {
+			w.printf(`]},"properties":{"kind":"track","started_at":%q,"ended_at":%q,"points":%d}}`,
+				start.UTC().Format(time.RFC3339), last.RecordedAt.UTC().Format(time.RFC3339), points)
+		}
+		points = 0
+	}
+
+	trackFilter := filter
+	trackFilter.HeartRateWindow = 0
+	err := l.repo.Location.StreamTrack(ctx, trackFilter, func(p models.TrackPoint) error {
+		if points > 0 && p.RecordedAt.Sub(last.RecordedAt) > trackGap {
+			closeTrack()
+		}
+
+		switch points {
+		case 0:
+			pending, start = p, p.RecordedAt
+		case 1:
+			separator()
+			w.printf(`{"type":"Feature","geometry":{"type":"LineString","coordinates":[%s,%s`,
+				position(pending), position(p))
+		default:
+			w.print("," + position(p))
+		}
+
+		points++
+		last = p
+		return w.err
+	})
+	if err != nil {
+		return err
+	}
+	closeTrack()
+
+	err = l.repo.Location.StreamTrack(ctx, filter, func(p models.TrackPoint) error {
+		separator()
+		w.printf(`{"type":"Feature","geometry":{"type":"Point","coordinates":%s},"properties":{"kind":"point","recorded_at":%q`,
+			position(p), p.RecordedAt.UTC().Format(time.RFC3339))
+		if p.HeartRate != nil {
+			w.printf(`,"heart_rate":%s`, strconv.FormatFloat(*p.HeartRate, 'f', -1, 64))
+		}
+		w.print("}}")
+		return w.err
+	})
+	if err != nil {
+		return err
+	}
+
+	w.print("\n]}\n")
+	return w.err
+}
+
+// position formats a GeoJSON position, longitude first.
+func position(p models.TrackPoint) string {
+	return "[" + formatCoordinate(p.Longitude) + "," + formatCoordinate(p.Latitude) + "]"
+}
+
+func formatCoordinate(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// exportWriter buffers the output and keeps the first write error, which
+// stops the database iteration when the client goes away.
+type exportWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *exportWriter) print(s string) {
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+func (e *exportWriter) printf(format string, args ...any) {
+	if e.err == nil {
+		_, e.err = fmt.Fprintf(e.w, format, args...)
+	}
+}
+
+func (e *exportWriter) flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
@@ -0,0 +1,112 @@
+package location
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+const (
+	defaultHistoryWindow = 24 * time.Hour
+	maxHistoryWindow     = 31 * 24 * time.Hour
+	defaultHistoryLimit  = 1000
+	maxHistoryLimit      = 10000
+	// visits and trips need every point of the range
+	maxSegmentedPoints = 20000
+)
+
+func (l *location) GetLocationHistory(ctx context.Context, query entity.TimeRangeQuery) ([]entity.LocationPoint, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	points, err := l.history(ctx, query, min(limit, maxHistoryLimit))
+	if err != nil {
+		return nil, fmt.Errorf("get location history: %w", err)
+	}
+	return points, nil
+}
+
+func (l *location) GetVisits(ctx context.Context, query entity.TimeRangeQuery) ([]entity.VisitResponse, error) {
+	points, err := l.segmentedHistory(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get visits: %w", err)
+	}
+
+	clusters := findVisits(points)
+	visits := make([]entity.VisitResponse, 0, len(clusters))
+	for _, v := range clusters {
+		visits = append(visits, v.response(points))
+	}
+	return visits, nil
+}
+
+func (l *location) GetTrips(ctx context.Context, query entity.TimeRangeQuery) ([]entity.TripResponse, error) {
+	points, err := l.segmentedHistory(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get trips: %w", err)
+	}
+
+	trips := findTrips(points, findVisits(points))
+	if trips == nil {
+		trips = []entity.TripResponse{}
+	}
+	return trips, nil
+}
+
+func (l *location) segmentedHistory(ctx context.Context, query entity.TimeRangeQuery) ([]entity.LocationPoint, error) {
+	points, err := l.history(ctx, query, maxSegmentedPoints+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) > maxSegmentedPoints {
+		return nil, fmt.Errorf("%w: more than %d locations in range, choose a shorter one", entity.ErrInvalidParam, maxSegmentedPoints)
+	}
+	return points, nil
+}
+
+// history loads the points in the query range, which defaults to the last day
+// and may span at most maxHistoryWindow.
+func (l *location) history(ctx context.Context, query entity.TimeRangeQuery, limit int) ([]entity.LocationPoint, error) {
+	to := time.Now().UTC()
+	if query.To != nil {
+		to = query.To.UTC()
+	}
+	from := to.Add(-defaultHistoryWindow)
+	if query.From != nil {
+		from = query.From.UTC()
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", entity.ErrInvalidParam)
+	}
+	if to.Sub(from) > maxHistoryWindow {
+		return nil, fmt.Errorf("%w: range must not exceed 31 days", entity.ErrInvalidParam)
+	}
+
+	locations, err := l.repo.Location.GetLocationHistory(ctx, models.TimeRangeFilter{
+		UserId: query.UserId,
+		From:   &from,
+		To:     &to,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]entity.LocationPoint, 0, len(locations))
+	for _, loc := range locations {
+		if loc.RecordedAt == nil {
+			continue
+		}
+		points = append(points, entity.LocationPoint{
+			Latitude:   loc.Latitude.InexactFloat64(),
+			Longitude:  loc.Longitude.InexactFloat64(),
+			RecordedAt: loc.RecordedAt.UTC(),
+		})
+	}
+	return points, nil
+}
@@ -2,60 +2,194 @@ package location
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"time"
 
+	"github.com/askaroe/dockify-backend/config"
 	"github.com/askaroe/dockify-backend/internal/entity"
 	"github.com/askaroe/dockify-backend/internal/models"
 	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/askaroe/dockify-backend/pkg/geo"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	SharingOff      = "off"
+	SharingFriends  = "friends"
+	SharingEveryone = "everyone"
+
+	defaultGridMeters    = 500
+	defaultMaxAgeMinutes = 60
+	defaultRadius        = 5000
+	maxRadius            = 50000
 )
 
 type Location interface {
-	CreateLocation(ctx context.Context, req entity.HealthMetricsRequest) error
 	GetNearestUsers(ctx context.Context, request entity.NearestUsersRequest) ([]entity.NearestUsersResponse, error)
+	GetLocationSharing(ctx context.Context, userID int) (entity.LocationSharingResponse, error)
+	UpdateLocationSharing(ctx context.Context, request entity.LocationSharingRequest) (entity.LocationSharingResponse, error)
+
+	GetLocationHistory(ctx context.Context, query entity.TimeRangeQuery) ([]entity.LocationPoint, error)
+	GetVisits(ctx context.Context, query entity.TimeRangeQuery) ([]entity.VisitResponse, error)
+	GetTrips(ctx context.Context, query entity.TimeRangeQuery) ([]entity.TripResponse, error)
+	ExportLocationHistory(ctx context.Context, query entity.LocationExportQuery, w io.Writer) error
 }
 
 type location struct {
-	repo *repository.Repository
+	repo          *repository.Repository
+	gridMeters    int
+	maxAgeMinutes int
 }
 
-func NewLocationService(repo *repository.Repository) Location {
-	return &location{repo: repo}
+func NewLocationService(repo *repository.Repository, cfg config.LocationConfig) Location {
+	l := &location{repo: repo, gridMeters: cfg.LocationGridMeters, maxAgeMinutes: cfg.LocationMaxAgeMinutes}
+	if l.gridMeters <= 0 {
+		l.gridMeters = defaultGridMeters
+	}
+	if l.maxAgeMinutes <= 0 {
+		l.maxAgeMinutes = defaultMaxAgeMinutes
+	}
+	return l
 }
 
-func (l *location) CreateLocation(ctx context.Context, req entity.HealthMetricsRequest) error {
-	locationRecord := models.Location{
-		Latitude:  req.Location.Latitude,
-		Longitude: req.Location.Longitude,
-		UserId:    req.UserId,
+// GetNearestUsers returns the users near the point who share their location
+// with the caller: friends of users sharing with friends or everyone see the
+// exact position, anyone else only the grid cell of users sharing with
+// everyone. Blocked users in either direction and stale locations are left out.
+func (l *location) GetNearestUsers(ctx context.Context, request entity.NearestUsersRequest) ([]entity.NearestUsersResponse, error) {
+	if request.Latitude < -90 || request.Latitude > 90 || request.Longitude < -180 || request.Longitude > 180 {
+		return nil, fmt.Errorf("%w: latitude or longitude out of range", entity.ErrInvalidParam)
 	}
 
-	err := l.repo.Location.Insert(ctx, locationRecord)
-	if err != nil {
-		return fmt.Errorf("create location: %w", err)
+	radius := request.Radius
+	if radius <= 0 {
+		radius = defaultRadius
 	}
+	radius = min(radius, maxRadius)
 
-	return nil
-}
+	// the grid cell of a user just outside the radius may lie inside it
+	locations, err := l.repo.Location.GetNearestUsers(ctx, models.NearestUsersFilter{
+		Latitude:  request.Latitude,
+		Longitude: request.Longitude,
+		Radius:    radius + l.gridMeters,
+		MaxAge:    time.Duration(l.maxAgeMinutes) * time.Minute,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get nearest users: %w", err)
+	}
+	if len(locations) == 0 {
+		return nil, nil
+	}
 
-func (l *location) GetNearestUsers(ctx context.Context, request entity.NearestUsersRequest) ([]entity.NearestUsersResponse, error) {
-	locations, err := l.repo.Location.GetNearestUsers(ctx, request.Latitude, request.Longitude, request.Radius)
+	userIDs := make([]int, 0, len(locations))
+	for _, loc := range locations {
+		userIDs = append(userIDs, loc.UserId)
+	}
+	sharing, err := l.repo.User.GetLocationSharing(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get nearest users: %w", err)
+	}
+	friends, err := idSet(ctx, l.repo.Friend.GetFriendIDs, request.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("get nearest users: %w", err)
+	}
+	blocked, err := idSet(ctx, l.repo.Friend.GetBlockedUserIDs, request.UserId)
 	if err != nil {
 		return nil, fmt.Errorf("get nearest users: %w", err)
 	}
 
 	var response []entity.NearestUsersResponse
 	for _, loc := range locations {
-		if loc.UserId != request.UserId {
-			userLocation := entity.Location{
-				Latitude:  loc.Latitude,
-				Longitude: loc.Longitude,
+		if loc.UserId == request.UserId || blocked[loc.UserId] {
+			continue
+		}
+
+		friend := friends[loc.UserId]
+		switch sharing[loc.UserId] {
+		case SharingEveryone:
+		case SharingFriends:
+			if !friend {
+				continue
 			}
-			response = append(response, entity.NearestUsersResponse{
-				UserID:   loc.UserId,
-				Location: userLocation,
-			})
+		default:
+			continue
+		}
+
+		lat, lon := loc.Latitude.InexactFloat64(), loc.Longitude.InexactFloat64()
+		if !friend {
+			lat, lon = geo.SnapToGrid(lat, lon, float64(l.gridMeters))
 		}
+		// non-friends are matched by their cell, so the result does not
+		// reveal more than the position shown
+		if geo.Distance(request.Latitude, request.Longitude, lat, lon) > float64(radius) {
+			continue
+		}
+
+		response = append(response, entity.NearestUsersResponse{
+			UserID: loc.UserId,
+			Location: entity.Location{
+				Latitude:  decimal.NewFromFloat(lat),
+				Longitude: decimal.NewFromFloat(lon),
+			},
+			Approximate: !friend,
+			RecordedAt:  loc.RecordedAt,
+		})
 	}
 
 	return response, nil
 }
+
+func idSet(ctx context.Context, get func(ctx context.Context, userID int) ([]int, error), userID int) (map[int]bool, error) {
+	ids, err := get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set, nil
+}
+
+func (l *location) GetLocationSharing(ctx context.Context, userID int) (entity.LocationSharingResponse, error) {
+	modes, err := l.repo.User.GetLocationSharing(ctx, []int{userID})
+	if err != nil {
+		return entity.LocationSharingResponse{}, fmt.Errorf("get location sharing: %w", err)
+	}
+	mode, ok := modes[userID]
+	if !ok {
+		return entity.LocationSharingResponse{}, entity.ErrNotFound
+	}
+
+	return l.sharingResponse(mode), nil
+}
+
+func (l *location) UpdateLocationSharing(ctx context.Context, request entity.LocationSharingRequest) (entity.LocationSharingResponse, error) {
+	switch request.Mode {
+	case SharingOff, SharingFriends, SharingEveryone:
+	default:
+		return entity.LocationSharingResponse{}, fmt.Errorf("%w: mode must be off, friends or everyone", entity.ErrInvalidParam)
+	}
+
+	err := l.repo.User.SetLocationSharing(ctx, request.UserId, request.Mode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.LocationSharingResponse{}, entity.ErrNotFound
+	}
+	if err != nil {
+		return entity.LocationSharingResponse{}, fmt.Errorf("update location sharing: %w", err)
+	}
+
+	return l.sharingResponse(request.Mode), nil
+}
+
+func (l *location) sharingResponse(mode string) entity.LocationSharingResponse {
+	return entity.LocationSharingResponse{
+		Mode:          mode,
+		GridMeters:    l.gridMeters,
+		MaxAgeMinutes: l.maxAgeMinutes,
+	}
+}
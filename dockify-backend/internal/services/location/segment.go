@@ -0,0 +1,163 @@
+package location
+
+import (
+	"math"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/pkg/geo"
+)
+
+const (
+	// visitRadius is how far from the centroid of a stay a location may be,
+	// which also absorbs GPS noise
+	visitRadius   = 100.0 // metres
+	minVisitDwell = 10 * time.Minute
+	// locations are only recorded with metric uploads, which can be hours
+	// apart while the user stays put; a longer gap ends the visit
+	maxVisitGap = 12 * time.Hour
+	// shorter movements are noise around a visit rather than a trip
+	minTripDistance = visitRadius
+)
+
+// visit is a stay found in a slice of points; first and last index the points
+// it covers.
+type visit struct {
+	first, last int
+	count       int
+	lat, lon    float64
+}
+
+func (v visit) response(points []entity.LocationPoint) entity.VisitResponse {
+	arrived, departed := points[v.first].RecordedAt, points[v.last].RecordedAt
+	return entity.VisitResponse{
+		Latitude:     v.lat,
+		Longitude:    v.lon,
+		ArrivedAt:    arrived,
+		DepartedAt:   departed,
+		DwellMinutes: round1(departed.Sub(arrived).Minutes()),
+		Points:       v.count,
+	}
+}
+
+// findVisits detects stays in points, which must be in time order. From each
+// point it grows a cluster while the next point lies within visitRadius of the
+// cluster's centroid; a cluster lasting at least minVisitDwell is a visit and
+// the scan continues after it, otherwise it restarts at the next point.
+func findVisits(points []entity.LocationPoint) []visit {
+	var visits []visit
+
+	for i := 0; i < len(points); {
+		v := visit{first: i, last: i, count: 1, lat: points[i].Latitude, lon: points[i].Longitude}
+		sumLat, sumLon := v.lat, v.lon
+
+		for j := i + 1; j < len(points); j++ {
+			p := points[j]
+			if p.RecordedAt.Sub(points[j-1].RecordedAt) > maxVisitGap ||
+				geo.Distance(v.lat, v.lon, p.Latitude, p.Longitude) > visitRadius {
+				break
+			}
+			sumLat += p.Latitude
+			sumLon += p.Longitude
+			v.last, v.count = j, v.count+1
+			v.lat, v.lon = sumLat/float64(v.count), sumLon/float64(v.count)
+		}
+
+		if points[v.last].RecordedAt.Sub(points[v.first].RecordedAt) < minVisitDwell {
+			i++
+			continue
+		}
+
+		visits = appendVisit(visits, v, points)
+		i = v.last + 1
+	}
+
+	return visits
+}
+
+// appendVisit adds v, merging it into the previous visit when the two are at
+// the same place and only a single stray location separates them.
+func appendVisit(visits []visit, v visit, points []entity.LocationPoint) []visit {
+	if len(visits) == 0 {
+		return append(visits, v)
+	}
+
+	prev := &visits[len(visits)-1]
+	if v.first-prev.last > 2 ||
+		points[v.first].RecordedAt.Sub(points[prev.last].RecordedAt) > maxVisitGap ||
+		geo.Distance(prev.lat, prev.lon, v.lat, v.lon) > visitRadius {
+		return append(visits, v)
+	}
+
+	total := float64(prev.count + v.count)
+	prev.lat = (prev.lat*float64(prev.count) + v.lat*float64(v.count)) / total
+	prev.lon = (prev.lon*float64(prev.count) + v.lon*float64(v.count)) / total
+	prev.last = v.last
+	prev.count += v.count
+	return visits
+}
+
+// findTrips returns the movements between the visits, and before the first
+// and after the last one. A trip runs from the last location of one visit to
+// the first of the next, so its distance includes leaving and arriving.
+func findTrips(points []entity.LocationPoint, visits []visit) []entity.TripResponse {
+	if len(points) < 2 {
+		return nil
+	}
+
+	var trips []entity.TripResponse
+	start := 0
+	for k := 0; k <= len(visits); k++ {
+		end := len(points) - 1
+		if k < len(visits) {
+			end = visits[k].first
+		}
+
+		if trip, ok := newTrip(points[start : end+1]); ok {
+			trips = append(trips, trip)
+		}
+
+		if k < len(visits) {
+			start = visits[k].last
+		}
+	}
+
+	return trips
+}
+
+func newTrip(points []entity.LocationPoint) (entity.TripResponse, bool) {
+	if len(points) < 2 {
+		return entity.TripResponse{}, false
+	}
+
+	var distance float64
+	for i := 1; i < len(points); i++ {
+		distance += geo.Distance(points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+	}
+	if distance < minTripDistance {
+		return entity.TripResponse{}, false
+	}
+
+	first, last := points[0], points[len(points)-1]
+	duration := last.RecordedAt.Sub(first.RecordedAt)
+
+	var speed float64
+	if duration > 0 {
+		speed = distance / 1000 / duration.Hours()
+	}
+
+	return entity.TripResponse{
+		StartedAt:       first.RecordedAt,
+		EndedAt:         last.RecordedAt,
+		DurationMinutes: round1(duration.Minutes()),
+		DistanceMeters:  round1(distance),
+		AverageSpeedKmh: round1(speed),
+		From:            entity.Point{Latitude: first.Latitude, Longitude: first.Longitude},
+		To:              entity.Point{Latitude: last.Latitude, Longitude: last.Longitude},
+		Points:          len(points),
+	}, true
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
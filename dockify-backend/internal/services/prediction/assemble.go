@@ -0,0 +1,287 @@
+package prediction
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/gateway/mindspore"
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+const (
+	// workoutWindow is the history used for workout averages and frequency.
+	workoutWindow = 28 * 24 * time.Hour
+	// intakeWindow is the history used for daily calorie and water averages.
+	intakeWindow = 7 * 24 * time.Hour
+
+	maxWindowRows = 1000
+)
+
+// PredictSleepFromData predicts the quality of the user's latest sleep session.
+// Day of week (Monday = 0, as in training) and start hour are taken in timezone.
+func (p *prediction) PredictSleepFromData(ctx context.Context, userID int, timezone string) (entity.SleepPredictionResponse, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return entity.SleepPredictionResponse{}, fmt.Errorf("%w: unknown timezone %q", entity.ErrInvalidParam, timezone)
+	}
+
+	sessions, err := p.health.GetSleepSessions(ctx, entity.TimeRangeQuery{UserId: userID, Limit: 1})
+	if err != nil {
+		return entity.SleepPredictionResponse{}, err
+	}
+	if len(sessions) == 0 {
+		return entity.SleepPredictionResponse{}, fmt.Errorf("%w: no sleep sessions recorded", entity.ErrInsufficientData)
+	}
+	session := sessions[0]
+
+	heartRate, ok, err := p.sleepHeartRate(ctx, session)
+	if err != nil {
+		return entity.SleepPredictionResponse{}, err
+	}
+	if !ok {
+		return entity.SleepPredictionResponse{}, fmt.Errorf("%w: missing heart_rate for the latest sleep session", entity.ErrInsufficientData)
+	}
+
+	started := session.StartedAt.In(loc)
+	req := mindspore.PredictSleepRequest{
+		SleepDurationHours: session.SleepDurationHours,
+		TimeInBedHours:     session.TimeInBedHours,
+		HeartRate:          heartRate,
+		SleepEfficiency:    session.SleepEfficiency * 100,
+		DayOfWeek:          (int(started.Weekday()) + 6) % 7,
+		HourStarted:        started.Hour(),
+		NoteCoffee:         noteFlag(session.Notes, "coffee"),
+		NoteTea:            noteFlag(session.Notes, "tea"),
+		NoteWorkout:        noteFlag(session.Notes, "workout"),
+		NoteStress:         noteFlag(session.Notes, "stress"),
+		NoteAteLate:        noteFlag(session.Notes, "ate_late"),
+	}
+	// trackers without movement or snore detection report none
+	if session.MovementsPerHour != nil {
+		req.MovementsPerHour = *session.MovementsPerHour
+	}
+	if session.SnoreMinutes != nil {
+		req.SnoreTime = *session.SnoreMinutes
+	}
+
+	return p.PredictSleep(ctx, userID, req)
+}
+
+// PredictLifestyleFromData assembles the lifestyle model input from the user's
+// profile, latest body measurements, recent workouts and daily intake.
+func (p *prediction) PredictLifestyleFromData(ctx context.Context, userID int) (entity.LifestylePredictionResponse, error) {
+	user, err := p.repo.User.GetUserByID(ctx, userID)
+	if err != nil {
+		return entity.LifestylePredictionResponse{}, err
+	}
+
+	now := time.Now().UTC()
+	var req mindspore.PredictLifestyleRequest
+	var missing []string
+
+	if user.BirthDate != nil {
+		req.Age = age(*user.BirthDate, now)
+	} else {
+		missing = append(missing, "birth_date")
+	}
+
+	latest := make(map[string]float64)
+	for _, metricType := range []string{"weight", "height", "body_fat", "resting_heart_rate"} {
+		value, ok, err := p.latestMetric(ctx, userID, metricType)
+		if err != nil {
+			return entity.LifestylePredictionResponse{}, err
+		}
+		if !ok {
+			missing = append(missing, metricType)
+			continue
+		}
+		latest[metricType] = value
+	}
+
+	req.WeightKg = int(math.Round(latest["weight"]))
+	req.HeightM = latest["height"] / 100
+	if req.HeightM > 0 {
+		req.Bmi = bmi(latest["weight"], req.HeightM)
+	}
+	req.FatPercentage = latest["body_fat"]
+	req.RestingBpm = int(math.Round(latest["resting_heart_rate"]))
+
+	from := now.Add(-workoutWindow)
+	workouts, err := p.health.GetWorkouts(ctx, entity.TimeRangeQuery{UserId: userID, From: &from, Limit: maxWindowRows})
+	if err != nil {
+		return entity.LifestylePredictionResponse{}, err
+	}
+	missing = append(missing, summarizeWorkouts(workouts, &req)...)
+
+	dailyCalories, ok, err := p.dailyAverage(ctx, userID, "calories_burned", now)
+	if err != nil {
+		return entity.LifestylePredictionResponse{}, err
+	}
+	if !ok {
+		missing = append(missing, "calories_burned")
+	}
+	req.DailyCalories = int(math.Round(dailyCalories))
+
+	req.WaterIntakeLiters, ok, err = p.dailyAverage(ctx, userID, "water_intake", now)
+	if err != nil {
+		return entity.LifestylePredictionResponse{}, err
+	}
+	if !ok {
+		missing = append(missing, "water_intake")
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return entity.LifestylePredictionResponse{}, fmt.Errorf("%w: missing %s", entity.ErrInsufficientData, strings.Join(missing, ", "))
+	}
+
+	return p.PredictLifestyle(ctx, userID, req)
+}
+
+// summarizeWorkouts fills the per-session averages and weekly frequency and
+// returns the names of the inputs it could not derive.
+func summarizeWorkouts(workouts []models.Workout, req *mindspore.PredictLifestyleRequest) []string {
+	if len(workouts) == 0 {
+		return []string{"workouts"}
+	}
+
+	var hours, calories, avgBpm float64
+	var caloriesCount, avgBpmCount, maxBpm int
+	days := make(map[string]struct{})
+
+	for _, w := range workouts {
+		hours += w.EndedAt.Sub(w.StartedAt).Hours()
+		days[w.StartedAt.Format(time.DateOnly)] = struct{}{}
+
+		if w.CaloriesBurned != nil {
+			calories += *w.CaloriesBurned
+			caloriesCount++
+		}
+		if w.AvgBpm != nil {
+			avgBpm += float64(*w.AvgBpm)
+			avgBpmCount++
+		}
+		if w.MaxBpm != nil {
+			maxBpm = max(maxBpm, *w.MaxBpm)
+		}
+	}
+
+	req.SessionDurationHours = hours / float64(len(workouts))
+	req.WorkoutFrequency = int(math.Round(float64(len(days)) / workoutWindow.Hours() * 24 * 7))
+
+	var missing []string
+	if caloriesCount > 0 {
+		req.CaloriesBurned = int(math.Round(calories / float64(caloriesCount)))
+	} else {
+		missing = append(missing, "workout calories_burned")
+	}
+	if avgBpmCount > 0 {
+		req.AvgBpm = int(math.Round(avgBpm / float64(avgBpmCount)))
+	} else {
+		missing = append(missing, "workout avg_bpm")
+	}
+	if maxBpm > 0 {
+		req.MaxBpm = maxBpm
+	} else {
+		missing = append(missing, "workout max_bpm")
+	}
+
+	return missing
+}
+
+// sleepHeartRate prefers the session's own average and falls back to the
+// heart rate samples recorded while the session lasted.
+func (p *prediction) sleepHeartRate(ctx context.Context, session models.SleepSession) (int, bool, error) {
+	if session.AvgHeartRate != nil {
+		return *session.AvgHeartRate, true, nil
+	}
+
+	page, err := p.health.GetMetrics(ctx, entity.HealthMetricsQuery{
+		UserId:      session.UserId,
+		MetricTypes: []string{"heart_rate"},
+		From:        &session.StartedAt,
+		To:          &session.EndedAt,
+		Limit:       maxWindowRows,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if len(page.Metrics) == 0 {
+		return 0, false, nil
+	}
+
+	var sum float64
+	for _, m := range page.Metrics {
+		sum += m.MetricValue
+	}
+	return int(math.Round(sum / float64(len(page.Metrics)))), true, nil
+}
+
+func (p *prediction) latestMetric(ctx context.Context, userID int, metricType string) (float64, bool, error) {
+	page, err := p.health.GetMetrics(ctx, entity.HealthMetricsQuery{
+		UserId:      userID,
+		MetricTypes: []string{metricType},
+		Order:       entity.OrderDesc,
+		Limit:       1,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if len(page.Metrics) == 0 {
+		return 0, false, nil
+	}
+	return page.Metrics[0].MetricValue, true, nil
+}
+
+// dailyAverage averages the daily totals of metricType over the days within
+// intakeWindow that have any data.
+func (p *prediction) dailyAverage(ctx context.Context, userID int, metricType string, now time.Time) (float64, bool, error) {
+	from := now.Add(-intakeWindow)
+	aggregate, err := p.health.AggregateMetrics(ctx, entity.MetricAggregateQuery{
+		UserId:     userID,
+		MetricType: metricType,
+		Bucket:     "1d",
+		Functions:  []string{"sum"},
+		From:       &from,
+		To:         &now,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	var total float64
+	var days int
+	for _, bucket := range aggregate.Buckets {
+		if sum := bucket.Values["sum"]; sum != nil {
+			total += *sum
+			days++
+		}
+	}
+	if days == 0 {
+		return 0, false, nil
+	}
+	return total / float64(days), true, nil
+}
+
+func noteFlag(notes []string, note string) int {
+	if slices.Contains(notes, note) {
+		return 1
+	}
+	return 0
+}
+
+func age(birthDate, now time.Time) int {
+	years := now.Year() - birthDate.Year()
+	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
+		years--
+	}
+	return years
+}
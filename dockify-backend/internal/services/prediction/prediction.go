@@ -0,0 +1,132 @@
+package prediction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/gateway"
+	"github.com/askaroe/dockify-backend/internal/gateway/mindspore"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/askaroe/dockify-backend/internal/services/health"
+)
+
+const (
+	KindSleep     = "sleep"
+	KindLifestyle = "lifestyle"
+
+	defaultPredictionsLimit = 20
+	maxPredictionsLimit     = 100
+)
+
+type Prediction interface {
+	PredictSleep(ctx context.Context, userID int, req mindspore.PredictSleepRequest) (entity.SleepPredictionResponse, error)
+	PredictLifestyle(ctx context.Context, userID int, req mindspore.PredictLifestyleRequest) (entity.LifestylePredictionResponse, error)
+	PredictSleepFromData(ctx context.Context, userID int, timezone string) (entity.SleepPredictionResponse, error)
+	PredictLifestyleFromData(ctx context.Context, userID int) (entity.LifestylePredictionResponse, error)
+	GetPredictions(ctx context.Context, query entity.PredictionsQuery) ([]models.Prediction, error)
+}
+
+type prediction struct {
+	repo    *repository.Repository
+	gateway *gateway.Gateway
+	health  health.Health
+}
+
+// NewPredictionService uses the health service to read the user's data so that
+// assembled model inputs see the same canonical, summarized values as the API.
+func NewPredictionService(repo *repository.Repository, gw *gateway.Gateway, health health.Health) Prediction {
+	return &prediction{repo: repo, gateway: gw, health: health}
+}
+
+func (p *prediction) PredictSleep(ctx context.Context, userID int, req mindspore.PredictSleepRequest) (entity.SleepPredictionResponse, error) {
+	if err := validateSleepRequest(req); err != nil {
+		return entity.SleepPredictionResponse{}, err
+	}
+
+	result, err := p.gateway.PredictSleep(ctx, req)
+	if err != nil {
+		return entity.SleepPredictionResponse{}, fmt.Errorf("%w: %v", entity.ErrModelUnavailable, err)
+	}
+
+	saved, err := p.save(ctx, userID, KindSleep, result.ModelVersion, req, result)
+	if err != nil {
+		return entity.SleepPredictionResponse{}, err
+	}
+
+	return entity.SleepPredictionResponse{ID: saved.ID, Input: req, Result: result, CreatedAt: saved.CreatedAt}, nil
+}
+
+func (p *prediction) PredictLifestyle(ctx context.Context, userID int, req mindspore.PredictLifestyleRequest) (entity.LifestylePredictionResponse, error) {
+	if req.Bmi == 0 && req.HeightM > 0 {
+		req.Bmi = bmi(float64(req.WeightKg), req.HeightM)
+	}
+
+	if err := validateLifestyleRequest(req); err != nil {
+		return entity.LifestylePredictionResponse{}, err
+	}
+
+	result, err := p.gateway.PredictLifestyle(ctx, req)
+	if err != nil {
+		return entity.LifestylePredictionResponse{}, fmt.Errorf("%w: %v", entity.ErrModelUnavailable, err)
+	}
+
+	saved, err := p.save(ctx, userID, KindLifestyle, result.ModelVersion, req, result)
+	if err != nil {
+		return entity.LifestylePredictionResponse{}, err
+	}
+
+	return entity.LifestylePredictionResponse{ID: saved.ID, Input: req, Result: result, CreatedAt: saved.CreatedAt}, nil
+}
+
+func (p *prediction) GetPredictions(ctx context.Context, query entity.PredictionsQuery) ([]models.Prediction, error) {
+	if query.Kind != "" && query.Kind != KindSleep && query.Kind != KindLifestyle {
+		return nil, fmt.Errorf("%w: kind must be sleep or lifestyle", entity.ErrInvalidParam)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultPredictionsLimit
+	}
+
+	predictions, err := p.repo.Prediction.GetPredictions(ctx, models.PredictionFilter{
+		UserId: query.UserId,
+		Kind:   query.Kind,
+		Limit:  min(limit, maxPredictionsLimit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get predictions: %w", err)
+	}
+
+	if predictions == nil {
+		predictions = []models.Prediction{}
+	}
+	return predictions, nil
+}
+
+// save stores the exact model input and output so results can be shown later
+// even after the user's underlying data has changed.
+func (p *prediction) save(ctx context.Context, userID int, kind, modelVersion string, input, output any) (models.Prediction, error) {
+	in, err := json.Marshal(input)
+	if err != nil {
+		return models.Prediction{}, fmt.Errorf("marshal prediction input: %w", err)
+	}
+	out, err := json.Marshal(output)
+	if err != nil {
+		return models.Prediction{}, fmt.Errorf("marshal prediction output: %w", err)
+	}
+
+	saved, err := p.repo.Prediction.CreatePrediction(ctx, models.Prediction{
+		UserId:       userID,
+		Kind:         kind,
+		ModelVersion: modelVersion,
+		Input:        in,
+		Output:       out,
+	})
+	if err != nil {
+		return models.Prediction{}, fmt.Errorf("save %s prediction: %w", kind, err)
+	}
+	return saved, nil
+}
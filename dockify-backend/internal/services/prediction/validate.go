@@ -0,0 +1,72 @@
+package prediction
+
+import (
+	"fmt"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/gateway/mindspore"
+)
+
+// bound is a model input together with the range the model was trained on.
+type bound struct {
+	name     string
+	value    float64
+	min, max float64
+}
+
+func checkBounds(bounds ...bound) error {
+	for _, b := range bounds {
+		if b.value < b.min || b.value > b.max {
+			return fmt.Errorf("%w: %s must be between %g and %g", entity.ErrInvalidParam, b.name, b.min, b.max)
+		}
+	}
+	return nil
+}
+
+func flag(name string, value int) bound {
+	return bound{name: name, value: float64(value), min: 0, max: 1}
+}
+
+func validateSleepRequest(req mindspore.PredictSleepRequest) error {
+	if req.SleepDurationHours > req.TimeInBedHours {
+		return fmt.Errorf("%w: sleep_duration_hours must not exceed time_in_bed_hours", entity.ErrInvalidParam)
+	}
+
+	return checkBounds(
+		bound{"sleep_duration_hours", req.SleepDurationHours, 0, 24},
+		bound{"time_in_bed_hours", req.TimeInBedHours, 0, 24},
+		bound{"heart_rate", float64(req.HeartRate), 20, 250},
+		bound{"sleep_efficiency", req.SleepEfficiency, 0, 100},
+		bound{"movements_per_hour", req.MovementsPerHour, 0, 1000},
+		bound{"snore_time", float64(req.SnoreTime), 0, 24 * 60},
+		bound{"day_of_week", float64(req.DayOfWeek), 0, 6},
+		bound{"hour_started", float64(req.HourStarted), 0, 23},
+		flag("note_coffee", req.NoteCoffee),
+		flag("note_tea", req.NoteTea),
+		flag("note_workout", req.NoteWorkout),
+		flag("note_stress", req.NoteStress),
+		flag("note_ate_late", req.NoteAteLate),
+	)
+}
+
+func validateLifestyleRequest(req mindspore.PredictLifestyleRequest) error {
+	return checkBounds(
+		bound{"age", float64(req.Age), 10, 120},
+		bound{"weight_kg", float64(req.WeightKg), 1, 500},
+		bound{"height_m", req.HeightM, 0.3, 2.8},
+		bound{"bmi", req.Bmi, 5, 100},
+		bound{"fat_percentage", req.FatPercentage, 2, 75},
+		bound{"max_bpm", float64(req.MaxBpm), 20, 250},
+		bound{"avg_bpm", float64(req.AvgBpm), 20, 250},
+		bound{"resting_bpm", float64(req.RestingBpm), 20, 200},
+		bound{"session_duration_hours", req.SessionDurationHours, 0, 24},
+		bound{"calories_burned", float64(req.CaloriesBurned), 0, 20000},
+		bound{"workout_frequency", float64(req.WorkoutFrequency), 0, 7},
+		bound{"daily_calories", float64(req.DailyCalories), 0, 20000},
+		bound{"water_intake_liters", req.WaterIntakeLiters, 0, 20},
+	)
+}
+
+func bmi(weightKg, heightM float64) float64 {
+	return weightKg / (heightM * heightM)
+}
@@ -0,0 +1,42 @@
+package recommendation
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+)
+
+// The cursor is the (priority, id) keyset of the last row of a page, base64
+// encoded so clients treat it as opaque.
+
+func encodeCursor(priority, id int) string {
+	raw := strconv.Itoa(priority) + "," + strconv.Itoa(id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (int, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", entity.ErrInvalidCursor, err)
+	}
+
+	priorityPart, idPart, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return 0, 0, entity.ErrInvalidCursor
+	}
+
+	priority, err := strconv.Atoi(priorityPart)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", entity.ErrInvalidCursor, err)
+	}
+
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", entity.ErrInvalidCursor, err)
+	}
+
+	return priority, id, nil
+}
@@ -0,0 +1,135 @@
+package recommendation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/gateway/mindspore"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/askaroe/dockify-backend/internal/services/prediction"
+)
+
+const (
+	day = 24 * time.Hour
+
+	recentWindow        = 7 * day
+	baselineWindow      = 28 * day
+	bloodPressureWindow = 14 * day
+	// predictions older than this no longer describe the user
+	sleepPredictionMaxAge     = 7 * day
+	lifestylePredictionMaxAge = 30 * day
+
+	maxFactRows = 1000
+)
+
+func (r *recommendation) collectFacts(ctx context.Context, userID int, now time.Time) (facts, error) {
+	var f facts
+	var err error
+
+	recentFrom := now.Add(-recentWindow)
+	f.sleepSessions, err = r.health.GetSleepSessions(ctx, entity.TimeRangeQuery{UserId: userID, From: &recentFrom, Limit: maxFactRows})
+	if err != nil {
+		return facts{}, err
+	}
+
+	bpFrom := now.Add(-bloodPressureWindow)
+	f.bloodPressure, err = r.health.GetBloodPressureReadings(ctx, entity.TimeRangeQuery{UserId: userID, From: &bpFrom, Limit: maxFactRows})
+	if err != nil {
+		return facts{}, err
+	}
+
+	resting, err := r.dailyValues(ctx, userID, "resting_heart_rate", "avg", now.Add(-recentWindow-baselineWindow), now)
+	if err != nil {
+		return facts{}, err
+	}
+	for _, b := range resting {
+		if b.start.Before(recentFrom) {
+			f.restingBaseline = append(f.restingBaseline, b.value)
+		} else {
+			f.restingRecent = append(f.restingRecent, b.value)
+		}
+	}
+
+	// totals only make sense for complete days
+	today := now.Truncate(day)
+	if f.dailySteps, err = r.dailyTotals(ctx, userID, "steps", today.Add(-recentWindow), today); err != nil {
+		return facts{}, err
+	}
+	if f.dailyWater, err = r.dailyTotals(ctx, userID, "water_intake", today.Add(-recentWindow), today); err != nil {
+		return facts{}, err
+	}
+
+	if f.sleepPrediction, err = latestPrediction[mindspore.PredictSleepResponse](ctx, r.repo, userID,
+		prediction.KindSleep, now.Add(-sleepPredictionMaxAge)); err != nil {
+		return facts{}, err
+	}
+	if f.lifestylePrediction, err = latestPrediction[mindspore.PredictLifestyleResponse](ctx, r.repo, userID,
+		prediction.KindLifestyle, now.Add(-lifestylePredictionMaxAge)); err != nil {
+		return facts{}, err
+	}
+
+	return f, nil
+}
+
+type dailyValue struct {
+	start time.Time
+	value float64
+}
+
+// dailyValues returns fn of metricType per UTC day in [from, to), skipping days without data.
+func (r *recommendation) dailyValues(ctx context.Context, userID int, metricType, fn string, from, to time.Time) ([]dailyValue, error) {
+	aggregate, err := r.health.AggregateMetrics(ctx, entity.MetricAggregateQuery{
+		UserId:     userID,
+		MetricType: metricType,
+		Bucket:     "1d",
+		Functions:  []string{fn},
+		From:       &from,
+		To:         &to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]dailyValue, 0, len(aggregate.Buckets))
+	for _, bucket := range aggregate.Buckets {
+		if v := bucket.Values[fn]; v != nil {
+			values = append(values, dailyValue{start: bucket.Start, value: *v})
+		}
+	}
+	return values, nil
+}
+
+func (r *recommendation) dailyTotals(ctx context.Context, userID int, metricType string, from, to time.Time) ([]float64, error) {
+	totals, err := r.dailyValues(ctx, userID, metricType, "sum", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]float64, 0, len(totals))
+	for _, t := range totals {
+		values = append(values, t.value)
+	}
+	return values, nil
+}
+
+// latestPrediction decodes the output of the user's newest prediction of kind,
+// returning nil when there is none made since.
+func latestPrediction[T any](ctx context.Context, repo *repository.Repository, userID int, kind string, since time.Time) (*T, error) {
+	predictions, err := repo.Prediction.GetPredictions(ctx, models.PredictionFilter{UserId: userID, Kind: kind, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(predictions) == 0 || predictions[0].CreatedAt.Before(since) {
+		return nil, nil
+	}
+
+	var result T
+	if err := json.Unmarshal(predictions[0].Output, &result); err != nil {
+		return nil, fmt.Errorf("decode %s prediction: %w", kind, err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,159 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/askaroe/dockify-backend/internal/services/health"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultRecommendationsLimit = 20
+	maxRecommendationsLimit     = 100
+
+	// dismissCooldown keeps a dismissed rule from coming back right away.
+	dismissCooldown = 7 * 24 * time.Hour
+
+	// defaultRecommendation is shown when no rule fires, e.g. for new users.
+	defaultRecommendation = "Stay hydrated and take regular breaks during work!"
+)
+
+type Recommendation interface {
+	GetRecommendations(ctx context.Context, query entity.RecommendationQuery) (entity.RecommendationResponse, error)
+	MarkRecommendationRead(ctx context.Context, userID, id int) error
+	DismissRecommendation(ctx context.Context, userID, id int) error
+}
+
+type recommendation struct {
+	repo   *repository.Repository
+	health health.Health
+}
+
+func NewRecommendationService(repo *repository.Repository, health health.Health) Recommendation {
+	return &recommendation{repo: repo, health: health}
+}
+
+// GetRecommendations re-evaluates the rules when the first page is requested
+// and then serves the open recommendations, highest priority first.
+func (r *recommendation) GetRecommendations(ctx context.Context, query entity.RecommendationQuery) (entity.RecommendationResponse, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultRecommendationsLimit
+	}
+	limit = min(limit, maxRecommendationsLimit)
+
+	filter := models.RecommendationFilter{
+		UserId: query.UserId,
+		// fetch one extra row to find out whether another page exists
+		Limit: limit + 1,
+	}
+
+	if query.Cursor != "" {
+		priority, id, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return entity.RecommendationResponse{}, err
+		}
+		filter.AfterPriority = &priority
+		filter.AfterID = id
+	} else if err := r.evaluate(ctx, query.UserId); err != nil {
+		return entity.RecommendationResponse{}, err
+	}
+
+	recommendations, err := r.repo.Recommendation.GetRecommendations(ctx, filter)
+	if err != nil {
+		return entity.RecommendationResponse{}, fmt.Errorf("get recommendations: %w", err)
+	}
+
+	response := entity.RecommendationResponse{Limit: limit, Recommendation: defaultRecommendation}
+
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+		last := recommendations[limit-1]
+		response.HasMore = true
+		response.NextCursor = encodeCursor(last.Priority, last.ID)
+	}
+
+	if recommendations == nil {
+		recommendations = []models.Recommendation{}
+	}
+	if len(recommendations) > 0 && query.Cursor == "" {
+		response.Recommendation = recommendations[0].Message
+	}
+	response.Recommendations = recommendations
+
+	return response, nil
+}
+
+func (r *recommendation) MarkRecommendationRead(ctx context.Context, userID, id int) error {
+	return notFound(r.repo.Recommendation.MarkRecommendationRead(ctx, userID, id))
+}
+
+func (r *recommendation) DismissRecommendation(ctx context.Context, userID, id int) error {
+	return notFound(r.repo.Recommendation.DismissRecommendation(ctx, userID, id))
+}
+
+// evaluate runs every rule against the user's recent data. Recommendations of
+// rules that fire are created or refreshed, open ones whose rule no longer
+// fires are resolved.
+func (r *recommendation) evaluate(ctx context.Context, userID int) error {
+	now := time.Now().UTC()
+
+	f, err := r.collectFacts(ctx, userID, now)
+	if err != nil {
+		return fmt.Errorf("collect recommendation facts: %w", err)
+	}
+
+	dismissed, err := r.repo.Recommendation.GetDismissedRules(ctx, userID, now.Add(-dismissCooldown))
+	if err != nil {
+		return err
+	}
+
+	active := []string{}
+	var recommendations []models.Recommendation
+	for _, rl := range rules {
+		c, ok := rl.evaluate(f)
+		if !ok || slices.Contains(dismissed, rl.name) {
+			continue
+		}
+
+		active = append(active, rl.name)
+		recommendations = append(recommendations, models.Recommendation{
+			UserId:        userID,
+			Rule:          rl.name,
+			Category:      rl.category,
+			Priority:      clampPriority(c.priority),
+			Title:         c.title,
+			Message:       c.message,
+			Reason:        c.reason,
+			SourceMetrics: c.sourceMetrics,
+		})
+	}
+
+	return r.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		if err := tx.Recommendation.ResolveRecommendations(ctx, userID, active); err != nil {
+			return err
+		}
+		if len(recommendations) == 0 {
+			return nil
+		}
+		return tx.Recommendation.UpsertRecommendations(ctx, recommendations)
+	})
+}
+
+func clampPriority(priority float64) int {
+	return int(min(max(priority, 1), 100))
+}
+
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,284 @@
+package recommendation
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/askaroe/dockify-backend/internal/gateway/mindspore"
+	"github.com/askaroe/dockify-backend/internal/models"
+)
+
+const (
+	categorySleep     = "sleep"
+	categoryHeart     = "heart"
+	categoryActivity  = "activity"
+	categoryHydration = "hydration"
+	categoryLifestyle = "lifestyle"
+
+	// minDays is the number of days (or nights) with data a trend rule needs
+	// before it says anything.
+	minDays = 3
+
+	targetSleepEfficiency = 0.85
+	targetSleepHours      = 7.0
+	restingRiseBpm        = 5.0
+	minBaselineDays       = 7
+	targetDailySteps      = 7000.0
+	targetWaterLiters     = 1.5
+	elevatedSystolic      = 130.0
+	elevatedDiastolic     = 80.0
+	highSystolic          = 140.0
+	highDiastolic         = 90.0
+	poorSleepScore        = 60.0
+	highHealthRiskScore   = 60.0
+)
+
+// candidate is what a firing rule contributes. priority is 1-100, higher is
+// more urgent, and grows with how far the user is from the target.
+type candidate struct {
+	priority      float64
+	title         string
+	message       string
+	reason        string
+	sourceMetrics map[string]any
+}
+
+type rule struct {
+	name     string
+	category string
+	evaluate func(f facts) (candidate, bool)
+}
+
+var rules = []rule{
+	{name: "low_sleep_efficiency", category: categorySleep, evaluate: lowSleepEfficiency},
+	{name: "short_sleep", category: categorySleep, evaluate: shortSleep},
+	{name: "poor_sleep_prediction", category: categorySleep, evaluate: poorSleepPrediction},
+	{name: "resting_heart_rate_rising", category: categoryHeart, evaluate: restingHeartRateRising},
+	{name: "elevated_blood_pressure", category: categoryHeart, evaluate: elevatedBloodPressure},
+	{name: "step_deficit", category: categoryActivity, evaluate: stepDeficit},
+	{name: "low_hydration", category: categoryHydration, evaluate: lowHydration},
+	{name: "high_health_risk", category: categoryLifestyle, evaluate: highHealthRisk},
+}
+
+func lowSleepEfficiency(f facts) (candidate, bool) {
+	if len(f.sleepSessions) < minDays {
+		return candidate{}, false
+	}
+
+	avg := mean(f.sleepSessions, func(s models.SleepSession) float64 { return s.SleepEfficiency })
+	if avg >= targetSleepEfficiency {
+		return candidate{}, false
+	}
+
+	return candidate{
+		priority: 50 + (targetSleepEfficiency-avg)*200,
+		title:    "Improve your sleep efficiency",
+		message: "You spend a lot of time in bed awake. Keep a regular bedtime, get up if you cannot fall asleep " +
+			"and avoid caffeine, screens and late meals in the evening.",
+		reason: fmt.Sprintf("Average sleep efficiency over the last %d nights was %.0f%%, below the %.0f%% target.",
+			len(f.sleepSessions), avg*100, targetSleepEfficiency*100),
+		sourceMetrics: map[string]any{
+			"sleep_efficiency_avg": round2(avg),
+			"nights":               len(f.sleepSessions),
+		},
+	}, true
+}
+
+func shortSleep(f facts) (candidate, bool) {
+	if len(f.sleepSessions) < minDays {
+		return candidate{}, false
+	}
+
+	avg := mean(f.sleepSessions, func(s models.SleepSession) float64 { return s.SleepDurationHours })
+	if avg >= targetSleepHours {
+		return candidate{}, false
+	}
+
+	return candidate{
+		priority: 45 + (targetSleepHours-avg)*10,
+		title:    "Get more sleep",
+		message:  "Adults need 7-9 hours of sleep. Try going to bed 30 minutes earlier this week.",
+		reason: fmt.Sprintf("You slept %.1f hours per night on average over the last %d nights.",
+			avg, len(f.sleepSessions)),
+		sourceMetrics: map[string]any{
+			"sleep_duration_hours_avg": round2(avg),
+			"nights":                   len(f.sleepSessions),
+		},
+	}, true
+}
+
+func poorSleepPrediction(f facts) (candidate, bool) {
+	p := f.sleepPrediction
+	if p == nil || p.SleepQualityScore >= poorSleepScore {
+		return candidate{}, false
+	}
+
+	reason := fmt.Sprintf("Your latest predicted sleep quality score was %.0f out of 100.", p.SleepQualityScore)
+	if p.Interpretation != "" {
+		reason += " " + p.Interpretation
+	}
+
+	return candidate{
+		priority: 40 + (poorSleepScore-p.SleepQualityScore)/2,
+		title:    "Your sleep quality is low",
+		message:  "Wind down before bed and note coffee, stress or late meals in your sleep log to see what affects you.",
+		reason:   reason,
+		sourceMetrics: map[string]any{
+			"sleep_quality_score": round2(p.SleepQualityScore),
+			"model_version":       p.ModelVersion,
+		},
+	}, true
+}
+
+func restingHeartRateRising(f facts) (candidate, bool) {
+	if len(f.restingRecent) < minDays || len(f.restingBaseline) < minBaselineDays {
+		return candidate{}, false
+	}
+
+	recent := mean(f.restingRecent, identity)
+	baseline := mean(f.restingBaseline, identity)
+	rise := recent - baseline
+	if rise < restingRiseBpm {
+		return candidate{}, false
+	}
+
+	return candidate{
+		priority: 55 + rise*3,
+		title:    "Your resting heart rate is rising",
+		message: "A rising resting heart rate can mean poor recovery, stress or an oncoming illness. " +
+			"Take it easy, sleep well and see a doctor if it keeps climbing.",
+		reason: fmt.Sprintf("Resting heart rate averaged %.0f bpm this week compared with %.0f bpm over the previous 4 weeks.",
+			recent, baseline),
+		sourceMetrics: map[string]any{
+			"resting_heart_rate_avg":      round2(recent),
+			"resting_heart_rate_baseline": round2(baseline),
+		},
+	}, true
+}
+
+func elevatedBloodPressure(f facts) (candidate, bool) {
+	if len(f.bloodPressure) < minDays {
+		return candidate{}, false
+	}
+
+	systolic := mean(f.bloodPressure, func(r models.BloodPressureReading) float64 { return float64(r.Systolic) })
+	diastolic := mean(f.bloodPressure, func(r models.BloodPressureReading) float64 { return float64(r.Diastolic) })
+	if systolic < elevatedSystolic && diastolic < elevatedDiastolic {
+		return candidate{}, false
+	}
+
+	c := candidate{
+		priority: 70,
+		title:    "Your blood pressure is elevated",
+		message:  "Cut down on salt and alcohol, stay active and keep measuring at the same time of day.",
+		reason: fmt.Sprintf("Your average blood pressure over %d readings was %.0f/%.0f mmHg.",
+			len(f.bloodPressure), systolic, diastolic),
+		sourceMetrics: map[string]any{
+			"systolic_avg":  round2(systolic),
+			"diastolic_avg": round2(diastolic),
+			"readings":      len(f.bloodPressure),
+		},
+	}
+	if systolic >= highSystolic || diastolic >= highDiastolic {
+		c.priority = 85
+		c.message = "Your readings are in the high range. Please discuss them with a doctor."
+	}
+
+	return c, true
+}
+
+func stepDeficit(f facts) (candidate, bool) {
+	if len(f.dailySteps) < minDays {
+		return candidate{}, false
+	}
+
+	avg := mean(f.dailySteps, identity)
+	if avg >= targetDailySteps {
+		return candidate{}, false
+	}
+
+	return candidate{
+		priority: 30 + (targetDailySteps-avg)/targetDailySteps*40,
+		title:    "Move a little more",
+		message:  "Short walks add up. Try a 15 minute walk after lunch or take the stairs.",
+		reason: fmt.Sprintf("You averaged %.0f steps a day over the last %d days, below the %.0f step goal.",
+			avg, len(f.dailySteps), targetDailySteps),
+		sourceMetrics: map[string]any{
+			"steps_daily_avg": math.Round(avg),
+			"days":            len(f.dailySteps),
+		},
+	}, true
+}
+
+func lowHydration(f facts) (candidate, bool) {
+	if len(f.dailyWater) < minDays {
+		return candidate{}, false
+	}
+
+	avg := mean(f.dailyWater, identity)
+	if avg >= targetWaterLiters {
+		return candidate{}, false
+	}
+
+	return candidate{
+		priority: 25 + (targetWaterLiters-avg)/targetWaterLiters*30,
+		title:    "Drink more water",
+		message:  "Keep a bottle of water at hand and take regular breaks during work.",
+		reason:   fmt.Sprintf("You logged %.1f L of water a day over the last %d days.", avg, len(f.dailyWater)),
+		sourceMetrics: map[string]any{
+			"water_intake_daily_avg": round2(avg),
+			"days":                   len(f.dailyWater),
+		},
+	}, true
+}
+
+func highHealthRisk(f facts) (candidate, bool) {
+	p := f.lifestylePrediction
+	if p == nil || p.HealthRiskScore < highHealthRiskScore {
+		return candidate{}, false
+	}
+
+	reason := fmt.Sprintf("Your latest predicted health risk score was %.0f out of 100 (%s lifestyle).",
+		p.HealthRiskScore, p.LifestyleCategory)
+	if p.Interpretation != "" {
+		reason += " " + p.Interpretation
+	}
+
+	return candidate{
+		priority: 50 + (p.HealthRiskScore-highHealthRiskScore)/2,
+		title:    "Lower your health risk",
+		message:  "Regular exercise, balanced meals and enough sleep lower your risk. Consider a check-up with your doctor.",
+		reason:   reason,
+		sourceMetrics: map[string]any{
+			"health_risk_score":  round2(p.HealthRiskScore),
+			"lifestyle_category": p.LifestyleCategory,
+			"model_version":      p.ModelVersion,
+		},
+	}, true
+}
+
+// facts are the inputs of the rules, collected once per evaluation.
+type facts struct {
+	sleepSessions       []models.SleepSession
+	restingRecent       []float64 // daily averages of the last 7 days
+	restingBaseline     []float64 // daily averages of the 4 weeks before
+	dailySteps          []float64
+	dailyWater          []float64
+	bloodPressure       []models.BloodPressureReading
+	sleepPrediction     *mindspore.PredictSleepResponse
+	lifestylePrediction *mindspore.PredictLifestyleResponse
+}
+
+func mean[T any](items []T, value func(T) float64) float64 {
+	var sum float64
+	for _, item := range items {
+		sum += value(item)
+	}
+	return sum / float64(len(items))
+}
+
+func identity(v float64) float64 { return v }
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
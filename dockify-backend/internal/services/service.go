@@ -1,22 +1,51 @@
 package services
 
 import (
+	"github.com/askaroe/dockify-backend/config"
+	"github.com/askaroe/dockify-backend/internal/gateway"
+	"github.com/askaroe/dockify-backend/internal/notifier"
 	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/askaroe/dockify-backend/internal/services/care"
+	"github.com/askaroe/dockify-backend/internal/services/friend"
 	"github.com/askaroe/dockify-backend/internal/services/health"
+	"github.com/askaroe/dockify-backend/internal/services/hospital"
 	"github.com/askaroe/dockify-backend/internal/services/location"
+	"github.com/askaroe/dockify-backend/internal/services/prediction"
+	"github.com/askaroe/dockify-backend/internal/services/recommendation"
+	"github.com/askaroe/dockify-backend/internal/services/sos"
 	"github.com/askaroe/dockify-backend/internal/services/user"
+	"github.com/askaroe/dockify-backend/pkg/mailer"
+	"github.com/askaroe/dockify-backend/pkg/password"
+	"github.com/askaroe/dockify-backend/pkg/secretbox"
+	"github.com/askaroe/dockify-backend/pkg/token"
+	"github.com/askaroe/dockify-backend/pkg/utils"
 )
 
 type Service struct {
 	health.Health
 	user.User
 	location.Location
+	hospital.Hospital
+	prediction.Prediction
+	recommendation.Recommendation
+	friend.Friend
+	sos.SOS
+	care.Care
 }
 
-func NewService(repo *repository.Repository) *Service {
+func NewService(cfg *config.Config, repo *repository.Repository, tokens *token.Manager, gw *gateway.Gateway, n notifier.Notifier, m mailer.Sender, policy *password.Policy, hasher *password.Hasher, secrets *secretbox.Box, logger *utils.Logger) *Service {
+	healthService := health.NewHealthService(repo, n, logger)
+	locationService := location.NewLocationService(repo, cfg.LocationConfig)
+
 	return &Service{
-		Health:   health.NewHealthService(repo),
-		User:     user.NewUserService(repo),
-		Location: location.NewLocationService(repo),
+		Health:         healthService,
+		User:           user.NewUserService(repo, tokens, m, policy, hasher, secrets, cfg.AccountConfig, logger),
+		Location:       locationService,
+		Hospital:       hospital.NewHospitalService(repo),
+		Prediction:     prediction.NewPredictionService(repo, gw, healthService),
+		Recommendation: recommendation.NewRecommendationService(repo, healthService),
+		Friend:         friend.NewFriendService(repo),
+		SOS:            sos.NewSOSService(repo, n, cfg.SOSConfig, logger),
+		Care:           care.NewCareService(repo, healthService, locationService, n, logger),
 	}
 }
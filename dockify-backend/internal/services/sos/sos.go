@@ -0,0 +1,524 @@
+package sos
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/askaroe/dockify-backend/config"
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/notifier"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	sosrepo "github.com/askaroe/dockify-backend/internal/repository/sos"
+	"github.com/askaroe/dockify-backend/pkg/geo"
+	"github.com/askaroe/dockify-backend/pkg/ratelimit"
+	"github.com/askaroe/dockify-backend/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultRadius        = 2000
+	defaultMaxAgeMinutes = 30
+	defaultMaxResponders = 20
+	maxMessageLength     = 500
+	incidentsLimit       = 50
+
+	incidentsPerUser = 3
+	incidentsWindow  = 10 * time.Minute
+
+	// responders only learn how far away someone is in steps of this size,
+	// so distances from several incidents can not be used to locate them
+	distanceBand = 100
+)
+
+type SOS interface {
+	CreateIncident(ctx context.Context, request entity.SOSRequest) (entity.IncidentResponse, error)
+	GetIncident(ctx context.Context, userID, incidentID int) (entity.IncidentResponse, error)
+	GetIncidents(ctx context.Context, userID int) ([]entity.IncidentResponse, error)
+	GetIncidentTimeline(ctx context.Context, userID, incidentID int) ([]entity.IncidentEventResponse, error)
+	AcknowledgeIncident(ctx context.Context, userID, incidentID int) error
+	ResolveIncident(ctx context.Context, userID, incidentID int, request entity.ResolveIncidentRequest) error
+
+	GetSOSSettings(ctx context.Context, userID int) (entity.SOSSettings, error)
+	UpdateSOSSettings(ctx context.Context, settings entity.SOSSettings) (entity.SOSSettings, error)
+}
+
+type sos struct {
+	repo          *repository.Repository
+	notifier      notifier.Notifier
+	logger        *utils.Logger
+	radius        int
+	maxAge        time.Duration
+	maxResponders int
+	createByUser  *ratelimit.Limiter
+}
+
+func NewSOSService(repo *repository.Repository, n notifier.Notifier, cfg config.SOSConfig, logger *utils.Logger) SOS {
+	s := &sos{
+		repo:          repo,
+		notifier:      n,
+		logger:        logger,
+		radius:        cfg.SOSRadiusMeters,
+		maxAge:        time.Duration(cfg.SOSMaxAgeMinutes) * time.Minute,
+		maxResponders: cfg.SOSMaxResponders,
+		createByUser:  ratelimit.New(incidentsPerUser, incidentsWindow),
+	}
+	if s.radius <= 0 {
+		s.radius = defaultRadius
+	}
+	if s.maxAge <= 0 {
+		s.maxAge = defaultMaxAgeMinutes * time.Minute
+	}
+	if s.maxResponders <= 0 {
+		s.maxResponders = defaultMaxResponders
+	}
+	return s
+}
+
+// CreateIncident raises an SOS at the caller's location and alerts the
+// nearest opted-in users with a recent location nearby. Users blocked by or
+// blocking the caller are never alerted. The incident is stored before any
+// notification is sent, so a failed delivery only shows up in the timeline.
+// A user can raise a few incidents in a row; beyond that a RetryAfterError is
+// returned.
+func (s *sos) CreateIncident(ctx context.Context, request entity.SOSRequest) (entity.IncidentResponse, error) {
+	if request.Latitude < -90 || request.Latitude > 90 || request.Longitude < -180 || request.Longitude > 180 {
+		return entity.IncidentResponse{}, fmt.Errorf("%w: latitude or longitude out of range", entity.ErrInvalidParam)
+	}
+	if len([]rune(request.Message)) > maxMessageLength {
+		return entity.IncidentResponse{}, fmt.Errorf("%w: message must not exceed %d characters", entity.ErrInvalidParam, maxMessageLength)
+	}
+	if ok, retryAfter := s.createByUser.Allow(strconv.Itoa(request.UserId)); !ok {
+		return entity.IncidentResponse{}, &entity.RetryAfterError{RetryAfter: retryAfter}
+	}
+
+	responders, err := s.findResponders(ctx, request)
+	if err != nil {
+		return entity.IncidentResponse{}, fmt.Errorf("create incident: %w", err)
+	}
+
+	var incident models.Incident
+	err = s.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		var err error
+		incident, err = tx.SOS.CreateIncident(ctx, models.Incident{
+			UserId:    request.UserId,
+			Latitude:  request.Latitude,
+			Longitude: request.Longitude,
+			Message:   request.Message,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.SOS.AddResponders(ctx, incident.ID, responders); err != nil {
+			return err
+		}
+		return tx.SOS.AddIncidentEvent(ctx, models.IncidentEvent{
+			IncidentId: incident.ID,
+			UserId:     &request.UserId,
+			Event:      sosrepo.EventCreated,
+			Details:    fmt.Sprintf("%d responders alerted", len(responders)),
+		})
+	})
+	if err != nil {
+		return entity.IncidentResponse{}, fmt.Errorf("create incident: %w", err)
+	}
+
+	// alerts go out even when the caller disconnects
+	ctx = context.WithoutCancel(ctx)
+	messages := make([]notifier.Message, 0, len(responders))
+	for _, r := range responders {
+		messages = append(messages, notifier.Message{
+			UserID:  r.UserId,
+			Subject: "SOS nearby",
+			Body:    fmt.Sprintf("Someone %.0f m from you needs help. %s", r.Distance, request.Message),
+			Data:    incidentData(incident),
+		})
+	}
+	s.notifyAll(ctx, messages, func(msg notifier.Message, err error) {
+		event := models.IncidentEvent{IncidentId: incident.ID, UserId: &msg.UserID, Event: sosrepo.EventNotified}
+		if err != nil {
+			s.logger.Errorf("notify responder %d of incident %d: %v", msg.UserID, incident.ID, err)
+			event.Event, event.Details = sosrepo.EventNotifyFailed, "notification could not be delivered"
+		} else if err := s.repo.SOS.MarkResponderNotified(ctx, incident.ID, msg.UserID); err != nil {
+			s.logger.Errorf("mark responder %d of incident %d notified: %v", msg.UserID, incident.ID, err)
+		}
+		if err := s.repo.SOS.AddIncidentEvent(ctx, event); err != nil {
+			s.logger.Errorf("add event to incident %d: %v", incident.ID, err)
+		}
+	})
+
+	response, err := s.incidentResponse(ctx, incident, request.UserId)
+	if err != nil {
+		return entity.IncidentResponse{}, fmt.Errorf("create incident: %w", err)
+	}
+	return response, nil
+}
+
+// findResponders returns the users to alert about an SOS at the request's
+// location, nearest first.
+func (s *sos) findResponders(ctx context.Context, request entity.SOSRequest) ([]models.IncidentResponder, error) {
+	locations, err := s.repo.Location.GetNearestUsers(ctx, models.NearestUsersFilter{
+		Latitude:  request.Latitude,
+		Longitude: request.Longitude,
+		Radius:    s.radius,
+		MaxAge:    s.maxAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int, 0, len(locations))
+	for _, loc := range locations {
+		if loc.UserId != request.UserId {
+			userIDs = append(userIDs, loc.UserId)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	optedIn, err := s.repo.User.GetSOSResponderIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.repo.Friend.GetBlockedUserIDs(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var responders []models.IncidentResponder
+	for _, loc := range locations {
+		if !slices.Contains(optedIn, loc.UserId) || slices.Contains(blocked, loc.UserId) {
+			continue
+		}
+		responders = append(responders, models.IncidentResponder{
+			UserId:   loc.UserId,
+			Distance: geo.Distance(request.Latitude, request.Longitude, loc.Latitude.InexactFloat64(), loc.Longitude.InexactFloat64()),
+		})
+	}
+
+	slices.SortFunc(responders, func(a, b models.IncidentResponder) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.UserId, b.UserId))
+	})
+	if len(responders) > s.maxResponders {
+		responders = responders[:s.maxResponders]
+	}
+	return responders, nil
+}
+
+func (s *sos) GetIncident(ctx context.Context, userID, incidentID int) (entity.IncidentResponse, error) {
+	incident, _, err := s.access(ctx, userID, incidentID)
+	if err != nil {
+		return entity.IncidentResponse{}, err
+	}
+
+	response, err := s.incidentResponse(ctx, incident, userID)
+	if err != nil {
+		return entity.IncidentResponse{}, fmt.Errorf("get incident: %w", err)
+	}
+	return response, nil
+}
+
+// GetIncidents returns the latest incidents the user raised or was alerted
+// about, newest first.
+func (s *sos) GetIncidents(ctx context.Context, userID int) ([]entity.IncidentResponse, error) {
+	incidents, err := s.repo.SOS.GetIncidents(ctx, userID, incidentsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("get incidents: %w", err)
+	}
+
+	response := make([]entity.IncidentResponse, 0, len(incidents))
+	for _, incident := range incidents {
+		response = append(response, incidentResponse(incident))
+	}
+	return response, nil
+}
+
+// GetIncidentTimeline returns the events of the incident in order. Responders
+// only see the events about the incident and themselves, not about the other
+// responders. The user who raised the incident sees events about responders
+// who have not acknowledged it without their user id.
+func (s *sos) GetIncidentTimeline(ctx context.Context, userID, incidentID int) ([]entity.IncidentEventResponse, error) {
+	incident, responder, err := s.access(ctx, userID, incidentID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.repo.SOS.GetIncidentEvents(ctx, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("get incident timeline: %w", err)
+	}
+	var acknowledged []int
+	if !responder {
+		if acknowledged, err = s.acknowledgedResponders(ctx, incidentID); err != nil {
+			return nil, fmt.Errorf("get incident timeline: %w", err)
+		}
+	}
+
+	response := make([]entity.IncidentEventResponse, 0, len(events))
+	for _, e := range events {
+		if responder && e.UserId != nil && *e.UserId != userID && *e.UserId != incident.UserId {
+			continue
+		}
+		eventUserID := e.UserId
+		if !responder && e.UserId != nil && *e.UserId != userID && !slices.Contains(acknowledged, *e.UserId) {
+			eventUserID = nil
+		}
+		response = append(response, entity.IncidentEventResponse{
+			Event:     e.Event,
+			UserID:    eventUserID,
+			Details:   e.Details,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// AcknowledgeIncident tells the user who raised the incident that a responder
+// is on their way. Acknowledging twice has no further effect.
+func (s *sos) AcknowledgeIncident(ctx context.Context, userID, incidentID int) error {
+	incident, responder, err := s.access(ctx, userID, incidentID)
+	if err != nil {
+		return err
+	}
+	if !responder {
+		return fmt.Errorf("%w: only alerted responders can acknowledge an incident", entity.ErrForbidden)
+	}
+	if incident.Status != sosrepo.StatusOpen {
+		return fmt.Errorf("%w: incident is already resolved", entity.ErrInvalidParam)
+	}
+
+	r, err := s.repo.SOS.GetResponder(ctx, incidentID, userID)
+	if err != nil {
+		return fmt.Errorf("acknowledge incident: %w", err)
+	}
+	if r.AcknowledgedAt != nil {
+		return nil
+	}
+
+	err = s.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		if err := tx.SOS.AcknowledgeIncident(ctx, incidentID, userID); err != nil {
+			return err
+		}
+		return tx.SOS.AddIncidentEvent(ctx, models.IncidentEvent{
+			IncidentId: incidentID,
+			UserId:     &userID,
+			Event:      sosrepo.EventAcknowledged,
+			Details:    fmt.Sprintf("responder within %.0f m is on the way", roundDistance(r.Distance)),
+		})
+	})
+	// a concurrent acknowledgement by the same responder got there first
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("acknowledge incident: %w", err)
+	}
+
+	s.notifyAll(context.WithoutCancel(ctx), []notifier.Message{{
+		UserID:  incident.UserId,
+		Subject: "Help is on the way",
+		Body:    fmt.Sprintf("A responder within %.0f m acknowledged your SOS.", roundDistance(r.Distance)),
+		Data:    incidentData(incident),
+	}}, s.logFailure(incidentID))
+	return nil
+}
+
+// ResolveIncident closes the incident and tells the responders who were
+// alerted that help is no longer needed. Only the user who raised the
+// incident can resolve it.
+func (s *sos) ResolveIncident(ctx context.Context, userID, incidentID int, request entity.ResolveIncidentRequest) error {
+	incident, responder, err := s.access(ctx, userID, incidentID)
+	if err != nil {
+		return err
+	}
+	if responder {
+		return fmt.Errorf("%w: only the user who raised the incident can resolve it", entity.ErrForbidden)
+	}
+	if len([]rune(request.Note)) > maxMessageLength {
+		return fmt.Errorf("%w: note must not exceed %d characters", entity.ErrInvalidParam, maxMessageLength)
+	}
+
+	err = s.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		if _, err := tx.SOS.ResolveIncident(ctx, incidentID); err != nil {
+			return err
+		}
+		return tx.SOS.AddIncidentEvent(ctx, models.IncidentEvent{
+			IncidentId: incidentID,
+			UserId:     &userID,
+			Event:      sosrepo.EventResolved,
+			Details:    request.Note,
+		})
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: incident is already resolved", entity.ErrInvalidParam)
+	}
+	if err != nil {
+		return fmt.Errorf("resolve incident: %w", err)
+	}
+
+	responders, err := s.repo.SOS.GetResponders(ctx, incidentID)
+	if err != nil {
+		s.logger.Errorf("get responders of incident %d: %v", incidentID, err)
+		return nil
+	}
+	var messages []notifier.Message
+	for _, r := range responders {
+		if r.NotifiedAt == nil {
+			continue
+		}
+		messages = append(messages, notifier.Message{
+			UserID:  r.UserId,
+			Subject: "SOS resolved",
+			Body:    "The emergency you were alerted about has been resolved. Thank you.",
+			Data:    incidentData(incident),
+		})
+	}
+	s.notifyAll(context.WithoutCancel(ctx), messages, s.logFailure(incidentID))
+	return nil
+}
+
+func (s *sos) GetSOSSettings(ctx context.Context, userID int) (entity.SOSSettings, error) {
+	enabled, err := s.repo.User.GetSOSResponder(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.SOSSettings{}, entity.ErrNotFound
+	}
+	if err != nil {
+		return entity.SOSSettings{}, fmt.Errorf("get sos settings: %w", err)
+	}
+	return entity.SOSSettings{Responder: enabled}, nil
+}
+
+func (s *sos) UpdateSOSSettings(ctx context.Context, settings entity.SOSSettings) (entity.SOSSettings, error) {
+	err := s.repo.User.SetSOSResponder(ctx, settings.UserId, settings.Responder)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.SOSSettings{}, entity.ErrNotFound
+	}
+	if err != nil {
+		return entity.SOSSettings{}, fmt.Errorf("update sos settings: %w", err)
+	}
+	return entity.SOSSettings{Responder: settings.Responder}, nil
+}
+
+// access loads the incident for the user who raised it or one of its
+// responders; responder reports which of the two the user is. Anyone else
+// gets entity.ErrNotFound.
+func (s *sos) access(ctx context.Context, userID, incidentID int) (incident models.Incident, responder bool, err error) {
+	incident, err = s.repo.SOS.GetIncident(ctx, incidentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Incident{}, false, entity.ErrNotFound
+	}
+	if err != nil {
+		return models.Incident{}, false, fmt.Errorf("get incident: %w", err)
+	}
+	if incident.UserId == userID {
+		return incident, false, nil
+	}
+
+	_, err = s.repo.SOS.GetResponder(ctx, incidentID, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Incident{}, false, entity.ErrNotFound
+	}
+	if err != nil {
+		return models.Incident{}, false, fmt.Errorf("get incident: %w", err)
+	}
+	return incident, true, nil
+}
+
+// incidentResponse adds the responders when userID raised the incident. A
+// responder's user id is only shown once they acknowledged the incident, and
+// distances are rounded up to distanceBand.
+func (s *sos) incidentResponse(ctx context.Context, incident models.Incident, userID int) (entity.IncidentResponse, error) {
+	response := incidentResponse(incident)
+	if incident.UserId != userID {
+		return response, nil
+	}
+
+	responders, err := s.repo.SOS.GetResponders(ctx, incident.ID)
+	if err != nil {
+		return entity.IncidentResponse{}, err
+	}
+	response.Responders = make([]entity.IncidentResponderResponse, 0, len(responders))
+	for _, r := range responders {
+		responder := entity.IncidentResponderResponse{
+			DistanceMeters: roundDistance(r.Distance),
+			NotifiedAt:     r.NotifiedAt,
+			AcknowledgedAt: r.AcknowledgedAt,
+		}
+		if r.AcknowledgedAt != nil {
+			responder.UserID = &r.UserId
+		}
+		response.Responders = append(response.Responders, responder)
+	}
+	return response, nil
+}
+
+func (s *sos) acknowledgedResponders(ctx context.Context, incidentID int) ([]int, error) {
+	responders, err := s.repo.SOS.GetResponders(ctx, incidentID)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []int
+	for _, r := range responders {
+		if r.AcknowledgedAt != nil {
+			userIDs = append(userIDs, r.UserId)
+		}
+	}
+	return userIDs, nil
+}
+
+// roundDistance rounds meters up to the next distanceBand.
+func roundDistance(meters float64) float64 {
+	return max(math.Ceil(meters/distanceBand), 1) * distanceBand
+}
+
+func incidentResponse(incident models.Incident) entity.IncidentResponse {
+	return entity.IncidentResponse{
+		ID:         incident.ID,
+		UserID:     incident.UserId,
+		Latitude:   incident.Latitude,
+		Longitude:  incident.Longitude,
+		Message:    incident.Message,
+		Status:     incident.Status,
+		CreatedAt:  incident.CreatedAt,
+		ResolvedAt: incident.ResolvedAt,
+	}
+}
+
+func incidentData(incident models.Incident) map[string]string {
+	return map[string]string{
+		"incident_id": strconv.Itoa(incident.ID),
+		"latitude":    strconv.FormatFloat(incident.Latitude, 'f', -1, 64),
+		"longitude":   strconv.FormatFloat(incident.Longitude, 'f', -1, 64),
+	}
+}
+
+// notifyAll sends the messages concurrently, calling done with the outcome of
+// each, and returns once all of them were handled.
+func (s *sos) notifyAll(ctx context.Context, messages []notifier.Message, done func(msg notifier.Message, err error)) {
+	var wg sync.WaitGroup
+	for _, msg := range messages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done(msg, s.notifier.Notify(ctx, msg))
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *sos) logFailure(incidentID int) func(msg notifier.Message, err error) {
+	return func(msg notifier.Message, err error) {
+		if err != nil {
+			s.logger.Errorf("notify user %d about incident %d: %v", msg.UserID, incidentID, err)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/askaroe/dockify-backend/pkg/token"
+	"github.com/jackc/pgx/v5"
+)
+
+func (u *user) GetProfile(ctx context.Context, userID int) (models.User, error) {
+	userModel, err := u.repo.User.GetUserByID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, entity.ErrNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("get profile: %w", err)
+	}
+	return userModel, nil
+}
+
+// UpdateProfile changes the fields present in the request. Changing the email
+// needs the current password; the new address loses its verified state and
+// gets a verification email, and the old one is told about the change.
+func (u *user) UpdateProfile(ctx context.Context, request entity.UpdateProfileRequest) (models.User, error) {
+	current, err := u.GetProfile(ctx, request.UserId)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	var errs fieldErrors
+	updated := current
+	if request.Username != nil {
+		updated.Username = strings.TrimSpace(*request.Username)
+		if msg := checkUsername(updated.Username); msg != "" {
+			errs.add("username", msg)
+		}
+	}
+	if request.FirstName != nil {
+		updated.FirstName = strings.TrimSpace(*request.FirstName)
+		if utf8.RuneCountInString(updated.FirstName) > maxNameLength {
+			errs.add("first_name", fmt.Sprintf("must not exceed %d characters", maxNameLength))
+		}
+	}
+	if request.LastName != nil {
+		updated.LastName = strings.TrimSpace(*request.LastName)
+		if utf8.RuneCountInString(updated.LastName) > maxNameLength {
+			errs.add("last_name", fmt.Sprintf("must not exceed %d characters", maxNameLength))
+		}
+	}
+	if request.Email != nil {
+		email, ok := normalizeEmail(*request.Email)
+		if !ok {
+			errs.add("email", "must be a valid email address")
+		}
+		updated.Email = email
+	}
+	if request.BirthDate != nil {
+		if updated.BirthDate, err = parseBirthDate(*request.BirthDate); err != nil {
+			errs.add("birth_date", "must be a past date in YYYY-MM-DD format")
+		}
+	}
+	if err := errs.err(); err != nil {
+		return models.User{}, err
+	}
+	if updated.Email != current.Email {
+		if err := u.hasher.Verify(current.PasswordHash, request.CurrentPassword); err != nil {
+			return models.User{}, fmt.Errorf("%w: current password is incorrect", entity.ErrForbidden)
+		}
+	}
+
+	saved, err := u.repo.User.UpdateProfile(ctx, updated)
+	if err = conflictError(err); errors.Is(err, entity.ErrConflict) {
+		return models.User{}, err
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.User{}, entity.ErrNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("update profile: %w", err)
+	}
+
+	if saved.Email != current.Email {
+		if err := u.sendVerification(ctx, saved); err != nil {
+			u.logger.Errorf("send verification email to user %d: %v", saved.ID, err)
+		}
+		if err := u.mailer.Send(ctx, current.Email, "Your email was changed",
+			fmt.Sprintf("The email address of your Dockify account was changed to %s. "+
+				"If this was not you, reset your password right away.", saved.Email)); err != nil {
+			u.logger.Errorf("send email changed email to user %d: %v", saved.ID, err)
+		}
+	}
+	return saved, nil
+}
+
+// ChangePassword replaces the password after checking the current one and
+// signs out every other session.
+func (u *user) ChangePassword(ctx context.Context, userID, sessionID int, request entity.ChangePasswordRequest) error {
+	userModel, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := u.hasher.Verify(userModel.PasswordHash, request.CurrentPassword); err != nil {
+		return fmt.Errorf("%w: current password is incorrect", entity.ErrForbidden)
+	}
+
+	hash, err := u.checkNewPassword("new_password", request.NewPassword, userModel.Username, userModel.Email)
+	if err != nil {
+		return err
+	}
+
+	err = u.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		if err := tx.User.SetPasswordHash(ctx, userID, hash); err != nil {
+			return err
+		}
+		return tx.User.RevokeOtherSessions(ctx, userID, sessionID)
+	})
+	if err != nil {
+		return fmt.Errorf("change password: %w", err)
+	}
+	return nil
+}
+
+func (u *user) SendEmailVerification(ctx context.Context, userID int) error {
+	userModel, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if userModel.EmailVerifiedAt != nil {
+		return fmt.Errorf("%w: email is already verified", entity.ErrInvalidParam)
+	}
+
+	if err := u.sendVerification(ctx, userModel); err != nil {
+		return fmt.Errorf("send email verification: %w", err)
+	}
+	return nil
+}
+
+// VerifyEmail marks the email the token was sent to as verified, unless the
+// user has changed their email since.
+func (u *user) VerifyEmail(ctx context.Context, request entity.VerifyEmailRequest) error {
+	claims, err := u.tokens.Parse(request.Token, token.TypeEmailVerification)
+	if err != nil {
+		return fmt.Errorf("%w: invalid or expired token", entity.ErrInvalidParam)
+	}
+
+	err = u.repo.User.MarkEmailVerified(ctx, claims.UserID, claims.Email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: the email address has changed since the token was sent", entity.ErrInvalidParam)
+	}
+	if err != nil {
+		return fmt.Errorf("verify email: %w", err)
+	}
+	return nil
+}
+
+// DeleteAccount schedules the account for deletion after the grace period and
+// signs out every session. Logging in before then restores the account.
+func (u *user) DeleteAccount(ctx context.Context, userID int, request entity.DeleteAccountRequest) (entity.AccountDeletionResponse, error) {
+	userModel, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return entity.AccountDeletionResponse{}, err
+	}
+	if err := u.hasher.Verify(userModel.PasswordHash, request.Password); err != nil {
+		return entity.AccountDeletionResponse{}, fmt.Errorf("%w: password is incorrect", entity.ErrForbidden)
+	}
+
+	var at time.Time
+	err = u.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		var err error
+		if at, err = tx.User.ScheduleDeletion(ctx, userID, u.graceDays); err != nil {
+			return err
+		}
+		return tx.User.RevokeUserSessions(ctx, userID)
+	})
+	if err != nil {
+		return entity.AccountDeletionResponse{}, fmt.Errorf("delete account: %w", err)
+	}
+
+	body := fmt.Sprintf("Your Dockify account will be deleted with all of its data on %s UTC. "+
+		"Log in before then to keep it.", at.Format("2006-01-02 15:04"))
+	if err := u.mailer.Send(ctx, userModel.Email, "Your account will be deleted", body); err != nil {
+		u.logger.Errorf("send deletion email to user %d: %v", userID, err)
+	}
+
+	return entity.AccountDeletionResponse{DeletionScheduledAt: at}, nil
+}
+
+// PurgeDeletedAccounts deletes the accounts whose grace period has passed,
+// together with all of their data.
+func (u *user) PurgeDeletedAccounts(ctx context.Context) (int64, error) {
+	n, err := u.repo.User.PurgeDeletedUsers(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("purge deleted accounts: %w", err)
+	}
+	return n, nil
+}
+
+func (u *user) sendVerification(ctx context.Context, userModel models.User) error {
+	t, err := u.tokens.IssueEmailVerification(userModel.ID, userModel.Email)
+	if err != nil {
+		return err
+	}
+
+	body := "Confirm your email address for Dockify with this code, valid for 24 hours:\n\n" + t
+	if u.appURL != "" {
+		body = "Confirm your email address for Dockify within 24 hours:\n\n" +
+			u.appURL + "/verify-email?token=" + url.QueryEscape(t)
+	}
+	return u.mailer.Send(ctx, userModel.Email, "Confirm your email address", body)
+}
+
+// parseBirthDate parses a YYYY-MM-DD date in the past; empty means none.
+func parseBirthDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil || date.After(time.Now()) {
+		return nil, fmt.Errorf("%w: birth_date must be a past date in YYYY-MM-DD format", entity.ErrInvalidParam)
+	}
+	return &date, nil
+}
@@ -0,0 +1,186 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/askaroe/dockify-backend/config"
+	"github.com/askaroe/dockify-backend/internal/entity"
+	userrepo "github.com/askaroe/dockify-backend/internal/repository/user"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	loginFailureStoreMemory = "memory"
+
+	// a failure count starts over after an hour without failures
+	loginFailureWindow = time.Hour
+
+	defaultAccountLockoutThreshold = 10
+	defaultIPLockoutThreshold      = 50
+	defaultLoginLockout            = 15 * time.Minute
+
+	// failures allowed before every further one doubles the wait, starting at
+	// a second
+	accountFreeFailures = 3
+	ipFreeFailures      = 10
+)
+
+// loginThrottle slows down password guessing. Every failed login is counted
+// for the account and for the client address; after a few failures each
+// further attempt has to wait twice as long as the previous one, and reaching
+// the threshold locks the key for the lockout duration. Attempts are counted
+// before the credentials are checked and taken back when they were right, so
+// a burst of concurrent guesses is throttled like one after the other.
+type loginThrottle struct {
+	failures userrepo.LoginFailures
+	lockout  time.Duration
+	account  backoff
+	ip       backoff
+}
+
+type backoff struct {
+	free      int
+	threshold int
+}
+
+func newLoginThrottle(repo userrepo.LoginFailures, cfg config.AccountConfig) loginThrottle {
+	t := loginThrottle{
+		failures: repo,
+		lockout:  time.Duration(cfg.LoginLockoutMinutes) * time.Minute,
+		account:  backoff{free: accountFreeFailures, threshold: cfg.LoginAccountLockoutThreshold},
+		ip:       backoff{free: ipFreeFailures, threshold: cfg.LoginIPLockoutThreshold},
+	}
+	if cfg.LoginFailureStore == loginFailureStoreMemory {
+		t.failures = userrepo.NewMemoryLoginFailures()
+	}
+	if t.lockout <= 0 {
+		t.lockout = defaultLoginLockout
+	}
+	if t.account.threshold <= 0 {
+		t.account.threshold = defaultAccountLockoutThreshold
+	}
+	if t.ip.threshold <= 0 {
+		t.ip.threshold = defaultIPLockoutThreshold
+	}
+	return t
+}
+
+// delay is how long a key has to wait after its nth failure in a row.
+func (b backoff) delay(failures int, lockout time.Duration) time.Duration {
+	switch {
+	case failures >= b.threshold:
+		return lockout
+	case failures <= b.free:
+		return 0
+	}
+	if exp := failures - b.free - 1; exp < 30 {
+		return min(time.Second<<exp, lockout)
+	}
+	return lockout
+}
+
+// schedule lists the delays after the first, second, ... failure up to the
+// threshold.
+func (b backoff) schedule(lockout time.Duration) []time.Duration {
+	delays := make([]time.Duration, b.threshold)
+	for i := range delays {
+		delays[i] = b.delay(i+1, lockout)
+	}
+	return delays
+}
+
+type loginKey struct {
+	key     string
+	backoff backoff
+}
+
+func accountKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func (t loginThrottle) keys(email, ipAddress string) []loginKey {
+	return []loginKey{
+		{key: accountKey(email), backoff: t.account},
+		{key: "ip:" + ipAddress, backoff: t.ip},
+	}
+}
+
+// loginAttempt is an attempt counted for the keys of an account and a client
+// address, with the count each key reached.
+type loginAttempt struct {
+	ipAddress string
+	keys      []loginKey
+	failures  []int
+}
+
+// reserveLogin counts an attempt for the account and the client address
+// before the credentials are checked. It returns a RetryAfterError while
+// either has to wait. The attempt has to end in failLogin or releaseLogin.
+func (u *user) reserveLogin(ctx context.Context, email, ipAddress string) (*loginAttempt, error) {
+	attempt := &loginAttempt{ipAddress: ipAddress}
+	for _, k := range u.throttle.keys(email, ipAddress) {
+		failures, err := u.throttle.failures.ReserveLoginAttempt(ctx, k.key, loginFailureWindow, k.backoff.schedule(u.throttle.lockout))
+		if err != nil {
+			u.releaseLogin(ctx, attempt)
+			return nil, err
+		}
+		if failures == 0 {
+			u.releaseLogin(ctx, attempt)
+			wait, err := u.throttle.failures.LoginBlockedFor(ctx, k.key)
+			if err != nil {
+				return nil, err
+			}
+			// the block may have ended just now
+			wait = max(wait, time.Second)
+			u.securityEvent("login_blocked", ipAddress, logrus.Fields{"key": k.key, "retry_after": wait.Round(time.Second).String()})
+			return nil, &entity.RetryAfterError{RetryAfter: wait}
+		}
+		attempt.keys = append(attempt.keys, k)
+		attempt.failures = append(attempt.failures, failures)
+	}
+	return attempt, nil
+}
+
+// failLogin ends an attempt with a wrong password or second factor code. It
+// was counted already; this only reports it.
+func (u *user) failLogin(attempt *loginAttempt) {
+	for i, k := range attempt.keys {
+		failures := attempt.failures[i]
+		u.securityEvent("login_failed", attempt.ipAddress, logrus.Fields{"key": k.key, "failures": failures})
+		if failures == k.backoff.threshold {
+			u.securityEvent("login_locked", attempt.ipAddress, logrus.Fields{"key": k.key, "failures": failures, "duration": u.throttle.lockout.String()})
+		}
+	}
+}
+
+// releaseLogin takes back an attempt whose credentials were right or that
+// could not be checked.
+func (u *user) releaseLogin(ctx context.Context, attempt *loginAttempt) {
+	for i, k := range attempt.keys {
+		if err := u.throttle.failures.ReleaseLoginAttempt(ctx, k.key, attempt.failures[i]); err != nil {
+			u.logger.Errorf("release login attempt: %v", err)
+		}
+	}
+}
+
+// clearLoginFailures forgets the failures of the account after a successful
+// login. Those of the client address are kept, otherwise logging in to an own
+// account would reset the count for guessing others.
+func (u *user) clearLoginFailures(ctx context.Context, email string) {
+	if err := u.throttle.failures.ClearLoginFailures(ctx, accountKey(email)); err != nil {
+		u.logger.Errorf("clear login failures: %v", err)
+	}
+}
+
+// PurgeLoginFailures deletes failure counts that have started over.
+func (u *user) PurgeLoginFailures(ctx context.Context) (int64, error) {
+	return u.throttle.failures.PurgeLoginFailures(ctx, loginFailureWindow)
+}
+
+func (u *user) securityEvent(event, ipAddress string, fields logrus.Fields) {
+	fields["event"] = event
+	fields["ip"] = ipAddress
+	u.logger.WithFields(fields).Warn("security event")
+}
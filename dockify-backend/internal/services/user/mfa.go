@@ -0,0 +1,303 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/askaroe/dockify-backend/pkg/token"
+	"github.com/askaroe/dockify-backend/pkg/totp"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	mfaIssuer = "Dockify"
+
+	// codes of the previous and the next period are accepted as well, for
+	// clock drift between the phone and the server
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// LoginMFA finishes a login started by Login with the challenge token and a
+// code from the authenticator app or a recovery code. Wrong codes count as
+// failed logins.
+func (u *user) LoginMFA(ctx context.Context, request entity.MFALoginRequest) (entity.LoginResponse, error) {
+	claims, err := u.tokens.Parse(request.MFAToken, token.TypeMFAChallenge)
+	if err != nil {
+		return entity.LoginResponse{}, err
+	}
+
+	userModel, err := u.repo.User.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return entity.LoginResponse{}, err
+	}
+
+	mfa, err := u.repo.User.GetMFA(ctx, userModel.ID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && mfa.EnabledAt == nil) {
+		// disabled since the challenge was issued, log in again
+		return entity.LoginResponse{}, token.ErrInvalidToken
+	}
+	if err != nil {
+		return entity.LoginResponse{}, err
+	}
+
+	if err := u.checkCode(ctx, userModel, mfa, request.Code, request.IPAddress, true); err != nil {
+		return entity.LoginResponse{}, err
+	}
+
+	u.clearLoginFailures(ctx, userModel.Email)
+	return u.startSession(ctx, userModel, request.DeviceName, request.Platform, request.IPAddress)
+}
+
+func (u *user) GetMFAStatus(ctx context.Context, userID int) (entity.MFAStatusResponse, error) {
+	mfa, err := u.repo.User.GetMFA(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && mfa.EnabledAt == nil) {
+		return entity.MFAStatusResponse{}, nil
+	}
+	if err != nil {
+		return entity.MFAStatusResponse{}, err
+	}
+
+	left, err := u.repo.User.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return entity.MFAStatusResponse{}, err
+	}
+	return entity.MFAStatusResponse{Enabled: true, EnabledAt: mfa.EnabledAt, RecoveryCodesLeft: left}, nil
+}
+
+// SetupMFA creates a new TOTP secret for the user. It takes effect once
+// EnableMFA confirmed that the authenticator app produces matching codes.
+func (u *user) SetupMFA(ctx context.Context, userID int) (entity.MFASetupResponse, error) {
+	userModel, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return entity.MFASetupResponse{}, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return entity.MFASetupResponse{}, err
+	}
+	sealed, err := u.secrets.Seal(secret)
+	if err != nil {
+		return entity.MFASetupResponse{}, fmt.Errorf("encrypt totp secret: %w", err)
+	}
+
+	err = u.repo.User.SetupMFA(ctx, userID, sealed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.MFASetupResponse{}, fmt.Errorf("%w: two-factor authentication is already enabled", entity.ErrInvalidParam)
+	}
+	if err != nil {
+		return entity.MFASetupResponse{}, err
+	}
+
+	return entity.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(mfaIssuer, userModel.Email, secret),
+	}, nil
+}
+
+// EnableMFA turns on the secret from SetupMFA after checking a code from it
+// and returns the first set of recovery codes.
+func (u *user) EnableMFA(ctx context.Context, userID int, request entity.MFACodeRequest) (entity.RecoveryCodesResponse, error) {
+	userModel, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return entity.RecoveryCodesResponse{}, err
+	}
+
+	mfa, err := u.repo.User.GetMFA(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.RecoveryCodesResponse{}, fmt.Errorf("%w: set up two-factor authentication first", entity.ErrInvalidParam)
+	}
+	if err != nil {
+		return entity.RecoveryCodesResponse{}, err
+	}
+	if mfa.EnabledAt != nil {
+		return entity.RecoveryCodesResponse{}, fmt.Errorf("%w: two-factor authentication is already enabled", entity.ErrInvalidParam)
+	}
+
+	if err := u.checkCode(ctx, userModel, mfa, request.Code, request.IPAddress, false); err != nil {
+		return entity.RecoveryCodesResponse{}, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return entity.RecoveryCodesResponse{}, err
+	}
+	err = u.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		if err := tx.User.EnableMFA(ctx, userID); err != nil {
+			return err
+		}
+		return tx.User.ReplaceRecoveryCodes(ctx, userID, hashes)
+	})
+	if err != nil {
+		return entity.RecoveryCodesResponse{}, fmt.Errorf("enable mfa: %w", err)
+	}
+
+	u.notifyMFAChange(ctx, userModel, "Two-factor authentication was turned on",
+		"Logging in to your Dockify account now needs a code from your authenticator app.")
+	return entity.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA turns two-factor authentication off after checking the password
+// and a code, which may be a recovery code for a lost phone.
+func (u *user) DisableMFA(ctx context.Context, userID int, request entity.DisableMFARequest) error {
+	userModel, mfa, err := u.enabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+	attempt, err := u.reserveLogin(ctx, userModel.Email, request.IPAddress)
+	if err != nil {
+		return err
+	}
+	if err := u.hasher.Verify(userModel.PasswordHash, request.Password); err != nil {
+		u.failLogin(attempt)
+		return fmt.Errorf("%w: password is incorrect", entity.ErrForbidden)
+	}
+	u.releaseLogin(ctx, attempt)
+	if err := u.checkCode(ctx, userModel, mfa, request.Code, request.IPAddress, true); err != nil {
+		return err
+	}
+
+	err = u.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		return tx.User.DeleteMFA(ctx, userID)
+	})
+	if err != nil {
+		return fmt.Errorf("disable mfa: %w", err)
+	}
+
+	u.notifyMFAChange(ctx, userModel, "Two-factor authentication was turned off",
+		"Logging in to your Dockify account no longer needs a code from your authenticator app. "+
+			"If this was not you, change your password and turn it on again right away.")
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user after
+// checking a code from the authenticator app.
+func (u *user) RegenerateRecoveryCodes(ctx context.Context, userID int, request entity.MFACodeRequest) (entity.RecoveryCodesResponse, error) {
+	userModel, mfa, err := u.enabledMFA(ctx, userID)
+	if err != nil {
+		return entity.RecoveryCodesResponse{}, err
+	}
+	if err := u.checkCode(ctx, userModel, mfa, request.Code, request.IPAddress, false); err != nil {
+		return entity.RecoveryCodesResponse{}, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return entity.RecoveryCodesResponse{}, err
+	}
+	err = u.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		return tx.User.ReplaceRecoveryCodes(ctx, userID, hashes)
+	})
+	if err != nil {
+		return entity.RecoveryCodesResponse{}, fmt.Errorf("regenerate recovery codes: %w", err)
+	}
+	return entity.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// mfaEnabled reports whether logging in needs a second factor.
+func (u *user) mfaEnabled(ctx context.Context, userID int) (bool, error) {
+	mfa, err := u.repo.User.GetMFA(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return mfa.EnabledAt != nil, nil
+}
+
+func (u *user) enabledMFA(ctx context.Context, userID int) (models.User, models.MFA, error) {
+	userModel, err := u.GetProfile(ctx, userID)
+	if err != nil {
+		return models.User{}, models.MFA{}, err
+	}
+
+	mfa, err := u.repo.User.GetMFA(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && mfa.EnabledAt == nil) {
+		return models.User{}, models.MFA{}, fmt.Errorf("%w: two-factor authentication is not enabled", entity.ErrInvalidParam)
+	}
+	if err != nil {
+		return models.User{}, models.MFA{}, err
+	}
+	return userModel, mfa, nil
+}
+
+// checkCode verifies a code of a signed in user. Wrong codes are throttled
+// like failed logins, so a stolen session can not be used to guess them.
+func (u *user) checkCode(ctx context.Context, userModel models.User, mfa models.MFA, code, ipAddress string, allowRecovery bool) error {
+	attempt, err := u.reserveLogin(ctx, userModel.Email, ipAddress)
+	if err != nil {
+		return err
+	}
+
+	ok, err := u.verifySecondFactor(ctx, mfa, code, allowRecovery)
+	if err == nil && !ok {
+		u.failLogin(attempt)
+		return fmt.Errorf("%w: invalid code", entity.ErrForbidden)
+	}
+	u.releaseLogin(ctx, attempt)
+	return err
+}
+
+// verifySecondFactor accepts a TOTP code that was not used before or, when
+// allowRecovery is set, an unused recovery code, which is used up.
+func (u *user) verifySecondFactor(ctx context.Context, mfa models.MFA, code string, allowRecovery bool) (bool, error) {
+	code = normalizeCode(code)
+
+	if len(code) == totp.Digits {
+		secret, err := u.secrets.Open(mfa.Secret)
+		if err != nil {
+			return false, fmt.Errorf("decrypt totp secret: %w", err)
+		}
+		step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+		if !ok || step <= mfa.LastUsedStep {
+			return false, nil
+		}
+		return u.repo.User.UseMFAStep(ctx, mfa.UserId, step)
+	}
+
+	if !allowRecovery {
+		return false, nil
+	}
+	return u.repo.User.UseRecoveryCode(ctx, mfa.UserId, hashToken(code))
+}
+
+// newRecoveryCodes returns codes to show the user, formatted like
+// "k3j9d-x2mfq", and the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeCode drops the separators users type or paste along with a code.
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
+
+func (u *user) notifyMFAChange(ctx context.Context, userModel models.User, subject, body string) {
+	if err := u.mailer.Send(ctx, userModel.Email, subject, body); err != nil {
+		u.logger.Errorf("send mfa change email to user %d: %v", userModel.ID, err)
+	}
+}
@@ -0,0 +1,132 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/askaroe/dockify-backend/internal/models"
+	"github.com/askaroe/dockify-backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	passwordResetTTL = time.Hour
+
+	forgotPasswordPerEmail = 3
+	forgotPasswordPerIP    = 10
+	forgotPasswordWindow   = time.Hour
+	resetPasswordPerIP     = 10
+	resetPasswordWindow    = 15 * time.Minute
+)
+
+// ForgotPassword emails a password reset link if an account exists for the
+// email. The outcome is the same whether or not it does, and the email is
+// sent in the background so the response time does not tell either. Requests
+// beyond the per-email limit are dropped silently for the same reason; only
+// the per-IP limit is reported.
+func (u *user) ForgotPassword(ctx context.Context, request entity.ForgotPasswordRequest) error {
+	if ok, retryAfter := u.forgotByIP.Allow(request.IPAddress); !ok {
+		return &entity.RetryAfterError{RetryAfter: retryAfter}
+	}
+
+	email := strings.TrimSpace(request.Email)
+	if ok, _ := u.forgotByEmail.Allow(strings.ToLower(email)); !ok {
+		return nil
+	}
+
+	go func() {
+		if err := u.sendPasswordReset(context.WithoutCancel(ctx), email, request.IPAddress); err != nil {
+			u.logger.Errorf("send password reset email: %v", err)
+		}
+	}()
+	return nil
+}
+
+// ResetPassword sets a new password with a token from ForgotPassword. The
+// token and every other outstanding one of the user are used up, and all
+// sessions are signed out.
+func (u *user) ResetPassword(ctx context.Context, request entity.ResetPasswordRequest) error {
+	if ok, retryAfter := u.resetByIP.Allow(request.IPAddress); !ok {
+		return &entity.RetryAfterError{RetryAfter: retryAfter}
+	}
+
+	var userModel models.User
+	err := u.repo.WithTx(ctx, func(tx *repository.Repository) error {
+		userID, err := tx.User.UsePasswordResetToken(ctx, hashToken(request.Token))
+		if err != nil {
+			return err
+		}
+		if userModel, err = tx.User.GetUserByID(ctx, userID); err != nil {
+			return err
+		}
+		// a rejected password rolls the token back so it can be tried again
+		hash, err := u.checkNewPassword("new_password", request.NewPassword, userModel.Username, userModel.Email)
+		if err != nil {
+			return err
+		}
+		if err := tx.User.SetPasswordHash(ctx, userModel.ID, hash); err != nil {
+			return err
+		}
+		if err := tx.User.InvalidatePasswordResetTokens(ctx, userModel.ID); err != nil {
+			return err
+		}
+		return tx.User.RevokeUserSessions(ctx, userModel.ID)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: invalid or expired token", entity.ErrInvalidParam)
+	}
+	var validationErr *entity.ValidationError
+	if errors.As(err, &validationErr) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("reset password: %w", err)
+	}
+
+	if err := u.mailer.Send(ctx, userModel.Email, "Your password was changed",
+		"The password of your Dockify account was reset and all devices were signed out. "+
+			"If this was not you, reset your password again right away."); err != nil {
+		u.logger.Errorf("send password changed email to user %d: %v", userModel.ID, err)
+	}
+	return nil
+}
+
+func (u *user) sendPasswordReset(ctx context.Context, email, ipAddress string) error {
+	userModel, err := u.repo.User.GetUserByEmail(ctx, email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("generate password reset token: %w", err)
+	}
+	t := base64.RawURLEncoding.EncodeToString(b)
+
+	err = u.repo.User.CreatePasswordResetToken(ctx, models.PasswordResetToken{
+		UserId:    userModel.ID,
+		TokenHash: hashToken(t),
+		IPAddress: ipAddress,
+	}, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	body := "Reset the password of your Dockify account with this code, valid for one hour:\n\n" + t
+	if u.appURL != "" {
+		body = "Reset the password of your Dockify account within one hour:\n\n" +
+			u.appURL + "/reset-password?token=" + url.QueryEscape(t)
+	}
+	body += "\n\nIf you did not ask for this, ignore this email; your password stays the same."
+	return u.mailer.Send(ctx, userModel.Email, "Reset your password", body)
+}
@@ -2,28 +2,116 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/askaroe/dockify-backend/config"
 	"github.com/askaroe/dockify-backend/internal/entity"
 	"github.com/askaroe/dockify-backend/internal/models"
 	"github.com/askaroe/dockify-backend/internal/repository"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/askaroe/dockify-backend/pkg/mailer"
+	"github.com/askaroe/dockify-backend/pkg/password"
+	"github.com/askaroe/dockify-backend/pkg/ratelimit"
+	"github.com/askaroe/dockify-backend/pkg/secretbox"
+	"github.com/askaroe/dockify-backend/pkg/token"
+	"github.com/askaroe/dockify-backend/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultDeletionGraceDays = 30
 )
 
 type User interface {
 	Register(ctx context.Context, request entity.UserRegisterRequest) (int, error)
-	Login(ctx context.Context, request entity.UserLoginRequest) (models.User, error)
+	Login(ctx context.Context, request entity.UserLoginRequest) (entity.LoginResponse, error)
+	RefreshToken(ctx context.Context, request entity.RefreshTokenRequest) (entity.TokenResponse, error)
+	Logout(ctx context.Context, userID, sessionID int) error
+	LogoutAll(ctx context.Context, userID int) error
+	GetSessions(ctx context.Context, userID, currentSessionID int) ([]entity.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID int) error
+	CheckSession(ctx context.Context, userID, sessionID int) error
+
+	GetProfile(ctx context.Context, userID int) (models.User, error)
+	UpdateProfile(ctx context.Context, request entity.UpdateProfileRequest) (models.User, error)
+	ChangePassword(ctx context.Context, userID, sessionID int, request entity.ChangePasswordRequest) error
+	SendEmailVerification(ctx context.Context, userID int) error
+	VerifyEmail(ctx context.Context, request entity.VerifyEmailRequest) error
+	DeleteAccount(ctx context.Context, userID int, request entity.DeleteAccountRequest) (entity.AccountDeletionResponse, error)
+	PurgeDeletedAccounts(ctx context.Context) (int64, error)
+	PurgeLoginFailures(ctx context.Context) (int64, error)
+
+	ForgotPassword(ctx context.Context, request entity.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, request entity.ResetPasswordRequest) error
+
+	LoginMFA(ctx context.Context, request entity.MFALoginRequest) (entity.LoginResponse, error)
+	GetMFAStatus(ctx context.Context, userID int) (entity.MFAStatusResponse, error)
+	SetupMFA(ctx context.Context, userID int) (entity.MFASetupResponse, error)
+	EnableMFA(ctx context.Context, userID int, request entity.MFACodeRequest) (entity.RecoveryCodesResponse, error)
+	DisableMFA(ctx context.Context, userID int, request entity.DisableMFARequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int, request entity.MFACodeRequest) (entity.RecoveryCodesResponse, error)
 }
 
 type user struct {
-	repo *repository.Repository
+	repo      *repository.Repository
+	tokens    *token.Manager
+	mailer    mailer.Sender
+	policy    *password.Policy
+	hasher    *password.Hasher
+	secrets   *secretbox.Box
+	appURL    string
+	graceDays int
+	throttle  loginThrottle
+	logger    *utils.Logger
+
+	// dummyHash is checked when nobody has the email of a login, so that
+	// takes as long as a wrong password
+	dummyHash string
+
+	forgotByEmail *ratelimit.Limiter
+	forgotByIP    *ratelimit.Limiter
+	resetByIP     *ratelimit.Limiter
 }
 
-func NewUserService(repo *repository.Repository) User {
-	return &user{repo: repo}
+func NewUserService(repo *repository.Repository, tokens *token.Manager, sender mailer.Sender, policy *password.Policy, hasher *password.Hasher, secrets *secretbox.Box, cfg config.AccountConfig, logger *utils.Logger) User {
+	u := &user{
+		repo:      repo,
+		tokens:    tokens,
+		mailer:    sender,
+		policy:    policy,
+		hasher:    hasher,
+		secrets:   secrets,
+		appURL:    strings.TrimSuffix(cfg.AppURL, "/"),
+		graceDays: cfg.AccountDeletionGraceDays,
+		throttle:  newLoginThrottle(repo.LoginFailures, cfg),
+		logger:    logger,
+
+		forgotByEmail: ratelimit.New(forgotPasswordPerEmail, forgotPasswordWindow),
+		forgotByIP:    ratelimit.New(forgotPasswordPerIP, forgotPasswordWindow),
+		resetByIP:     ratelimit.New(resetPasswordPerIP, resetPasswordWindow),
+	}
+	if u.graceDays <= 0 {
+		u.graceDays = defaultDeletionGraceDays
+	}
+
+	var err error
+	if u.dummyHash, err = hasher.Hash(rand.Text()); err != nil {
+		logger.Errorf("hash dummy password: %v", err)
+	}
+	return u
 }
 
 func (u *user) Register(ctx context.Context, request entity.UserRegisterRequest) (int, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.MinCost)
+	if err := u.normalizeRegistration(&request); err != nil {
+		return 0, err
+	}
+	birthDate, _ := parseBirthDate(request.BirthDate)
+
+	hash, err := u.hasher.Hash(request.Password)
 	if err != nil {
 		return 0, err
 	}
@@ -32,22 +120,241 @@ func (u *user) Register(ctx context.Context, request entity.UserRegisterRequest)
 		FirstName:    request.FirstName,
 		LastName:     request.LastName,
 		Email:        request.Email,
-		PasswordHash: string(b),
+		PasswordHash: hash,
+		BirthDate:    birthDate,
+	}
+
+	userModel.ID, err = u.repo.User.CreateUser(ctx, userModel)
+	if err != nil {
+		return 0, conflictError(err)
 	}
 
-	return u.repo.User.CreateUser(ctx, userModel)
+	// registration succeeds even when the email cannot be sent, the user can
+	// ask for another one
+	go func() {
+		if err := u.sendVerification(context.WithoutCancel(ctx), userModel); err != nil {
+			u.logger.Errorf("send verification email to user %d: %v", userModel.ID, err)
+		}
+	}()
+
+	return userModel.ID, nil
 }
 
-func (u *user) Login(ctx context.Context, request entity.UserLoginRequest) (models.User, error) {
+// Login checks the password. When the user has two-factor authentication
+// enabled it returns an MFAChallengeError instead of a session; the challenge
+// token from it is exchanged for a session by LoginMFA.
+func (u *user) Login(ctx context.Context, request entity.UserLoginRequest) (entity.LoginResponse, error) {
+	if email, ok := normalizeEmail(request.Email); ok {
+		request.Email = email
+	}
+	attempt, err := u.reserveLogin(ctx, request.Email, request.IPAddress)
+	if err != nil {
+		return entity.LoginResponse{}, err
+	}
+
 	userModel, err := u.repo.User.GetUserByEmail(ctx, request.Email)
+	if err == nil {
+		err = u.hasher.Verify(userModel.PasswordHash, request.Password)
+	} else if errors.Is(err, pgx.ErrNoRows) {
+		_ = u.hasher.Verify(u.dummyHash, request.Password)
+	}
+	// unknown emails are counted like existing accounts so the responses do
+	// not reveal which accounts exist
+	if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, password.ErrMismatch) {
+		u.failLogin(attempt)
+		return entity.LoginResponse{}, err
+	}
+	u.releaseLogin(ctx, attempt)
+	if err != nil {
+		return entity.LoginResponse{}, err
+	}
+	u.rehashPassword(ctx, &userModel, request.Password)
+
+	mfaEnabled, err := u.mfaEnabled(ctx, userModel.ID)
+	if err != nil {
+		return entity.LoginResponse{}, err
+	}
+	if mfaEnabled {
+		challenge, err := u.tokens.IssueMFAChallenge(userModel.ID)
+		if err != nil {
+			return entity.LoginResponse{}, fmt.Errorf("issue mfa challenge: %w", err)
+		}
+		return entity.LoginResponse{}, &entity.MFAChallengeError{Token: challenge, ExpiresIn: token.MFAChallengeTTL}
+	}
+
+	u.clearLoginFailures(ctx, request.Email)
+	return u.startSession(ctx, userModel, request.DeviceName, request.Platform, request.IPAddress)
+}
+
+// startSession signs the user in on a new device after all factors were
+// checked.
+func (u *user) startSession(ctx context.Context, userModel models.User, deviceName, platform, ipAddress string) (entity.LoginResponse, error) {
+	// logging in during the grace period restores a deleted account
+	if userModel.DeletionScheduledAt != nil {
+		if err := u.repo.User.CancelDeletion(ctx, userModel.ID); err != nil {
+			return entity.LoginResponse{}, err
+		}
+		userModel.DeletionScheduledAt = nil
+	}
+
+	sessionID, err := u.repo.User.CreateSession(ctx, models.Session{
+		UserId:     userModel.ID,
+		DeviceName: deviceName,
+		Platform:   platform,
+		IPAddress:  ipAddress,
+	})
+	if err != nil {
+		return entity.LoginResponse{}, err
+	}
+
+	tokens, err := u.rotateTokens(ctx, userModel.ID, sessionID, "", ipAddress)
+	if err != nil {
+		return entity.LoginResponse{}, err
+	}
+
+	return entity.LoginResponse{User: userModel, TokenResponse: tokens}, nil
+}
+
+// RefreshToken rotates the session's refresh token. Presenting a refresh token
+// that has already been rotated means it leaked, so the whole session (the
+// token family) is revoked and every descendant token stops working.
+func (u *user) RefreshToken(ctx context.Context, request entity.RefreshTokenRequest) (entity.TokenResponse, error) {
+	claims, err := u.tokens.Parse(request.RefreshToken, token.TypeRefresh)
+	if err != nil {
+		return entity.TokenResponse{}, err
+	}
+
+	session, err := u.repo.User.GetSessionByID(ctx, claims.SessionID)
 	if err != nil {
-		return models.User{}, err
+		return entity.TokenResponse{}, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(userModel.PasswordHash), []byte(request.Password))
+	if session.UserId != claims.UserID {
+		return entity.TokenResponse{}, token.ErrInvalidToken
+	}
+
+	if session.RevokedAt != nil {
+		return entity.TokenResponse{}, entity.ErrSessionClosed
+	}
+
+	tokens, err := u.rotateTokens(ctx, claims.UserID, session.ID, hashToken(request.RefreshToken), request.IPAddress)
+	if errors.Is(err, entity.ErrTokenReused) {
+		if revokeErr := u.repo.User.RevokeSession(ctx, session.UserId, session.ID); revokeErr != nil {
+			return entity.TokenResponse{}, fmt.Errorf("revoke reused session: %w", revokeErr)
+		}
+	}
+	if err != nil {
+		return entity.TokenResponse{}, err
+	}
+
+	return tokens, nil
+}
+
+func (u *user) Logout(ctx context.Context, userID, sessionID int) error {
+	return u.RevokeSession(ctx, userID, sessionID)
+}
+
+func (u *user) LogoutAll(ctx context.Context, userID int) error {
+	return u.repo.User.RevokeUserSessions(ctx, userID)
+}
+
+func (u *user) GetSessions(ctx context.Context, userID, currentSessionID int) ([]entity.SessionResponse, error) {
+	sessions, err := u.repo.User.GetActiveSessions(ctx, userID)
 	if err != nil {
-		return models.User{}, err
+		return nil, err
+	}
+
+	response := make([]entity.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, entity.SessionResponse{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			Platform:   session.Platform,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return response, nil
+}
+
+func (u *user) RevokeSession(ctx context.Context, userID, sessionID int) error {
+	err := u.repo.User.RevokeSession(ctx, userID, sessionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrNotFound
+	}
+	return err
+}
+
+// CheckSession returns entity.ErrSessionClosed when the session of an access
+// token was signed out or no longer exists, so the token stops working before
+// it expires.
+func (u *user) CheckSession(ctx context.Context, userID, sessionID int) error {
+	session, err := u.repo.User.GetSessionByID(ctx, sessionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ErrSessionClosed
 	}
+	if err != nil {
+		return fmt.Errorf("check session: %w", err)
+	}
+	if session.UserId != userID || session.RevokedAt != nil {
+		return entity.ErrSessionClosed
+	}
+	return nil
+}
+
+// rotateTokens issues a new token pair for the session and stores the hash of
+// the new refresh token in place of currentHash.
+func (u *user) rotateTokens(ctx context.Context, userID, sessionID int, currentHash, ipAddress string) (entity.TokenResponse, error) {
+	accessToken, err := u.tokens.Issue(userID, sessionID, token.TypeAccess)
+	if err != nil {
+		return entity.TokenResponse{}, fmt.Errorf("issue access token: %w", err)
+	}
+
+	refreshToken, err := u.tokens.Issue(userID, sessionID, token.TypeRefresh)
+	if err != nil {
+		return entity.TokenResponse{}, fmt.Errorf("issue refresh token: %w", err)
+	}
+
+	rotated, err := u.repo.User.RotateSessionToken(ctx, sessionID, currentHash, hashToken(refreshToken), ipAddress)
+	if err != nil {
+		return entity.TokenResponse{}, err
+	}
+
+	if !rotated {
+		return entity.TokenResponse{}, entity.ErrTokenReused
+	}
+
+	return entity.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(u.tokens.AccessTTL().Seconds()),
+	}, nil
+}
+
+// rehashPassword upgrades a hash made with an older algorithm or weaker
+// parameters to the configured ones. It needs the plain password, so it runs
+// after a successful login; a failure only leaves the old hash in place.
+func (u *user) rehashPassword(ctx context.Context, userModel *models.User, password string) {
+	if !u.hasher.NeedsRehash(userModel.PasswordHash) {
+		return
+	}
+
+	hash, err := u.hasher.Hash(password)
+	if err == nil {
+		err = u.repo.User.SetPasswordHash(ctx, userModel.ID, hash)
+	}
+	if err != nil {
+		u.logger.Errorf("rehash password of user %d: %v", userModel.ID, err)
+		return
+	}
+	userModel.PasswordHash = hash
+}
 
-	return userModel, nil
+func hashToken(t string) string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:])
 }
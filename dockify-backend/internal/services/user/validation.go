@@ -0,0 +1,130 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/askaroe/dockify-backend/internal/entity"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 50
+	// first_name and last_name are VARCHAR(50)
+	maxNameLength  = 50
+	maxEmailLength = 100
+
+	uniqueViolation = "23505"
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// uniqueFields maps the unique constraints of the users table to the request
+// field they protect.
+var uniqueFields = map[string]string{
+	"users_username_key":    "username",
+	"users_email_key":       "email",
+	"users_email_lower_key": "email",
+}
+
+// fieldErrors collects what is wrong with a request, field by field.
+type fieldErrors []entity.FieldError
+
+func (f *fieldErrors) add(field, message string) {
+	*f = append(*f, entity.FieldError{Field: field, Message: message})
+}
+
+// err returns a ValidationError with the collected fields, or nil.
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &entity.ValidationError{Fields: f}
+}
+
+// normalizeRegistration trims the request and checks every field, reporting
+// all invalid fields at once.
+func (u *user) normalizeRegistration(request *entity.UserRegisterRequest) error {
+	var errs fieldErrors
+
+	request.Username = strings.TrimSpace(request.Username)
+	if msg := checkUsername(request.Username); msg != "" {
+		errs.add("username", msg)
+	}
+
+	if email, ok := normalizeEmail(request.Email); ok {
+		request.Email = email
+	} else {
+		errs.add("email", "must be a valid email address")
+	}
+
+	if err := u.policy.Check(request.Password, request.Username, request.Email); err != nil {
+		errs.add("password", err.Error())
+	}
+
+	request.FirstName = strings.TrimSpace(request.FirstName)
+	if utf8.RuneCountInString(request.FirstName) > maxNameLength {
+		errs.add("first_name", fmt.Sprintf("must not exceed %d characters", maxNameLength))
+	}
+	request.LastName = strings.TrimSpace(request.LastName)
+	if utf8.RuneCountInString(request.LastName) > maxNameLength {
+		errs.add("last_name", fmt.Sprintf("must not exceed %d characters", maxNameLength))
+	}
+
+	if _, err := parseBirthDate(request.BirthDate); err != nil {
+		errs.add("birth_date", "must be a past date in YYYY-MM-DD format")
+	}
+
+	return errs.err()
+}
+
+// checkUsername returns what is wrong with username, or "".
+func checkUsername(username string) string {
+	if n := utf8.RuneCountInString(username); n < minUsernameLength || n > maxUsernameLength {
+		return fmt.Sprintf("must be %d to %d characters", minUsernameLength, maxUsernameLength)
+	}
+	if !usernamePattern.MatchString(username) {
+		return "may only contain letters, digits, '_', '.' and '-'"
+	}
+	return ""
+}
+
+// normalizeEmail returns the bare address in lower case when value is a
+// single valid email address without a display name. Addresses differing only
+// in case belong to the same account.
+func normalizeEmail(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value || len(addr.Address) > maxEmailLength {
+		return "", false
+	}
+	return strings.ToLower(addr.Address), true
+}
+
+// checkNewPassword applies the password policy to a new password and hashes
+// it. field names the request field in the error.
+func (u *user) checkNewPassword(field, password string, identifiers ...string) (string, error) {
+	if err := u.policy.Check(password, identifiers...); err != nil {
+		return "", &entity.ValidationError{Fields: []entity.FieldError{{Field: field, Message: err.Error()}}}
+	}
+
+	return u.hasher.Hash(password)
+}
+
+// conflictError turns a unique violation on the users table into a
+// ConflictError naming the field, and returns other errors unchanged.
+func conflictError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return err
+	}
+	if field, ok := uniqueFields[pgErr.ConstraintName]; ok {
+		return &entity.ConflictError{Field: field}
+	}
+	return &entity.ConflictError{Field: "username or email"}
+}
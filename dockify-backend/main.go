@@ -1,14 +1,27 @@
 package main
 
 import (
+	"context"
+	"os"
+	"time"
+	_ "time/tzdata" // timezone database for aggregation buckets in minimal containers
+
 	"github.com/askaroe/dockify-backend/config"
 	_ "github.com/askaroe/dockify-backend/docs"
+	"github.com/askaroe/dockify-backend/internal/gateway"
 	"github.com/askaroe/dockify-backend/internal/handlers"
+	"github.com/askaroe/dockify-backend/internal/notifier"
 	"github.com/askaroe/dockify-backend/internal/repository"
 	"github.com/askaroe/dockify-backend/internal/router"
 	"github.com/askaroe/dockify-backend/internal/server"
 	"github.com/askaroe/dockify-backend/internal/services"
+	"github.com/askaroe/dockify-backend/internal/services/hospital"
+	"github.com/askaroe/dockify-backend/internal/services/user"
+	"github.com/askaroe/dockify-backend/pkg/mailer"
+	"github.com/askaroe/dockify-backend/pkg/password"
 	"github.com/askaroe/dockify-backend/pkg/psql"
+	"github.com/askaroe/dockify-backend/pkg/secretbox"
+	"github.com/askaroe/dockify-backend/pkg/token"
 	"github.com/askaroe/dockify-backend/pkg/utils"
 )
 
@@ -16,6 +29,10 @@ import (
 // @version 1.0
 // @description API for Dockify backend.
 // @schemes http https
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and the access token.
 func main() {
 	logger := utils.NewLogger("dockify-backend")
 	cfg, err := config.GetConfig()
@@ -30,16 +47,108 @@ func main() {
 		return
 	}
 
-	repo := repository.NewRepository(db)
+	repo := repository.NewRepository(db, cfg.UsePostGIS)
+
+	if len(os.Args) > 1 && os.Args[1] == "import-hospitals" {
+		if err := importHospitals(context.Background(), hospital.NewHospitalService(repo), os.Args[2:], os.Stdout); err != nil {
+			logger.Fatalf("failed to import hospitals: %v", err)
+		}
+		return
+	}
+
+	tokens, err := token.NewManager(cfg.JWTConfig)
+	if err != nil {
+		logger.Fatalf("failed to initialize token manager: %v", err)
+	}
+
+	policy, err := password.NewPolicy(cfg.BreachedPasswordsFile)
+	if err != nil {
+		logger.Fatalf("failed to load password policy: %v", err)
+	}
+
+	hasher, err := password.NewHasher(cfg.PasswordHashConfig)
+	if err != nil {
+		logger.Fatalf("failed to initialize password hasher: %v", err)
+	}
+
+	secrets, err := secretbox.New(cfg.MFASecretKey)
+	if err != nil {
+		logger.Fatalf("failed to initialize mfa secret encryption: %v", err)
+	}
+
+	gw := gateway.NewGateway(cfg)
 
-	s := services.NewService(repo)
+	m := newMailer(cfg, logger)
+	s := services.NewService(cfg, repo, tokens, gw, newNotifier(cfg, m, logger), m, policy, hasher, secrets, logger)
 
 	handler := handlers.NewHandler(logger, s)
 
-	r := router.NewRouter(handler)
+	r, err := router.NewRouter(handler, tokens, s.User, cfg.TrustedProxies)
+	if err != nil {
+		logger.Fatalf("failed to initialize router: %v", err)
+	}
 
 	srv := server.New(cfg, r, logger)
 	srv.Start()
+	go purgeExpired(s.User, logger)
 	srv.HandleShutdown()
 
 }
+
+// newMailer sends emails through the configured SMTP server. Without one they
+// are written to files in the mail directory, or to the log.
+func newMailer(cfg *config.Config, logger *utils.Logger) mailer.Sender {
+	var (
+		m   mailer.Sender
+		err error
+	)
+	switch {
+	case cfg.SMTPHost != "":
+		m, err = mailer.New(cfg.SMTPConfig)
+	case cfg.MailDir != "":
+		m, err = mailer.NewFileSender(cfg.MailDir, cfg.SMTPFrom)
+	default:
+		m = mailer.NewLogSender(logger)
+	}
+	if err != nil {
+		logger.Fatalf("failed to initialize mailer: %v", err)
+	}
+	return m
+}
+
+// newNotifier delivers in-app notifications to the log until a push channel
+// exists. Email notifications are only available when an SMTP server is
+// configured.
+func newNotifier(cfg *config.Config, m mailer.Sender, logger *utils.Logger) notifier.Notifier {
+	logNotifier := notifier.NewLogNotifier(logger)
+	channels := map[string]notifier.Notifier{
+		notifier.ChannelLog:     logNotifier,
+		notifier.ChannelWebhook: notifier.NewWebhookNotifier(cfg.WebhookConfig),
+	}
+
+	if cfg.SMTPHost != "" {
+		channels[notifier.ChannelEmail] = notifier.NewEmailNotifier(m)
+	}
+
+	return notifier.NewDispatcher(logNotifier, channels)
+}
+
+// purgeExpired deletes accounts whose deletion grace period has passed and
+// stale login failure counts, once at startup and then every hour.
+func purgeExpired(users user.User, logger *utils.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		n, err := users.PurgeDeletedAccounts(context.Background())
+		if err != nil {
+			logger.Errorf("failed to purge deleted accounts: %v", err)
+		} else if n > 0 {
+			logger.Infof("purged %d deleted accounts", n)
+		}
+		if _, err := users.PurgeLoginFailures(context.Background()); err != nil {
+			logger.Errorf("failed to purge login failures: %v", err)
+		}
+		<-ticker.C
+	}
+}
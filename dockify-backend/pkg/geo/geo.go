@@ -0,0 +1,43 @@
+// Package geo holds the spherical geometry shared by location searches.
+package geo
+
+import "math"
+
+// EarthRadius is the mean earth radius in metres used by every distance in
+// the service, including the haversine in SQL queries.
+const EarthRadius = 6371000
+
+const metersPerDegree = EarthRadius * math.Pi / 180
+
+// Distance returns the haversine distance in metres between two points.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := radians(lat1), radians(lat2)
+	dPhi := phi2 - phi1
+	dLambda := radians(lon2 - lon1)
+
+	x := math.Pow(math.Sin(dPhi/2), 2) + math.Cos(phi1)*math.Cos(phi2)*math.Pow(math.Sin(dLambda/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(x))
+}
+
+// SnapToGrid returns the centre of the grid cell of about cellSize metres
+// containing the point. Every point of a cell maps to the same centre, so
+// repeated queries do not narrow down the real position the way random noise
+// would.
+func SnapToGrid(lat, lon, cellSize float64) (float64, float64) {
+	latStep := cellSize / metersPerDegree
+	lat = math.Min(90, math.Max(-90, (math.Floor(lat/latStep)+0.5)*latStep))
+
+	// cells get narrower towards the poles; the row's centre latitude sets the
+	// width so all points of a row share one grid
+	lonStep := cellSize / (metersPerDegree * math.Max(math.Cos(radians(lat)), 0.01))
+	lon = (math.Floor(lon/lonStep) + 0.5) * lonStep
+	if lon > 180 {
+		lon -= 360
+	}
+
+	return lat, lon
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
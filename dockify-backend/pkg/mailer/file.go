@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type fileSender struct {
+	dir  string
+	from mail.Address
+}
+
+// NewFileSender returns a sender that writes every email as an .eml file to
+// dir, for local testing without an SMTP server.
+func NewFileSender(dir, from string) (Sender, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create mail dir: %w", err)
+	}
+	return &fileSender{dir: dir, from: *sender}, nil
+}
+
+func (f *fileSender) Send(_ context.Context, to, subject, body string) error {
+	rcpt, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+	msg, err := compose(f.from, *rcpt, subject, body)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("name mail file: %w", err)
+	}
+	name := time.Now().UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(suffix) + ".eml"
+	if err := os.WriteFile(filepath.Join(f.dir, name), msg, 0o600); err != nil {
+		return fmt.Errorf("write mail file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/askaroe/dockify-backend/pkg/utils"
+)
+
+type logSender struct {
+	logger *utils.Logger
+}
+
+// NewLogSender returns a sender that writes emails to the log instead, for
+// deployments without an SMTP server.
+func NewLogSender(logger *utils.Logger) Sender {
+	return &logSender{logger: logger}
+}
+
+func (l *logSender) Send(_ context.Context, to, subject, body string) error {
+	l.logger.WithField("to", to).Infof("email: %s: %s", subject, body)
+	return nil
+}
@@ -0,0 +1,208 @@
+// Package openinghours understands the common subset of the OpenStreetMap
+// opening_hours syntax: "24/7", weekday selectors (Mo-Fr, Sa,Su), time ranges
+// (08:00-18:00, 22:00-02:00) and "off"/"closed", with rules separated by ";".
+// A later rule replaces the hours of the days it names, as in OSM. Rules for
+// public holidays (PH) are skipped since no holiday calendar is available.
+package openinghours
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+var ErrUnsupported = errors.New("unsupported opening hours")
+
+var weekdays = []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"}
+
+// span is an open interval in minutes from the start of its day. end may go
+// past midnight for ranges that spill into the next day.
+type span struct {
+	start, end int
+}
+
+// Schedule is a parsed opening_hours value. Days are indexed Monday first.
+type Schedule struct {
+	days [7][]span
+}
+
+func Parse(value string) (*Schedule, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("%w: empty value", ErrUnsupported)
+	}
+
+	var s Schedule
+	for _, rule := range strings.Split(value, ";") {
+		rule = strings.TrimSpace(strings.ReplaceAll(rule, ", ", ","))
+		if rule == "" {
+			continue
+		}
+		if err := s.apply(rule); err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+// IsOpen reports whether the schedule is open at t, interpreted in t's location.
+func (s *Schedule) IsOpen(t time.Time) bool {
+	day := (int(t.Weekday()) + 6) % 7
+	minute := t.Hour()*60 + t.Minute()
+
+	for _, sp := range s.days[day] {
+		if minute >= sp.start && minute < sp.end {
+			return true
+		}
+	}
+
+	// ranges of the previous day that run past midnight
+	for _, sp := range s.days[(day+6)%7] {
+		if minute+minutesPerDay < sp.end {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Schedule) apply(rule string) error {
+	if rule == "24/7" {
+		for d := range s.days {
+			s.days[d] = []span{{0, minutesPerDay}}
+		}
+		return nil
+	}
+
+	selector, hours := "", rule
+	if fields := strings.Fields(rule); len(fields) == 2 {
+		selector, hours = fields[0], fields[1]
+	} else if len(fields) != 1 {
+		return fmt.Errorf("%w: %q", ErrUnsupported, rule)
+	}
+
+	// a lone selector ("Mo-Fr") or lone hours ("08:00-18:00")
+	if selector == "" && !strings.Contains(hours, ":") && hours != "off" && hours != "closed" {
+		selector, hours = hours, ""
+	}
+
+	if strings.Contains(selector, "PH") || strings.Contains(selector, "SH") {
+		return nil
+	}
+
+	days, err := parseDays(selector)
+	if err != nil {
+		return err
+	}
+
+	var spans []span
+	switch hours {
+	case "off", "closed":
+		spans = nil
+	case "":
+		spans = []span{{0, minutesPerDay}}
+	default:
+		if spans, err = parseSpans(hours); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range days {
+		s.days[d] = spans
+	}
+	return nil
+}
+
+// parseDays expands a selector such as "Mo-Fr,Su" into weekday indexes. An
+// empty selector means every day.
+func parseDays(selector string) ([]int, error) {
+	if selector == "" {
+		return []int{0, 1, 2, 3, 4, 5, 6}, nil
+	}
+
+	var days []int
+	for _, part := range strings.Split(selector, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+
+		start, err := weekdayIndex(from)
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if isRange {
+			if end, err = weekdayIndex(to); err != nil {
+				return nil, err
+			}
+		}
+
+		// ranges may wrap around the week, e.g. Sa-Mo
+		for d := start; ; d = (d + 1) % 7 {
+			days = append(days, d)
+			if d == end {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func weekdayIndex(name string) (int, error) {
+	for i, w := range weekdays {
+		if w == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown weekday %q", ErrUnsupported, name)
+}
+
+func parseSpans(hours string) ([]span, error) {
+	var spans []span
+	for _, part := range strings.Split(hours, ",") {
+		from, to, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, fmt.Errorf("%w: time range %q", ErrUnsupported, part)
+		}
+
+		start, err := parseClock(from)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(to)
+		if err != nil {
+			return nil, err
+		}
+
+		if end <= start {
+			end += minutesPerDay
+		}
+		spans = append(spans, span{start, end})
+	}
+	return spans, nil
+}
+
+// parseClock converts "HH:MM" to minutes; 24:00 is allowed as end of day.
+func parseClock(clock string) (int, error) {
+	h, m, ok := strings.Cut(clock, ":")
+	if !ok {
+		return 0, fmt.Errorf("%w: time %q", ErrUnsupported, clock)
+	}
+
+	hour, err := strconv.Atoi(h)
+	if err != nil {
+		return 0, fmt.Errorf("%w: time %q", ErrUnsupported, clock)
+	}
+	minute, err := strconv.Atoi(m)
+	if err != nil || len(m) != 2 {
+		return 0, fmt.Errorf("%w: time %q", ErrUnsupported, clock)
+	}
+
+	total := hour*60 + minute
+	if hour < 0 || minute < 0 || minute > 59 || total > minutesPerDay {
+		return 0, fmt.Errorf("%w: time %q", ErrUnsupported, clock)
+	}
+	return total, nil
+}
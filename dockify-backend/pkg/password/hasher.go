@@ -0,0 +1,212 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/askaroe/dockify-backend/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+
+	defaultArgon2MemoryKiB   = 64 * 1024
+	defaultArgon2Iterations  = 3
+	defaultArgon2Parallelism = 2
+	defaultBcryptCost        = 12
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var (
+	// ErrMismatch means the password does not match the hash.
+	ErrMismatch = errors.New("password does not match")
+	// ErrUnknownHash means the hash was not made by a supported algorithm.
+	ErrUnknownHash = errors.New("unknown password hash format")
+)
+
+// scheme is one hashing algorithm. Hashes are self-describing: they name the
+// algorithm and carry its parameters, so a hash can be verified after the
+// configuration changed.
+type scheme interface {
+	hash(password string) (string, error)
+	// owns reports whether encoded was made by this algorithm.
+	owns(encoded string) bool
+	verify(encoded, password string) error
+	// current reports whether encoded was made with this scheme's parameters.
+	current(encoded string) bool
+}
+
+// Hasher hashes new passwords with the configured algorithm and verifies
+// hashes of every supported algorithm.
+type Hasher struct {
+	active  scheme
+	schemes []scheme
+}
+
+func NewHasher(cfg config.PasswordHashConfig) (*Hasher, error) {
+	if cfg.Argon2MemoryKiB < 0 || cfg.Argon2Iterations < 0 || cfg.Argon2Parallelism < 0 || cfg.Argon2Parallelism > 255 {
+		return nil, errors.New("argon2 memory and iterations must be positive and parallelism between 1 and 255")
+	}
+	argon := argon2Scheme{
+		memory:      uint32(orDefault(cfg.Argon2MemoryKiB, defaultArgon2MemoryKiB)),
+		iterations:  uint32(orDefault(cfg.Argon2Iterations, defaultArgon2Iterations)),
+		parallelism: uint8(orDefault(cfg.Argon2Parallelism, defaultArgon2Parallelism)),
+	}
+	if argon.memory < 8*uint32(argon.parallelism) {
+		return nil, errors.New("argon2 memory must be at least 8 KiB per thread")
+	}
+
+	bc := bcryptScheme{cost: orDefault(cfg.BcryptCost, defaultBcryptCost)}
+	if bc.cost < bcrypt.MinCost || bc.cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	h := &Hasher{schemes: []scheme{argon, bc}}
+	switch cfg.PasswordHashAlgorithm {
+	case "", AlgorithmArgon2id:
+		h.active = argon
+	case AlgorithmBcrypt:
+		h.active = bc
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", cfg.PasswordHashAlgorithm)
+	}
+	return h, nil
+}
+
+// Hash hashes password with the configured algorithm.
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.active.hash(password)
+}
+
+// Verify returns nil when password matches encoded, ErrMismatch when it does
+// not.
+func (h *Hasher) Verify(encoded, password string) error {
+	for _, s := range h.schemes {
+		if s.owns(encoded) {
+			return s.verify(encoded, password)
+		}
+	}
+	return ErrUnknownHash
+}
+
+// NeedsRehash reports whether encoded was made with another algorithm or
+// other parameters than the configured ones. Rehash it after a successful
+// Verify.
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	return !h.active.owns(encoded) || !h.active.current(encoded)
+}
+
+// argon2Scheme encodes hashes in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+type argon2Scheme struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+const argon2Prefix = "$argon2id$"
+
+func (a argon2Scheme) hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, a.iterations, a.memory, a.parallelism, argon2KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version, a.memory, a.iterations, a.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a argon2Scheme) owns(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2Prefix)
+}
+
+func (a argon2Scheme) verify(encoded, password string) error {
+	params, salt, key, err := decodeArgon2(encoded)
+	if err != nil {
+		return err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+func (a argon2Scheme) current(encoded string) bool {
+	params, _, key, err := decodeArgon2(encoded)
+	return err == nil && params == a && len(key) == argon2KeyLength
+}
+
+func decodeArgon2(encoded string) (argon2Scheme, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return argon2Scheme{}, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Scheme{}, nil, nil, ErrUnknownHash
+	}
+
+	var params argon2Scheme
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil ||
+		params.iterations == 0 || params.parallelism == 0 {
+		return argon2Scheme{}, nil, nil, ErrUnknownHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Scheme{}, nil, nil, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return argon2Scheme{}, nil, nil, ErrUnknownHash
+	}
+	return params, salt, key, nil
+}
+
+type bcryptScheme struct {
+	cost int
+}
+
+func (b bcryptScheme) hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", fmt.Errorf("hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+func (b bcryptScheme) owns(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b bcryptScheme) verify(encoded, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	return err
+}
+
+func (b bcryptScheme) current(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err == nil && cost == b.cost
+}
+
+func orDefault(value, def int) int {
+	if value == 0 {
+		return def
+	}
+	return value
+}
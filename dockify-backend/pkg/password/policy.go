@@ -0,0 +1,72 @@
+package password
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MinLength = 8
+	// MaxBytes is where bcrypt stops reading a password.
+	MaxBytes = 72
+)
+
+// Policy decides whether a password is acceptable: long enough, not too long
+// to hash, not derived from the account's own identifiers and not on the list
+// of breached passwords.
+type Policy struct {
+	breached map[string]struct{}
+}
+
+// NewPolicy loads the breached password list from path, one password per
+// line; lines starting with # are comments. An empty path disables the list.
+func NewPolicy(path string) (*Policy, error) {
+	p := &Policy{breached: make(map[string]struct{})}
+	if path == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read breached password list: %w", err)
+	}
+	return p, nil
+}
+
+// Check returns why password is not acceptable, or nil. identifiers are
+// values of the account, e.g. its username and email, the password must not
+// equal.
+func (p *Policy) Check(password string, identifiers ...string) error {
+	if utf8.RuneCountInString(password) < MinLength {
+		return fmt.Errorf("must be at least %d characters", MinLength)
+	}
+	if len(password) > MaxBytes {
+		return fmt.Errorf("must not exceed %d bytes", MaxBytes)
+	}
+	for _, id := range identifiers {
+		if id != "" && strings.EqualFold(password, id) {
+			return errors.New("must not be the same as your username or email")
+		}
+	}
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return errors.New("appears in a list of breached passwords, choose another one")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows at most limit events per key in each fixed window. Counters
+// live in process memory, so every instance of the server limits on its own.
+type Limiter struct {
+	limit  int
+	window time.Duration
+
+	mu        sync.Mutex
+	counters  map[string]counter
+	lastSweep time.Time
+}
+
+type counter struct {
+	count   int
+	resetAt time.Time
+}
+
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:    limit,
+		window:   window,
+		counters: make(map[string]counter),
+	}
+}
+
+// Allow records an event for key and reports whether it is within the limit.
+// When it is not, retryAfter is the time until the window resets.
+func (l *Limiter) Allow(key string) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	c := l.counters[key]
+	if !now.Before(c.resetAt) {
+		c = counter{resetAt: now.Add(l.window)}
+	}
+	if c.count >= l.limit {
+		return false, c.resetAt.Sub(now)
+	}
+	c.count++
+	l.counters[key] = c
+	return true, 0
+}
+
+// sweep drops expired counters at most once per window so the map does not
+// grow with every key ever seen.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+	for key, c := range l.counters {
+		if !now.Before(c.resetAt) {
+			delete(l.counters, key)
+		}
+	}
+}
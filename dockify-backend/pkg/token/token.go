@@ -0,0 +1,182 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/askaroe/dockify-backend/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	TypeAccess            = "access"
+	TypeRefresh           = "refresh"
+	TypeEmailVerification = "email_verification"
+	TypeMFAChallenge      = "mfa_challenge"
+
+	emailVerificationTTL = 24 * time.Hour
+	MFAChallengeTTL      = 5 * time.Minute
+
+	headerKeyID = "kid"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type Claims struct {
+	UserID    int    `json:"uid"`
+	SessionID int    `json:"sid"`
+	Type      string `json:"typ"`
+	// Email is the address an email verification token was sent to.
+	Email string `json:"email,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type Manager struct {
+	issuer      string
+	activeKeyID string
+	keys        map[string][]byte
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+}
+
+func NewManager(cfg config.JWTConfig) (*Manager, error) {
+	if cfg.JWTActiveKeyID == "" {
+		return nil, errors.New("jwt active key id is not configured")
+	}
+
+	keys := make(map[string][]byte, len(cfg.JWTSigningKeys))
+	for kid, secret := range cfg.JWTSigningKeys {
+		if secret == "" {
+			return nil, fmt.Errorf("jwt signing key %q is empty", kid)
+		}
+		keys[kid] = []byte(secret)
+	}
+
+	if _, ok := keys[cfg.JWTActiveKeyID]; !ok {
+		return nil, fmt.Errorf("jwt active key %q not found in signing keys", cfg.JWTActiveKeyID)
+	}
+
+	if cfg.AccessTokenTTLMinutes <= 0 || cfg.RefreshTokenTTLHours <= 0 {
+		return nil, errors.New("jwt token ttl must be positive")
+	}
+
+	return &Manager{
+		issuer:      cfg.JWTIssuer,
+		activeKeyID: cfg.JWTActiveKeyID,
+		keys:        keys,
+		accessTTL:   time.Duration(cfg.AccessTokenTTLMinutes) * time.Minute,
+		refreshTTL:  time.Duration(cfg.RefreshTokenTTLHours) * time.Hour,
+	}, nil
+}
+
+func (m *Manager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+// Issue signs a token of the given type for the user's session with the active key.
+func (m *Manager) Issue(userID, sessionID int, tokenType string) (string, error) {
+	ttl, err := m.ttl(tokenType)
+	if err != nil {
+		return "", err
+	}
+
+	return m.sign(Claims{UserID: userID, SessionID: sessionID, Type: tokenType}, ttl)
+}
+
+// IssueEmailVerification signs a token proving that whoever presents it
+// received mail at email. It is not bound to a session.
+func (m *Manager) IssueEmailVerification(userID int, email string) (string, error) {
+	return m.sign(Claims{UserID: userID, Type: TypeEmailVerification, Email: email}, emailVerificationTTL)
+}
+
+// IssueMFAChallenge signs a token proving that whoever presents it knew the
+// user's password a moment ago. It is exchanged for a session together with a
+// second factor code.
+func (m *Manager) IssueMFAChallenge(userID int) (string, error) {
+	return m.sign(Claims{UserID: userID, Type: TypeMFAChallenge}, MFAChallengeTTL)
+}
+
+func (m *Manager) sign(claims Claims, ttl time.Duration) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        jti,
+		Issuer:    m.issuer,
+		Subject:   strconv.Itoa(claims.UserID),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	t.Header[headerKeyID] = m.activeKeyID
+
+	signed, err := t.SignedString(m.keys[m.activeKeyID])
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+
+	return signed, nil
+}
+
+// Parse verifies the signature using the key referenced by the kid header and
+// checks that the token is of the expected type.
+func (m *Manager) Parse(tokenString, tokenType string) (*Claims, error) {
+	var claims Claims
+
+	_, err := jwt.ParseWithClaims(tokenString, &claims, m.keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.Type != tokenType {
+		return nil, fmt.Errorf("%w: unexpected token type %q", ErrInvalidToken, claims.Type)
+	}
+
+	return &claims, nil
+}
+
+func (m *Manager) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, ok := t.Header[headerKeyID].(string)
+	if !ok {
+		return nil, errors.New("missing kid header")
+	}
+
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	return key, nil
+}
+
+func (m *Manager) ttl(tokenType string) (time.Duration, error) {
+	switch tokenType {
+	case TypeAccess:
+		return m.accessTTL, nil
+	case TypeRefresh:
+		return m.refreshTTL, nil
+	default:
+		return 0, fmt.Errorf("unknown token type %q", tokenType)
+	}
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}